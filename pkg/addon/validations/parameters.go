@@ -0,0 +1,263 @@
+package validations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	addonsv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+const (
+	editableDirectionUp   = "up"
+	editableDirectionDown = "down"
+)
+
+// ParameterError describes a problem found with a single add-on parameter
+type ParameterError struct {
+	ParameterID string
+	Message     string
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("parameter '%s' %s", e.ParameterID, e.Message)
+}
+
+func newParameterError(parameterID string, format string, args ...interface{}) error {
+	return &ParameterError{
+		ParameterID: parameterID,
+		Message:     fmt.Sprintf(format, args...),
+	}
+}
+
+// FetchAddonParameters returns the parameter definitions of the add-on
+func FetchAddonParameters(ctx context.Context, addonsClient *addonsv1.AddonsClient,
+	addonID string) ([]*addonsv1.AddonParameter, error) {
+	resp, err := addonsClient.Addon(addonID).Get().SendContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get add-on '%s': %v", addonID, err)
+	}
+	return resp.Body().Parameters().Items(), nil
+}
+
+// ValidateAddonInstallation fetches the parameter definitions of the add-on and validates the proposed
+// parameters for an installation on the cluster. See ValidateAddonParameters.
+func ValidateAddonInstallation(ctx context.Context, addonsClient *addonsv1.AddonsClient, addonID string,
+	cluster *cmv1.Cluster, proposed map[string]string) error {
+	parameters, err := FetchAddonParameters(ctx, addonsClient, addonID)
+	if err != nil {
+		return err
+	}
+	return ValidateAddonParameters(parameters, cluster, proposed)
+}
+
+// ValidateAddonParameters validates the proposed parameters against the add-on parameter definitions:
+//
+// * Every proposed parameter must be defined by the add-on, enabled and applicable to the cluster.
+// * Required parameters without a default value must be provided.
+// * Values must match the parameter type, its validation regular expression and its options.
+//
+// Parameters whose conditions are not met by the cluster are ignored. When cluster is nil the conditions
+// are not evaluated. All the issues are returned at once, joined into a single error of ParameterError.
+func ValidateAddonParameters(parameters []*addonsv1.AddonParameter, cluster *cmv1.Cluster,
+	proposed map[string]string) error {
+	var errs []error
+	clusterData, err := clusterToMap(cluster)
+	if err != nil {
+		return err
+	}
+
+	definitions := map[string]*addonsv1.AddonParameter{}
+	for _, parameter := range parameters {
+		definitions[parameter.ID()] = parameter
+	}
+	for id := range proposed {
+		if _, ok := definitions[id]; !ok {
+			errs = append(errs, newParameterError(id, "is not defined by the add-on"))
+		}
+	}
+
+	for _, parameter := range parameters {
+		id := parameter.ID()
+		value, provided := proposed[id]
+		applicable := parameter.Enabled() && conditionsMet(parameter.Conditions(), clusterData)
+		if !applicable {
+			if provided {
+				errs = append(errs, newParameterError(id, "is not applicable to the cluster"))
+			}
+			continue
+		}
+		if !provided {
+			if parameter.Required() && parameter.DefaultValue() == "" {
+				errs = append(errs, newParameterError(id, "is required"))
+			}
+			continue
+		}
+		errs = append(errs, validateParameterValue(parameter, value)...)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAddonParametersUpdate checks that the changes between the current and the proposed parameters of an
+// installation are allowed: non editable parameters can't change and parameters with an editable direction
+// can only increase ("up") or decrease ("down").
+func ValidateAddonParametersUpdate(parameters []*addonsv1.AddonParameter, current map[string]string,
+	proposed map[string]string) error {
+	var errs []error
+	for _, parameter := range parameters {
+		id := parameter.ID()
+		newValue, provided := proposed[id]
+		oldValue, existing := current[id]
+		if !provided || !existing || newValue == oldValue {
+			continue
+		}
+		if !parameter.Editable() {
+			errs = append(errs, newParameterError(id, "is not editable"))
+			continue
+		}
+		direction := parameter.EditableDirection()
+		if direction == "" || parameter.ValueType() != addonsv1.AddonParameterValueTypeNumber {
+			continue
+		}
+		oldNumber, oldErr := strconv.ParseFloat(oldValue, 64)
+		newNumber, newErr := strconv.ParseFloat(newValue, 64)
+		if oldErr != nil || newErr != nil {
+			continue
+		}
+		if direction == editableDirectionUp && newNumber < oldNumber {
+			errs = append(errs, newParameterError(id, "can only be increased, current value is %s", oldValue))
+		}
+		if direction == editableDirectionDown && newNumber > oldNumber {
+			errs = append(errs, newParameterError(id, "can only be decreased, current value is %s", oldValue))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateParameterValue(parameter *addonsv1.AddonParameter, value string) []error {
+	var errs []error
+	id := parameter.ID()
+	if parameter.Required() && strings.TrimSpace(value) == "" {
+		errs = append(errs, newParameterError(id, "is required and can't be empty"))
+		return errs
+	}
+
+	switch parameter.ValueType() {
+	case addonsv1.AddonParameterValueTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			errs = append(errs, newParameterError(id, "must be a boolean, got '%s'", value))
+		}
+	case addonsv1.AddonParameterValueTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			errs = append(errs, newParameterError(id, "must be a number, got '%s'", value))
+		}
+	case addonsv1.AddonParameterValueTypeCIDR:
+		if value != "" {
+			if _, _, err := net.ParseCIDR(value); err != nil {
+				errs = append(errs, newParameterError(id, "must be a valid CIDR, got '%s'", value))
+			}
+		}
+	}
+
+	if validation := parameter.Validation(); validation != "" && value != "" {
+		re, err := regexp.Compile(validation)
+		if err != nil {
+			errs = append(errs, newParameterError(id, "has an invalid validation expression '%s': %v", validation, err))
+		} else if !re.MatchString(value) {
+			message := parameter.ValidationErrMsg()
+			if message == "" {
+				message = fmt.Sprintf("does not match the expression '%s'", validation)
+			}
+			errs = append(errs, newParameterError(id, "value '%s' is invalid: %s", value, message))
+		}
+	}
+
+	if options := parameter.Options(); len(options) > 0 {
+		values := []string{}
+		found := false
+		for _, option := range options {
+			values = append(values, option.Value())
+			if option.Value() == value {
+				found = true
+			}
+		}
+		if !found {
+			errs = append(errs, newParameterError(id, "value '%s' is not one of the options [%s]",
+				value, strings.Join(values, ", ")))
+		}
+	}
+	return errs
+}
+
+// conditionsMet evaluates the cluster conditions of a parameter. Each condition data maps a dotted path of the
+// cluster to the expected value, or to a list of accepted values.
+func conditionsMet(conditions []*addonsv1.AddonRequirement, clusterData map[string]interface{}) bool {
+	if clusterData == nil {
+		return true
+	}
+	for _, condition := range conditions {
+		if !condition.Enabled() || condition.Resource() != addonsv1.AddonRequirementResourceCluster {
+			continue
+		}
+		for path, expected := range condition.Data() {
+			actual, ok := lookupPath(clusterData, path)
+			if !ok || !matchesExpected(actual, expected) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchesExpected(actual interface{}, expected interface{}) bool {
+	if accepted, ok := expected.([]interface{}); ok {
+		for _, value := range accepted {
+			if matchesExpected(actual, value) {
+				return true
+			}
+		}
+		return false
+	}
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	return fmt.Sprint(actual) == fmt.Sprint(expected)
+}
+
+func lookupPath(data map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = data
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func clusterToMap(cluster *cmv1.Cluster) (map[string]interface{}, error) {
+	if cluster == nil {
+		return nil, nil
+	}
+	buffer := &bytes.Buffer{}
+	if err := cmv1.MarshalCluster(cluster, buffer); err != nil {
+		return nil, fmt.Errorf("failed to marshal cluster: %v", err)
+	}
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cluster: %v", err)
+	}
+	return data, nil
+}
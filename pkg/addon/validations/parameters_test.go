@@ -0,0 +1,113 @@
+package validations
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	addonsv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func buildParameter(builder *addonsv1.AddonParameterBuilder) *addonsv1.AddonParameter {
+	parameter, err := builder.Enabled(true).Build()
+	Expect(err).ToNot(HaveOccurred())
+	return parameter
+}
+
+var _ = Describe("Add-on parameters validation", func() {
+	var parameters []*addonsv1.AddonParameter
+
+	BeforeEach(func() {
+		parameters = []*addonsv1.AddonParameter{
+			buildParameter(addonsv1.NewAddonParameter().ID("notification-email").
+				ValueType(addonsv1.AddonParameterValueTypeString).
+				Required(true).
+				Validation(`^[^@\s]+@[^@\s]+$`).
+				ValidationErrMsg("must be an email address")),
+			buildParameter(addonsv1.NewAddonParameter().ID("size").
+				ValueType(addonsv1.AddonParameterValueTypeNumber).
+				DefaultValue("1").
+				Options(addonsv1.NewAddonParameterOption().Value("1"), addonsv1.NewAddonParameterOption().Value("4"))),
+			buildParameter(addonsv1.NewAddonParameter().ID("pod-cidr").
+				ValueType(addonsv1.AddonParameterValueTypeCIDR)),
+			buildParameter(addonsv1.NewAddonParameter().ID("use-sts").
+				ValueType(addonsv1.AddonParameterValueTypeBoolean).
+				Required(true).
+				Conditions(addonsv1.NewAddonRequirement().
+					Enabled(true).
+					Resource(addonsv1.AddonRequirementResourceCluster).
+					Data(map[string]interface{}{"cloud_provider.id": []interface{}{"aws"}}))),
+		}
+	})
+
+	It("accepts valid parameters", func() {
+		err := ValidateAddonParameters(parameters, nil, map[string]string{
+			"notification-email": "someone@example.com",
+			"size":               "4",
+			"pod-cidr":           "10.128.0.0/14",
+			"use-sts":            "true",
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns all the issues at once", func() {
+		err := ValidateAddonParameters(parameters, nil, map[string]string{
+			"notification-email": "someone",
+			"size":               "2",
+			"pod-cidr":           "10.128.0.0",
+			"unknown":            "value",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("parameter 'unknown' is not defined by the add-on"))
+		Expect(err.Error()).To(ContainSubstring(
+			"parameter 'notification-email' value 'someone' is invalid: must be an email address"))
+		Expect(err.Error()).To(ContainSubstring("parameter 'size' value '2' is not one of the options [1, 4]"))
+		Expect(err.Error()).To(ContainSubstring("parameter 'pod-cidr' must be a valid CIDR, got '10.128.0.0'"))
+		Expect(err.Error()).To(ContainSubstring("parameter 'use-sts' is required"))
+	})
+
+	It("skips parameters whose conditions are not met by the cluster", func() {
+		cluster, err := cmv1.NewCluster().CloudProvider(cmv1.NewCloudProvider().ID("gcp")).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ValidateAddonParameters(parameters, cluster, map[string]string{
+			"notification-email": "someone@example.com",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ValidateAddonParameters(parameters, cluster, map[string]string{
+			"notification-email": "someone@example.com",
+			"use-sts":            "true",
+		})
+		Expect(err).To(MatchError("parameter 'use-sts' is not applicable to the cluster"))
+	})
+
+	It("evaluates the conditions against the cluster", func() {
+		cluster, err := cmv1.NewCluster().CloudProvider(cmv1.NewCloudProvider().ID("aws")).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ValidateAddonParameters(parameters, cluster, map[string]string{
+			"notification-email": "someone@example.com",
+			"use-sts":            "yes",
+		})
+		Expect(err).To(MatchError("parameter 'use-sts' must be a boolean, got 'yes'"))
+	})
+
+	It("validates the updates of the parameters", func() {
+		updatable := []*addonsv1.AddonParameter{
+			buildParameter(addonsv1.NewAddonParameter().ID("size").
+				ValueType(addonsv1.AddonParameterValueTypeNumber).
+				Editable(true).
+				EditableDirection("up")),
+			buildParameter(addonsv1.NewAddonParameter().ID("name").
+				ValueType(addonsv1.AddonParameterValueTypeString)),
+		}
+		current := map[string]string{"size": "4", "name": "first"}
+
+		Expect(ValidateAddonParametersUpdate(updatable, current, map[string]string{"size": "8", "name": "first"})).
+			To(Succeed())
+		err := ValidateAddonParametersUpdate(updatable, current, map[string]string{"size": "2", "name": "second"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("parameter 'size' can only be increased, current value is 4"))
+		Expect(err.Error()).To(ContainSubstring("parameter 'name' is not editable"))
+	})
+})
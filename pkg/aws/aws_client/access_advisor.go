@@ -0,0 +1,63 @@
+package aws_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const (
+	accessAdvisorPollInterval = 2 * time.Second
+	accessAdvisorTimeout      = 2 * time.Minute
+)
+
+// GetLastAccessedTime returns the last time the IAM role, policy, user or group was used to access an AWS service,
+// as reported by the IAM access advisor. It is nil when the entity was never used in the tracking period.
+func (client *AWSClient) GetLastAccessedTime(arn string) (*time.Time, error) {
+	job, err := client.IamClient.GenerateServiceLastAccessedDetails(context.TODO(),
+		&iam.GenerateServiceLastAccessedDetailsInput{Arn: aws.String(arn)})
+	if err != nil {
+		log.LogError("Generate last accessed details of %s failed: %s", arn, err)
+		return nil, err
+	}
+
+	deadline := time.Now().Add(accessAdvisorTimeout)
+	var lastAccessed *time.Time
+	input := &iam.GetServiceLastAccessedDetailsInput{JobId: job.JobId}
+	for {
+		out, err := client.IamClient.GetServiceLastAccessedDetails(context.TODO(), input)
+		if err != nil {
+			return nil, err
+		}
+		switch out.JobStatus {
+		case iamtypes.JobStatusTypeFailed:
+			reason := ""
+			if out.Error != nil {
+				reason = aws.ToString(out.Error.Message)
+			}
+			return nil, fmt.Errorf("last accessed details job of %s failed: %s", arn, reason)
+		case iamtypes.JobStatusTypeInProgress:
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("timed out waiting for the last accessed details of %s", arn)
+			}
+			time.Sleep(accessAdvisorPollInterval)
+			continue
+		}
+		for _, service := range out.ServicesLastAccessed {
+			if service.LastAuthenticated != nil &&
+				(lastAccessed == nil || service.LastAuthenticated.After(*lastAccessed)) {
+				lastAccessed = service.LastAuthenticated
+			}
+		}
+		if !out.IsTruncated {
+			return lastAccessed, nil
+		}
+		input.Marker = out.Marker
+	}
+}
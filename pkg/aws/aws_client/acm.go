@@ -0,0 +1,289 @@
+package aws_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmtypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const acmValidationRecordTTL = 300
+
+// RequestCertificate requests a public certificate for the domain validated by DNS and returns its ARN.
+// When wildcard is true the certificate also covers '*.<domain>'.
+func (client *AWSClient) RequestCertificate(domain string, wildcard bool, tags map[string]string) (string, error) {
+	input := &acm.RequestCertificateInput{
+		DomainName:       aws.String(domain),
+		ValidationMethod: acmtypes.ValidationMethodDns,
+	}
+	if wildcard {
+		input.SubjectAlternativeNames = []string{"*." + domain}
+	}
+	for key, value := range tags {
+		input.Tags = append(input.Tags, acmtypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	output, err := client.AcmClient.RequestCertificate(context.TODO(), input)
+	if err != nil {
+		log.LogError("Request certificate for domain %s failed: %s", domain, err)
+		return "", err
+	}
+	log.LogInfo("Requested certificate %s for domain %s", *output.CertificateArn, domain)
+	return *output.CertificateArn, nil
+}
+
+func (client *AWSClient) DescribeCertificate(certificateArn string) (*acmtypes.CertificateDetail, error) {
+	output, err := client.AcmClient.DescribeCertificate(context.TODO(), &acm.DescribeCertificateInput{
+		CertificateArn: aws.String(certificateArn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Certificate, nil
+}
+
+// GetCertificateValidationRecords waits until ACM published the DNS validation records of the certificate and
+// returns them. The records of a domain and of its wildcard are the same, so they are returned only once.
+// Imported and private certificates, and the domains validated by email, have no validation record.
+func (client *AWSClient) GetCertificateValidationRecords(certificateArn string,
+	timeout time.Duration) ([]acmtypes.ResourceRecord, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		certificate, err := client.DescribeCertificate(certificateArn)
+		if err != nil {
+			return nil, err
+		}
+		if certificate.Type != acmtypes.CertificateTypeAmazonIssued {
+			return []acmtypes.ResourceRecord{}, nil
+		}
+		records, complete := certificateValidationRecords(certificate)
+		if complete {
+			return records, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for the validation records of certificate %s", certificateArn)
+		}
+		log.LogDebug("Validation records of certificate %s are not available yet", certificateArn)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// certificateValidationRecords returns the DNS validation records of the certificate, and whether ACM published
+// all of them
+func certificateValidationRecords(certificate *acmtypes.CertificateDetail) ([]acmtypes.ResourceRecord, bool) {
+	records := []acmtypes.ResourceRecord{}
+	seen := map[string]bool{}
+	// The options are filled shortly after the certificate is requested
+	complete := len(certificate.DomainValidationOptions) > 0
+	for _, option := range certificate.DomainValidationOptions {
+		if option.ValidationMethod == acmtypes.ValidationMethodEmail {
+			continue
+		}
+		if option.ResourceRecord == nil {
+			complete = false
+			continue
+		}
+		if !seen[aws.ToString(option.ResourceRecord.Name)] {
+			seen[aws.ToString(option.ResourceRecord.Name)] = true
+			records = append(records, *option.ResourceRecord)
+		}
+	}
+	return records, complete
+}
+
+// validationRecordsInUse returns the names of the DNS validation records of the certificates of the account other
+// than the excluded one. ACM gives the same record to all the certificates of a domain.
+func (client *AWSClient) validationRecordsInUse(excludedArn string) (map[string]bool, error) {
+	inUse := map[string]bool{}
+	paginator := acm.NewListCertificatesPaginator(client.AcmClient, &acm.ListCertificatesInput{
+		Includes: &acmtypes.Filters{KeyTypes: acmtypes.KeyAlgorithm("").Values()},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range page.CertificateSummaryList {
+			if aws.ToString(summary.CertificateArn) == excludedArn ||
+				summary.Type != acmtypes.CertificateTypeAmazonIssued {
+				continue
+			}
+			certificate, err := client.DescribeCertificate(aws.ToString(summary.CertificateArn))
+			if err != nil {
+				return nil, err
+			}
+			records, _ := certificateValidationRecords(certificate)
+			for _, record := range records {
+				inUse[aws.ToString(record.Name)] = true
+			}
+		}
+	}
+	return inUse, nil
+}
+
+// CreateCertificateValidationRecords creates the DNS validation records of the certificate in the hosted zone
+// and waits until they are in sync
+func (client *AWSClient) CreateCertificateValidationRecords(certificateArn string, hostedZoneID string,
+	timeout time.Duration) error {
+	return client.changeCertificateValidationRecords(certificateArn, hostedZoneID, route53types.ChangeActionUpsert,
+		timeout)
+}
+
+// DeleteCertificateValidationRecords deletes the DNS validation records of the certificate from the hosted zone.
+// Records that don't exist anymore, or that are still used by another certificate of the account, are kept.
+func (client *AWSClient) DeleteCertificateValidationRecords(certificateArn string, hostedZoneID string,
+	timeout time.Duration) error {
+	return client.changeCertificateValidationRecords(certificateArn, hostedZoneID, route53types.ChangeActionDelete,
+		timeout)
+}
+
+func (client *AWSClient) changeCertificateValidationRecords(certificateArn string, hostedZoneID string,
+	action route53types.ChangeAction, timeout time.Duration) error {
+	records, err := client.GetCertificateValidationRecords(certificateArn, timeout)
+	if err != nil {
+		return err
+	}
+	inUse := map[string]bool{}
+	if action == route53types.ChangeActionDelete && len(records) > 0 {
+		inUse, err = client.validationRecordsInUse(certificateArn)
+		if err != nil {
+			return err
+		}
+	}
+	for _, record := range records {
+		if inUse[aws.ToString(record.Name)] {
+			log.LogInfo("Keep validation record %s used by another certificate", aws.ToString(record.Name))
+			continue
+		}
+		recordSet := &route53types.ResourceRecordSet{
+			Name: record.Name,
+			Type: route53types.RRType(record.Type),
+			TTL:  aws.Int64(acmValidationRecordTTL),
+			ResourceRecords: []route53types.ResourceRecord{
+				{Value: record.Value},
+			},
+		}
+		if action == route53types.ChangeActionDelete {
+			existing, err := client.GetResourceRecordSet(hostedZoneID, *record.Name, recordSet.Type)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				continue
+			}
+			recordSet = existing
+		}
+		output, err := client.ChangeResourceRecordSet(hostedZoneID, action, recordSet)
+		if err != nil {
+			return err
+		}
+		err = client.WaitForRecordChangeInSync(*output.ChangeInfo.Id, timeout)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForCertificateIssued waits until the certificate is issued, failing early if its validation failed
+func (client *AWSClient) WaitForCertificateIssued(certificateArn string, timeout time.Duration) error {
+	waiter := acm.NewCertificateValidatedWaiter(client.AcmClient)
+	err := waiter.Wait(context.TODO(), &acm.DescribeCertificateInput{
+		CertificateArn: aws.String(certificateArn),
+	}, timeout)
+	if err != nil {
+		log.LogError("Waiting for certificate %s to be issued failed: %s", certificateArn, err)
+		return err
+	}
+	log.LogInfo("Certificate %s is issued", certificateArn)
+	return nil
+}
+
+// ProvisionCertificate requests a certificate for the domain and its wildcard, validates it with DNS records in
+// the hosted zone and waits for it to be issued. It returns the certificate ARN.
+func (client *AWSClient) ProvisionCertificate(domain string, hostedZoneID string, tags map[string]string,
+	timeout time.Duration) (string, error) {
+	certificateArn, err := client.RequestCertificate(domain, true, tags)
+	if err != nil {
+		return "", err
+	}
+	err = client.CreateCertificateValidationRecords(certificateArn, hostedZoneID, timeout)
+	if err != nil {
+		return certificateArn, err
+	}
+	return certificateArn, client.WaitForCertificateIssued(certificateArn, timeout)
+}
+
+// ImportCertificate imports a PEM encoded certificate, its private key and optional chain. When certificateArn is
+// not empty the certificate with that ARN is re-imported. It returns the certificate ARN.
+func (client *AWSClient) ImportCertificate(certificate []byte, privateKey []byte, chain []byte,
+	certificateArn string) (string, error) {
+	input := &acm.ImportCertificateInput{
+		Certificate: certificate,
+		PrivateKey:  privateKey,
+	}
+	if len(chain) > 0 {
+		input.CertificateChain = chain
+	}
+	if certificateArn != "" {
+		input.CertificateArn = aws.String(certificateArn)
+	}
+	output, err := client.AcmClient.ImportCertificate(context.TODO(), input)
+	if err != nil {
+		log.LogError("Import certificate failed: %s", err)
+		return "", err
+	}
+	log.LogInfo("Imported certificate %s", *output.CertificateArn)
+	return *output.CertificateArn, nil
+}
+
+// GetCertificatePEM returns the PEM encoded certificate and chain of an issued or imported certificate
+func (client *AWSClient) GetCertificatePEM(certificateArn string) (certificate string, chain string, err error) {
+	output, err := client.AcmClient.GetCertificate(context.TODO(), &acm.GetCertificateInput{
+		CertificateArn: aws.String(certificateArn),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return aws.ToString(output.Certificate), aws.ToString(output.CertificateChain), nil
+}
+
+// ExportCertificate exports an exportable certificate with its private key encrypted with the passphrase
+func (client *AWSClient) ExportCertificate(certificateArn string, passphrase []byte) (certificate string,
+	chain string, privateKey string, err error) {
+	output, err := client.AcmClient.ExportCertificate(context.TODO(), &acm.ExportCertificateInput{
+		CertificateArn: aws.String(certificateArn),
+		Passphrase:     passphrase,
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+	return aws.ToString(output.Certificate), aws.ToString(output.CertificateChain),
+		aws.ToString(output.PrivateKey), nil
+}
+
+// DeleteCertificate deletes the certificate. When hostedZoneID is not empty its DNS validation records are
+// removed from the hosted zone first.
+func (client *AWSClient) DeleteCertificate(certificateArn string, hostedZoneID string, timeout time.Duration) error {
+	if hostedZoneID != "" {
+		err := client.DeleteCertificateValidationRecords(certificateArn, hostedZoneID, timeout)
+		if err != nil {
+			log.LogError("Delete validation records of certificate %s failed: %s", certificateArn, err)
+			return err
+		}
+	}
+	_, err := client.AcmClient.DeleteCertificate(context.TODO(), &acm.DeleteCertificateInput{
+		CertificateArn: aws.String(certificateArn),
+	})
+	if err != nil {
+		log.LogError("Delete certificate %s failed: %s", certificateArn, err)
+		return err
+	}
+	log.LogInfo("Deleted certificate %s", certificateArn)
+	return nil
+}
@@ -0,0 +1,68 @@
+package aws_client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	classicelbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing/types"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// ListClassicLoadBalancersByTag returns the classic load balancers having the tag key. When tagValue is empty
+// only the key is checked
+func (client *AWSClient) ListClassicLoadBalancersByTag(tagKey string,
+	tagValue string) ([]classicelbtypes.LoadBalancerDescription, error) {
+	loadBalancers := map[string]classicelbtypes.LoadBalancerDescription{}
+	names := []string{}
+	paginator := elasticloadbalancing.NewDescribeLoadBalancersPaginator(client.ClassicElbClient,
+		&elasticloadbalancing.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List classic load balancers failed: %s", err)
+			return nil, err
+		}
+		for _, lb := range page.LoadBalancerDescriptions {
+			loadBalancers[*lb.LoadBalancerName] = lb
+			names = append(names, *lb.LoadBalancerName)
+		}
+	}
+
+	tagged := []classicelbtypes.LoadBalancerDescription{}
+	// DescribeTags accepts at most 20 load balancers per call
+	for start := 0; start < len(names); start += 20 {
+		end := start + 20
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := client.ClassicElbClient.DescribeTags(context.TODO(), &elasticloadbalancing.DescribeTagsInput{
+			LoadBalancerNames: names[start:end],
+		})
+		if err != nil {
+			log.LogError("Describe classic load balancer tags failed: %s", err)
+			return nil, err
+		}
+		for _, description := range output.TagDescriptions {
+			for _, tag := range description.Tags {
+				if aws.ToString(tag.Key) == tagKey && (tagValue == "" || aws.ToString(tag.Value) == tagValue) {
+					tagged = append(tagged, loadBalancers[aws.ToString(description.LoadBalancerName)])
+					break
+				}
+			}
+		}
+	}
+	return tagged, nil
+}
+
+// DeleteClassicLoadBalancer deletes the classic load balancer with the name
+func (client *AWSClient) DeleteClassicLoadBalancer(name string) error {
+	log.LogInfo("Going to delete classic load balancer %s", name)
+	_, err := client.ClassicElbClient.DeleteLoadBalancer(context.TODO(), &elasticloadbalancing.DeleteLoadBalancerInput{
+		LoadBalancerName: &name,
+	})
+	if err != nil {
+		log.LogError("Delete classic load balancer %s failed: %s", name, err)
+	}
+	return err
+}
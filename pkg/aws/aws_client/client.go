@@ -7,12 +7,16 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/ram"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/openshift-online/ocm-common/pkg/log"
@@ -37,6 +41,10 @@ type AWSClient struct {
 	CloudWatchLogsClient *cloudwatchlogs.Client
 	AWSConfig            *aws.Config
 	RamClient            *ram.Client
+	AcmClient            *acm.Client
+	ClassicElbClient     *elasticloadbalancing.Client
+	S3Client             *s3.Client
+	CloudTrailClient     *cloudtrail.Client
 }
 
 type AccessKeyMod struct {
@@ -101,6 +109,10 @@ func CreateAWSClient(profileName string, region string, awsSharedCredentialFile
 		AWSConfig:            &cfg,
 		RamClient:            ram.NewFromConfig(cfg),
 		CloudWatchLogsClient: cloudwatchlogs.NewFromConfig(cfg),
+		AcmClient:            acm.NewFromConfig(cfg),
+		ClassicElbClient:     elasticloadbalancing.NewFromConfig(cfg),
+		S3Client:             s3.NewFromConfig(cfg),
+		CloudTrailClient:     cloudtrail.NewFromConfig(cfg),
 	}
 	awsClient.AccountID = awsClient.GetAWSAccountID()
 	return awsClient, nil
@@ -0,0 +1,167 @@
+package aws_client
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// CloudTrailRetention is how far back LookupEvents can find management events
+const CloudTrailRetention = 90 * 24 * time.Hour
+
+var (
+	createEventPrefixes = []string{"Create", "Run", "Allocate", "Import", "Register", "Request"}
+	deleteEventPrefixes = []string{"Delete", "Terminate", "Release", "Deregister"}
+)
+
+// ResourceEvent is a CloudTrail management event with the details of the caller
+type ResourceEvent struct {
+	EventID     string
+	EventName   string
+	EventSource string
+	EventTime   time.Time
+	// Principal is the ARN of the caller, for assumed roles it contains the session name which usually names the job
+	Principal     string
+	PrincipalType string
+	Username      string
+	AccessKeyID   string
+	SourceIP      string
+	UserAgent     string
+	ErrorCode     string
+	// RequestParameters are the parameters of the API call as recorded by CloudTrail
+	RequestParameters map[string]interface{}
+}
+
+// IsCreate tells if the event created a resource, like CreateVpc or RunInstances
+func (e *ResourceEvent) IsCreate() bool {
+	return hasAnyPrefix(e.EventName, createEventPrefixes)
+}
+
+// IsDelete tells if the event deleted a resource, like DeleteVpc or TerminateInstances
+func (e *ResourceEvent) IsDelete() bool {
+	return hasAnyPrefix(e.EventName, deleteEventPrefixes)
+}
+
+// cloudTrailRecord is the subset of the CloudTrail record JSON we use
+type cloudTrailRecord struct {
+	UserIdentity struct {
+		Type string `json:"type"`
+		Arn  string `json:"arn"`
+	} `json:"userIdentity"`
+	SourceIPAddress   string                 `json:"sourceIPAddress"`
+	UserAgent         string                 `json:"userAgent"`
+	ErrorCode         string                 `json:"errorCode"`
+	RequestParameters map[string]interface{} `json:"requestParameters"`
+}
+
+// LookupEvents returns the management events matching the attribute between start and end, the oldest first.
+// A zero start defaults to CloudTrailRetention ago and a zero end to now.
+func (client *AWSClient) LookupEvents(attributeKey types.LookupAttributeKey, attributeValue string,
+	start time.Time, end time.Time) ([]ResourceEvent, error) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		start = end.Add(-CloudTrailRetention)
+	}
+	input := &cloudtrail.LookupEventsInput{
+		LookupAttributes: []types.LookupAttribute{
+			{
+				AttributeKey:   attributeKey,
+				AttributeValue: &attributeValue,
+			},
+		},
+		StartTime: &start,
+		EndTime:   &end,
+	}
+	events := []ResourceEvent{}
+	paginator := cloudtrail.NewLookupEventsPaginator(client.CloudTrailClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("Lookup CloudTrail events with %s %s failed: %s", attributeKey, attributeValue, err)
+			return nil, err
+		}
+		for _, event := range page.Events {
+			events = append(events, toResourceEvent(event))
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].EventTime.Before(events[j].EventTime) })
+	return events, nil
+}
+
+// LookupResourceEvents returns the events of the resource, identified by its ID, like an instance ID, or its name,
+// like a role name, the oldest first. See LookupEvents for start and end.
+func (client *AWSClient) LookupResourceEvents(resource string, start time.Time, end time.Time) ([]ResourceEvent, error) {
+	return client.LookupEvents(types.LookupAttributeKeyResourceName, resource, start, end)
+}
+
+// LookupCreateDeleteEvents returns the events that created or deleted the resource, the oldest first
+func (client *AWSClient) LookupCreateDeleteEvents(resource string, start time.Time,
+	end time.Time) ([]ResourceEvent, error) {
+	events, err := client.LookupResourceEvents(resource, start, end)
+	if err != nil {
+		return nil, err
+	}
+	lifecycleEvents := []ResourceEvent{}
+	for _, event := range events {
+		if event.IsCreate() || event.IsDelete() {
+			lifecycleEvents = append(lifecycleEvents, event)
+		}
+	}
+	return lifecycleEvents, nil
+}
+
+// FindResourceCreator returns the successful event that created the resource, or nil when CloudTrail has no
+// record of it, for example when it was created more than CloudTrailRetention ago
+func (client *AWSClient) FindResourceCreator(resource string) (*ResourceEvent, error) {
+	events, err := client.LookupCreateDeleteEvents(resource, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.IsCreate() && event.ErrorCode == "" {
+			return &event, nil
+		}
+	}
+	return nil, nil
+}
+
+func toResourceEvent(event types.Event) ResourceEvent {
+	resourceEvent := ResourceEvent{
+		EventID:     aws.ToString(event.EventId),
+		EventName:   aws.ToString(event.EventName),
+		EventSource: aws.ToString(event.EventSource),
+		EventTime:   aws.ToTime(event.EventTime),
+		Username:    aws.ToString(event.Username),
+		AccessKeyID: aws.ToString(event.AccessKeyId),
+	}
+	record := cloudTrailRecord{}
+	if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &record); err != nil {
+		log.LogWarning("Parse CloudTrail record of event %s failed: %s", resourceEvent.EventID, err)
+		return resourceEvent
+	}
+	resourceEvent.Principal = record.UserIdentity.Arn
+	resourceEvent.PrincipalType = record.UserIdentity.Type
+	resourceEvent.SourceIP = record.SourceIPAddress
+	resourceEvent.UserAgent = record.UserAgent
+	resourceEvent.ErrorCode = record.ErrorCode
+	resourceEvent.RequestParameters = record.RequestParameters
+	return resourceEvent
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/openshift-online/ocm-common/pkg/log"
 )
 
@@ -87,3 +88,25 @@ func (client *AWSClient) ReleaseAddress(allocationID string) (*ec2.ReleaseAddres
 	log.LogInfo("Release eip success: " + allocationID)
 	return respRelease, err
 }
+
+// ListAddresses pass parameter like
+// map[string][]string{"public-ip":[]string{"<ip>" }}, map[string][]string{"tag-key":[]string{"<key>" }}
+func (client *AWSClient) ListAddresses(filters ...map[string][]string) ([]types.Address, error) {
+	filterInput := []types.Filter{}
+	for _, filter := range filters {
+		for k, v := range filter {
+			filterInput = append(filterInput, types.Filter{
+				Name:   aws.String(k),
+				Values: v,
+			})
+		}
+	}
+	output, err := client.Ec2Client.DescribeAddresses(context.TODO(), &ec2.DescribeAddressesInput{
+		Filters: filterInput,
+	})
+	if err != nil {
+		log.LogError("List addresses failed with filters %v: %s", filters, err)
+		return nil, err
+	}
+	return output.Addresses, nil
+}
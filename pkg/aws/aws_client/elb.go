@@ -3,6 +3,7 @@ package aws_client
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 
 	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
@@ -37,3 +38,47 @@ func (client *AWSClient) DeleteELB(ELB elbtypes.LoadBalancer) error {
 	_, err := client.ElbClient.DeleteLoadBalancer(context.TODO(), deleteELBInput)
 	return err
 }
+
+// ListLoadBalancersByTag returns the load balancers having the tag key. When tagValue is empty only the
+// key is checked
+func (client *AWSClient) ListLoadBalancersByTag(tagKey string, tagValue string) ([]elbtypes.LoadBalancer, error) {
+	loadBalancers := map[string]elbtypes.LoadBalancer{}
+	arns := []string{}
+	paginator := elb.NewDescribeLoadBalancersPaginator(client.ElbClient, &elb.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List load balancers failed: %s", err)
+			return nil, err
+		}
+		for _, lb := range page.LoadBalancers {
+			loadBalancers[*lb.LoadBalancerArn] = lb
+			arns = append(arns, *lb.LoadBalancerArn)
+		}
+	}
+
+	tagged := []elbtypes.LoadBalancer{}
+	// DescribeTags accepts at most 20 load balancers per call
+	for start := 0; start < len(arns); start += 20 {
+		end := start + 20
+		if end > len(arns) {
+			end = len(arns)
+		}
+		output, err := client.ElbClient.DescribeTags(context.TODO(), &elb.DescribeTagsInput{
+			ResourceArns: arns[start:end],
+		})
+		if err != nil {
+			log.LogError("Describe load balancer tags failed: %s", err)
+			return nil, err
+		}
+		for _, description := range output.TagDescriptions {
+			for _, tag := range description.Tags {
+				if aws.ToString(tag.Key) == tagKey && (tagValue == "" || aws.ToString(tag.Value) == tagValue) {
+					tagged = append(tagged, loadBalancers[aws.ToString(description.ResourceArn)])
+					break
+				}
+			}
+		}
+	}
+	return tagged, nil
+}
@@ -0,0 +1,317 @@
+package aws_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+
+	awsErrors "github.com/openshift-online/ocm-common/pkg/aws/errors"
+	awsUtils "github.com/openshift-online/ocm-common/pkg/aws/utils"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// The Ensure functions create a resource only when there is no resource with the same name yet, so that jobs
+// can be re-run after a failure. When the resource exists and matches the request it is returned, when it
+// doesn't match a ConflictError is returned. The returned flag tells if the resource was created by the call.
+
+// ConflictError reports an existing resource with the requested name that doesn't match the request
+type ConflictError struct {
+	ResourceType string
+	Name         string
+	Reason       string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s '%s' already exists and doesn't match the request: %s", e.ResourceType, e.Name,
+		e.Reason)
+}
+
+// IsConflictError tells if the error is a ConflictError
+func IsConflictError(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
+
+func newConflictError(resourceType string, name string, format string, args ...interface{}) error {
+	return &ConflictError{
+		ResourceType: resourceType,
+		Name:         name,
+		Reason:       fmt.Sprintf(format, args...),
+	}
+}
+
+// EnsureRole returns the role with the name, creating it when missing. An existing role conflicts when its
+// path, permissions boundary or trust policy statements differ from the requested ones. Tags are not compared.
+func (client *AWSClient) EnsureRole(roleName string, assumeRolePolicyDocument string, permissionBoundry string,
+	tags map[string]string, path string) (iamtypes.Role, bool, error) {
+	existing, err := client.GetRole(roleName)
+	if err != nil {
+		if !awsErrors.IsNoSuchEntityException(err) {
+			return iamtypes.Role{}, false, err
+		}
+		role, err := client.CreateRole(roleName, assumeRolePolicyDocument, permissionBoundry, tags, path)
+		return role, role.RoleName != nil, err
+	}
+
+	if path != "" && aws.ToString(existing.Path) != path {
+		return *existing, false, newConflictError("role", roleName, "path is '%s' instead of '%s'",
+			aws.ToString(existing.Path), path)
+	}
+	existingBoundary := ""
+	if existing.PermissionsBoundary != nil {
+		existingBoundary = aws.ToString(existing.PermissionsBoundary.PermissionsBoundaryArn)
+	}
+	if existingBoundary != permissionBoundry {
+		return *existing, false, newConflictError("role", roleName,
+			"permissions boundary is '%s' instead of '%s'", existingBoundary, permissionBoundry)
+	}
+	trustPolicy, err := awsUtils.DecodePolicyDocument(aws.ToString(existing.AssumeRolePolicyDocument))
+	if err != nil {
+		return *existing, false, err
+	}
+	diff, err := awsUtils.DiffPolicyDocuments(trustPolicy, assumeRolePolicyDocument)
+	if err != nil {
+		return *existing, false, err
+	}
+	if !diff.Empty() {
+		return *existing, false, newConflictError("role", roleName,
+			"trust policy differs, missing statements %v, extra statements %v", diff.Added, diff.Removed)
+	}
+	log.LogInfo("Role %s already exists, reusing it", roleName)
+	return *existing, false, nil
+}
+
+// EnsureIAMPolicy returns the customer managed policy with the name, creating it when missing. An existing
+// policy conflicts when the statements of its default version differ from the requested document.
+func (client *AWSClient) EnsureIAMPolicy(policyName string, policyDocument string,
+	tags map[string]string) (*iamtypes.Policy, bool, error) {
+	existing, err := client.findCustomerPolicy(policyName)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		policy, err := client.CreateIAMPolicy(policyName, policyDocument, tags)
+		return policy, err == nil, err
+	}
+
+	version, err := client.GetPolicyVersion(aws.ToString(existing.Arn), aws.ToString(existing.DefaultVersionId))
+	if err != nil {
+		return existing, false, err
+	}
+	diff, err := awsUtils.DiffPolicyDocuments(version.Document, policyDocument)
+	if err != nil {
+		return existing, false, err
+	}
+	if !diff.Empty() {
+		return existing, false, newConflictError("policy", policyName,
+			"default version %s differs, missing statements %v, extra statements %v", version.VersionID,
+			diff.Added, diff.Removed)
+	}
+	log.LogInfo("Policy %s already exists, reusing it", policyName)
+	return existing, false, nil
+}
+
+func (client *AWSClient) findCustomerPolicy(policyName string) (*iamtypes.Policy, error) {
+	paginator := iam.NewListPoliciesPaginator(client.IamClient, &iam.ListPoliciesInput{
+		Scope: iamtypes.PolicyScopeTypeLocal,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List customer policies failed: %s", err)
+			return nil, err
+		}
+		for _, policy := range page.Policies {
+			if aws.ToString(policy.PolicyName) == policyName {
+				return &policy, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// EnsureKeyPair returns the key pair with the name, creating it when missing. The private key material is only
+// returned when the key pair is created, AWS doesn't keep it.
+func (client *AWSClient) EnsureKeyPair(keyName string) (*ec2.CreateKeyPairOutput, bool, error) {
+	keyPairs, err := client.ListKeyPairs(map[string][]string{"key-name": {keyName}})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(keyPairs) == 0 {
+		output, err := client.CreateKeyPair(keyName)
+		return output, err == nil, err
+	}
+	log.LogInfo("Key pair %s already exists, reusing it", keyName)
+	return &ec2.CreateKeyPairOutput{
+		KeyName:        keyPairs[0].KeyName,
+		KeyPairId:      keyPairs[0].KeyPairId,
+		KeyFingerprint: keyPairs[0].KeyFingerprint,
+		Tags:           keyPairs[0].Tags,
+	}, false, nil
+}
+
+// EnsureSecurityGroup returns the security group with the name in the VPC, creating it when missing. An existing
+// security group conflicts when its description differs.
+func (client *AWSClient) EnsureSecurityGroup(vpcID string, groupName string,
+	sgDescription string) (*ec2types.SecurityGroup, bool, error) {
+	securityGroups, err := client.ListSecurityGroupsByFilters(map[string][]string{
+		"vpc-id":     {vpcID},
+		"group-name": {groupName},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(securityGroups) == 0 {
+		output, err := client.CreateSecurityGroup(vpcID, groupName, sgDescription)
+		if output == nil {
+			return nil, false, err
+		}
+		if err != nil {
+			return nil, true, err
+		}
+		described, err := client.GetSecurityGroupWithID(*output.GroupId)
+		if err != nil {
+			return nil, true, err
+		}
+		return &described.SecurityGroups[0], true, nil
+	}
+
+	existing := securityGroups[0]
+	if aws.ToString(existing.Description) != sgDescription {
+		return &existing, false, newConflictError("security group", groupName,
+			"description is '%s' instead of '%s'", aws.ToString(existing.Description), sgDescription)
+	}
+	log.LogInfo("Security group %s already exists in %s, reusing it", groupName, vpcID)
+	return &existing, false, nil
+}
+
+// EnsureVpc returns the VPC tagged with the name, creating it when missing. An existing VPC conflicts when its
+// CIDR block differs, and several VPCs with the name are reported as a conflict as well.
+func (client *AWSClient) EnsureVpc(cidr string, vpcName string) (*ec2types.Vpc, bool, error) {
+	vpcs, err := client.ListVPCByName(vpcName)
+	if err != nil {
+		return nil, false, err
+	}
+	switch len(vpcs) {
+	case 0:
+		output, err := client.CreateVpc(cidr, vpcName)
+		if output == nil {
+			return nil, false, err
+		}
+		return output.Vpc, true, err
+	case 1:
+	default:
+		return nil, false, newConflictError("VPC", vpcName, "%d VPCs have the name", len(vpcs))
+	}
+
+	existing := vpcs[0]
+	if aws.ToString(existing.CidrBlock) != cidr {
+		return &existing, false, newConflictError("VPC", vpcName, "CIDR block is '%s' instead of '%s'",
+			aws.ToString(existing.CidrBlock), cidr)
+	}
+	log.LogInfo("VPC %s already exists with ID %s, reusing it", vpcName, aws.ToString(existing.VpcId))
+	return &existing, false, nil
+}
+
+// EnsureHostedZone returns the hosted zone with the name matching the privacy and, for a private zone, associated
+// with the VPC, creating it when none matches. A public and private zones, or private zones of different VPCs,
+// can share the name.
+func (client *AWSClient) EnsureHostedZone(hostedZoneName string, callerReference string, vpcID string,
+	region string, private bool) (*route53types.HostedZone, bool, error) {
+	zones, err := client.ListHostedZonesByName(hostedZoneName)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, existing := range zones {
+		existingPrivate := existing.Config != nil && existing.Config.PrivateZone
+		if existingPrivate != private {
+			continue
+		}
+		if private && vpcID != "" {
+			zone, err := client.GetHostedZone(aws.ToString(existing.Id))
+			if err != nil {
+				return nil, false, err
+			}
+			associated := false
+			for _, vpc := range zone.VPCs {
+				if aws.ToString(vpc.VPCId) == vpcID {
+					associated = true
+				}
+			}
+			if !associated {
+				continue
+			}
+		}
+		log.LogInfo("Hosted zone %s already exists with ID %s, reusing it", hostedZoneName,
+			aws.ToString(existing.Id))
+		return &existing, false, nil
+	}
+
+	output, err := client.CreateHostedZone(hostedZoneName, callerReference, vpcID, region, private)
+	if err != nil {
+		return nil, false, err
+	}
+	return output.HostedZone, true, nil
+}
+
+// EnsureKMSKey returns the KMS key with the alias, creating the key and the alias when missing. The alias is
+// given without the 'alias/' prefix. An existing key conflicts when it is not enabled or when its multi-region
+// setting differs.
+func (client *AWSClient) EnsureKMSKey(alias string, tagKey string, tagValue string, description string,
+	policy string, multiRegion bool) (*kmstypes.KeyMetadata, bool, error) {
+	aliasName := "alias/" + alias
+	output, err := client.KmsClient.DescribeKey(context.TODO(), &kms.DescribeKeyInput{
+		KeyId: aws.String(aliasName),
+	})
+	if err != nil {
+		var notFound *kmstypes.NotFoundException
+		if !errors.As(err, &notFound) {
+			log.LogError("Describe KMS key %s failed: %s", aliasName, err)
+			return nil, false, err
+		}
+		return client.createKMSKeyWithAlias(aliasName, tagKey, tagValue, description, policy, multiRegion)
+	}
+
+	existing := output.KeyMetadata
+	if existing.KeyState != kmstypes.KeyStateEnabled {
+		return existing, false, newConflictError("KMS key", aliasName, "key %s is in state %s",
+			aws.ToString(existing.KeyId), existing.KeyState)
+	}
+	if aws.ToBool(existing.MultiRegion) != multiRegion {
+		return existing, false, newConflictError("KMS key", aliasName, "multi-region is %t instead of %t",
+			aws.ToBool(existing.MultiRegion), multiRegion)
+	}
+	log.LogInfo("KMS key %s already exists with ID %s, reusing it", aliasName, aws.ToString(existing.KeyId))
+	return existing, false, nil
+}
+
+func (client *AWSClient) createKMSKeyWithAlias(aliasName string, tagKey string, tagValue string,
+	description string, policy string, multiRegion bool) (*kmstypes.KeyMetadata, bool, error) {
+	keyID, _, err := client.CreateKMSKeys(tagKey, tagValue, description, policy, multiRegion)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = client.KmsClient.CreateAlias(context.TODO(), &kms.CreateAliasInput{
+		AliasName:   aws.String(aliasName),
+		TargetKeyId: aws.String(keyID),
+	})
+	if err != nil {
+		log.LogError("Create alias %s for KMS key %s failed: %s", aliasName, keyID, err)
+		return nil, true, err
+	}
+	log.LogInfo("Created KMS key %s with alias %s", keyID, aliasName)
+	output, err := client.KmsClient.DescribeKey(context.TODO(), &kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return nil, true, err
+	}
+	return output.KeyMetadata, true, nil
+}
@@ -29,7 +29,12 @@ func (client *AWSClient) LaunchInstance(subnetID string, imageID string, count i
 	if len(userDate) > 0 {
 		input.UserData = &userDate[0]
 	}
+	return client.RunInstances(input, wait)
+}
 
+// RunInstances launches the instances described by the input, for the cases LaunchInstance doesn't cover.
+// When wait is true it waits for the instances to be running.
+func (client *AWSClient) RunInstances(input *ec2.RunInstancesInput, wait bool) (*ec2.RunInstancesOutput, error) {
 	output, err := client.Ec2Client.RunInstances(context.TODO(), input)
 	if wait && err == nil {
 		instanceIDs := []string{}
@@ -263,6 +268,50 @@ func (client *AWSClient) GetTagsOfInstanceProfile(instanceProfileName string) ([
 	return tags, err
 }
 
+// ListInstanceProfiles returns the instance profiles with the path prefix, all of them when it is empty
+func (client *AWSClient) ListInstanceProfiles(pathPrefix string) ([]iamtypes.InstanceProfile, error) {
+	input := &iam.ListInstanceProfilesInput{}
+	if pathPrefix != "" {
+		input.PathPrefix = &pathPrefix
+	}
+	instanceProfiles := []iamtypes.InstanceProfile{}
+	paginator := iam.NewListInstanceProfilesPaginator(client.IamClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List instance profiles failed: %s", err)
+			return nil, err
+		}
+		instanceProfiles = append(instanceProfiles, page.InstanceProfiles...)
+	}
+	return instanceProfiles, nil
+}
+
+// DeleteInstanceProfile removes the roles from the instance profile and deletes it, the roles are kept
+func (client *AWSClient) DeleteInstanceProfile(instanceProfileName string) error {
+	out, err := client.IamClient.GetInstanceProfile(context.TODO(), &iam.GetInstanceProfileInput{
+		InstanceProfileName: &instanceProfileName,
+	})
+	if err != nil {
+		return err
+	}
+	for _, role := range out.InstanceProfile.Roles {
+		_, err = client.IamClient.RemoveRoleFromInstanceProfile(context.TODO(), &iam.RemoveRoleFromInstanceProfileInput{
+			InstanceProfileName: &instanceProfileName,
+			RoleName:            role.RoleName,
+		})
+		if err != nil {
+			log.LogError("Remove role %s from instance profile %s failed: %s", aws.ToString(role.RoleName),
+				instanceProfileName, err)
+			return err
+		}
+	}
+	_, err = client.IamClient.DeleteInstanceProfile(context.TODO(), &iam.DeleteInstanceProfileInput{
+		InstanceProfileName: &instanceProfileName,
+	})
+	return err
+}
+
 func GetInstanceName(instance *types.Instance) string {
 	tags := instance.Tags
 	for _, tag := range tags {
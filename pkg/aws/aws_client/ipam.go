@@ -0,0 +1,59 @@
+package aws_client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// AllocateIpamPoolCidr allocates a CIDR of the netmask length from the IPAM pool. The description is recorded
+// with the allocation to find its owner.
+func (client *AWSClient) AllocateIpamPoolCidr(poolID string, netmaskLength int32,
+	description string) (*types.IpamPoolAllocation, error) {
+	output, err := client.Ec2Client.AllocateIpamPoolCidr(context.TODO(), &ec2.AllocateIpamPoolCidrInput{
+		IpamPoolId:    aws.String(poolID),
+		NetmaskLength: aws.Int32(netmaskLength),
+		Description:   aws.String(description),
+	})
+	if err != nil {
+		log.LogError("Allocate CIDR /%d from IPAM pool %s failed: %s", netmaskLength, poolID, err)
+		return nil, err
+	}
+	log.LogInfo("Allocated CIDR %s from IPAM pool %s", aws.ToString(output.IpamPoolAllocation.Cidr), poolID)
+	return output.IpamPoolAllocation, nil
+}
+
+// ListIpamPoolAllocations returns the allocations of the IPAM pool
+func (client *AWSClient) ListIpamPoolAllocations(poolID string) ([]types.IpamPoolAllocation, error) {
+	allocations := []types.IpamPoolAllocation{}
+	paginator := ec2.NewGetIpamPoolAllocationsPaginator(client.Ec2Client, &ec2.GetIpamPoolAllocationsInput{
+		IpamPoolId: aws.String(poolID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List allocations of IPAM pool %s failed: %s", poolID, err)
+			return nil, err
+		}
+		allocations = append(allocations, page.IpamPoolAllocations...)
+	}
+	return allocations, nil
+}
+
+// ReleaseIpamPoolAllocation releases the CIDR allocated from the IPAM pool
+func (client *AWSClient) ReleaseIpamPoolAllocation(poolID string, allocationID string, cidr string) error {
+	_, err := client.Ec2Client.ReleaseIpamPoolAllocation(context.TODO(), &ec2.ReleaseIpamPoolAllocationInput{
+		IpamPoolId:           aws.String(poolID),
+		IpamPoolAllocationId: aws.String(allocationID),
+		Cidr:                 aws.String(cidr),
+	})
+	if err != nil {
+		log.LogError("Release CIDR %s of IPAM pool %s failed: %s", cidr, poolID, err)
+		return err
+	}
+	log.LogInfo("Released CIDR %s of IPAM pool %s", cidr, poolID)
+	return nil
+}
@@ -3,7 +3,9 @@ package aws_client
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
 func (client *AWSClient) CreateKeyPair(keyName string) (*ec2.CreateKeyPairOutput, error) {
@@ -32,3 +34,24 @@ func (client *AWSClient) DeleteKeyPair(keyName string) (*ec2.DeleteKeyPairOutput
 	return output, err
 
 }
+
+// ListKeyPairs pass parameter like
+// map[string][]string{"key-name":[]string{"<name>" }}, map[string][]string{"tag-key":[]string{"<key>" }}
+func (client *AWSClient) ListKeyPairs(filters ...map[string][]string) ([]types.KeyPairInfo, error) {
+	filterInput := []types.Filter{}
+	for _, filter := range filters {
+		for k, v := range filter {
+			filterInput = append(filterInput, types.Filter{
+				Name:   aws.String(k),
+				Values: v,
+			})
+		}
+	}
+	output, err := client.Ec2Client.DescribeKeyPairs(context.TODO(), &ec2.DescribeKeyPairsInput{
+		Filters: filterInput,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.KeyPairs, nil
+}
@@ -26,7 +26,7 @@ func (client *AWSClient) CreateKMSKeys(tagKey string, tagValue string, descripti
 
 	if err != nil {
 		log.LogError("Got error creating key: %s", err)
-
+		return "", "", err
 	}
 
 	return *result.KeyMetadata.KeyId, *result.KeyMetadata.Arn, err
@@ -3,6 +3,7 @@ package aws_client
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/openshift-online/ocm-common/pkg/log"
@@ -52,3 +53,30 @@ func (client *AWSClient) DeleteNetworkInterface(networkinterface types.NetworkIn
 	}
 	return err
 }
+
+// ListNetworkInterfaces pass parameter like
+// map[string][]string{"vpc-id":[]string{"<id>" }}, map[string][]string{"tag-key":[]string{"<key>" }}
+func (client *AWSClient) ListNetworkInterfaces(filters ...map[string][]string) ([]types.NetworkInterface, error) {
+	filterInput := []types.Filter{}
+	for _, filter := range filters {
+		for k, v := range filter {
+			filterInput = append(filterInput, types.Filter{
+				Name:   aws.String(k),
+				Values: v,
+			})
+		}
+	}
+	networkInterfaces := []types.NetworkInterface{}
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(client.Ec2Client, &ec2.DescribeNetworkInterfacesInput{
+		Filters: filterInput,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List network interfaces failed with filters %v: %s", filters, err)
+			return nil, err
+		}
+		networkInterfaces = append(networkInterfaces, page.NetworkInterfaces...)
+	}
+	return networkInterfaces, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 )
 
 func (client *AWSClient) DeleteOIDCProvider(providerArn string) error {
@@ -13,3 +14,19 @@ func (client *AWSClient) DeleteOIDCProvider(providerArn string) error {
 	_, err := client.IamClient.DeleteOpenIDConnectProvider(context.TODO(), input)
 	return err
 }
+
+// ListOIDCProviders returns the ARNs of the IAM OIDC providers of the account
+func (client *AWSClient) ListOIDCProviders() ([]types.OpenIDConnectProviderListEntry, error) {
+	output, err := client.IamClient.ListOpenIDConnectProviders(context.TODO(), &iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return nil, err
+	}
+	return output.OpenIDConnectProviderList, nil
+}
+
+// GetOIDCProvider returns the issuer URL, without scheme, the tags and the creation date of the IAM OIDC provider
+func (client *AWSClient) GetOIDCProvider(providerArn string) (*iam.GetOpenIDConnectProviderOutput, error) {
+	return client.IamClient.GetOpenIDConnectProvider(context.TODO(), &iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: &providerArn,
+	})
+}
@@ -38,7 +38,10 @@ func (client *AWSClient) GetIAMPolicy(policyArn string) (*types.Policy, error) {
 		PolicyArn: &policyArn,
 	}
 	out, err := client.IamClient.GetPolicy(context.TODO(), input)
-	return out.Policy, err
+	if err != nil {
+		return nil, err
+	}
+	return out.Policy, nil
 }
 
 func (client *AWSClient) DeleteIAMPolicy(arn string) error {
@@ -85,6 +88,43 @@ func (client *AWSClient) GetCustomerIAMPolicies() ([]types.Policy, error) {
 	return out.Policies, err
 
 }
+
+// ListCustomerIAMPolicies returns all the customer managed policies, GetCustomerIAMPolicies only returns the first
+// 1000 of them
+func (client *AWSClient) ListCustomerIAMPolicies() ([]types.Policy, error) {
+	policies := []types.Policy{}
+	paginator := iam.NewListPoliciesPaginator(client.IamClient, &iam.ListPoliciesInput{
+		Scope: types.PolicyScopeTypeLocal,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List customer policies failed: %s", err)
+			return nil, err
+		}
+		policies = append(policies, page.Policies...)
+	}
+	return policies, nil
+}
+
+// ListPolicyTags returns the tags of the policy, ListPolicies doesn't return them
+func (client *AWSClient) ListPolicyTags(policyArn string) ([]types.Tag, error) {
+	tags := []types.Tag{}
+	paginator := iam.NewListPolicyTagsPaginator(client.IamClient, &iam.ListPolicyTagsInput{
+		PolicyArn: &policyArn,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+	}
+	return tags, nil
+}
+
+// CleanByOutDate and CleanByName are the rules of the QE CI accounts, the test/cleaner package composes rules for
+// other accounts
 func CleanByOutDate(policy types.Policy) bool {
 	now := time.Now().UTC()
 	return policy.CreateDate.Add(7 * time.Hour * 24).Before(now)
@@ -0,0 +1,175 @@
+package aws_client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+
+	awsUtils "github.com/openshift-online/ocm-common/pkg/aws/utils"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// MaxPolicyVersions is the maximum number of versions IAM keeps for a managed policy
+const MaxPolicyVersions = 5
+
+// PolicyVersion is a version of a managed policy with its decoded JSON document
+type PolicyVersion struct {
+	VersionID  string
+	IsDefault  bool
+	CreateDate time.Time
+	Document   string
+}
+
+// ListPolicyVersions returns the versions of the policy with their documents, the newest first
+func (client *AWSClient) ListPolicyVersions(policyArn string) ([]PolicyVersion, error) {
+	versions := []PolicyVersion{}
+	paginator := iam.NewListPolicyVersionsPaginator(client.IamClient, &iam.ListPolicyVersionsInput{
+		PolicyArn: &policyArn,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List versions of policy %s failed: %s", policyArn, err)
+			return nil, err
+		}
+		for _, version := range page.Versions {
+			// The listing doesn't return the documents
+			policyVersion, err := client.GetPolicyVersion(policyArn, aws.ToString(version.VersionId))
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, *policyVersion)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].CreateDate.After(versions[j].CreateDate) })
+	return versions, nil
+}
+
+// GetPolicyVersion returns the version of the policy with its document
+func (client *AWSClient) GetPolicyVersion(policyArn string, versionID string) (*PolicyVersion, error) {
+	output, err := client.IamClient.GetPolicyVersion(context.TODO(), &iam.GetPolicyVersionInput{
+		PolicyArn: &policyArn,
+		VersionId: &versionID,
+	})
+	if err != nil {
+		log.LogError("Get version %s of policy %s failed: %s", versionID, policyArn, err)
+		return nil, err
+	}
+	document, err := awsUtils.DecodePolicyDocument(aws.ToString(output.PolicyVersion.Document))
+	if err != nil {
+		return nil, err
+	}
+	return &PolicyVersion{
+		VersionID:  aws.ToString(output.PolicyVersion.VersionId),
+		IsDefault:  output.PolicyVersion.IsDefaultVersion,
+		CreateDate: aws.ToTime(output.PolicyVersion.CreateDate),
+		Document:   document,
+	}, nil
+}
+
+// CreatePolicyVersion publishes the document as the new default version of the policy. When the policy already
+// has MaxPolicyVersions versions the oldest non-default one is deleted first.
+func (client *AWSClient) CreatePolicyVersion(policyArn string, document string) (*PolicyVersion, error) {
+	versions, err := client.ListPolicyVersions(policyArn)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) >= MaxPolicyVersions {
+		// Versions are sorted from the newest, so the first non-default found from the end is the oldest
+		for i := len(versions) - 1; i >= 0; i-- {
+			if versions[i].IsDefault {
+				continue
+			}
+			if err = client.DeletePolicyVersion(policyArn, versions[i].VersionID); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	output, err := client.IamClient.CreatePolicyVersion(context.TODO(), &iam.CreatePolicyVersionInput{
+		PolicyArn:      &policyArn,
+		PolicyDocument: &document,
+		SetAsDefault:   true,
+	})
+	if err != nil {
+		log.LogError("Create version of policy %s failed: %s", policyArn, err)
+		return nil, err
+	}
+	log.LogInfo("Created version %s of policy %s", aws.ToString(output.PolicyVersion.VersionId), policyArn)
+	return &PolicyVersion{
+		VersionID:  aws.ToString(output.PolicyVersion.VersionId),
+		IsDefault:  output.PolicyVersion.IsDefaultVersion,
+		CreateDate: aws.ToTime(output.PolicyVersion.CreateDate),
+		Document:   document,
+	}, nil
+}
+
+// DeletePolicyVersion deletes a non-default version of the policy
+func (client *AWSClient) DeletePolicyVersion(policyArn string, versionID string) error {
+	_, err := client.IamClient.DeletePolicyVersion(context.TODO(), &iam.DeletePolicyVersionInput{
+		PolicyArn: &policyArn,
+		VersionId: &versionID,
+	})
+	if err != nil {
+		log.LogError("Delete version %s of policy %s failed: %s", versionID, policyArn, err)
+		return err
+	}
+	log.LogInfo("Deleted version %s of policy %s", versionID, policyArn)
+	return nil
+}
+
+// SetDefaultPolicyVersion makes the version the one in effect for the policy
+func (client *AWSClient) SetDefaultPolicyVersion(policyArn string, versionID string) error {
+	_, err := client.IamClient.SetDefaultPolicyVersion(context.TODO(), &iam.SetDefaultPolicyVersionInput{
+		PolicyArn: &policyArn,
+		VersionId: &versionID,
+	})
+	if err != nil {
+		log.LogError("Set default version %s of policy %s failed: %s", versionID, policyArn, err)
+	}
+	return err
+}
+
+// RollbackPolicyVersion makes the version created just before the current default one the default version and
+// returns its ID. The current default version is kept.
+func (client *AWSClient) RollbackPolicyVersion(policyArn string) (string, error) {
+	versions, err := client.ListPolicyVersions(policyArn)
+	if err != nil {
+		return "", err
+	}
+	for i, version := range versions {
+		if !version.IsDefault {
+			continue
+		}
+		if i+1 >= len(versions) {
+			return "", fmt.Errorf("policy %s has no version older than the default version %s",
+				policyArn, version.VersionID)
+		}
+		previous := versions[i+1].VersionID
+		if err = client.SetDefaultPolicyVersion(policyArn, previous); err != nil {
+			return "", err
+		}
+		log.LogInfo("Rolled back policy %s from version %s to %s", policyArn, version.VersionID, previous)
+		return previous, nil
+	}
+	return "", fmt.Errorf("policy %s has no default version", policyArn)
+}
+
+// DiffPolicyVersions returns the statements added and removed going from one version of the policy to the other
+func (client *AWSClient) DiffPolicyVersions(policyArn string, fromVersionID string,
+	toVersionID string) (*awsUtils.PolicyDocumentDiff, error) {
+	from, err := client.GetPolicyVersion(policyArn, fromVersionID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := client.GetPolicyVersion(policyArn, toVersionID)
+	if err != nil {
+		return nil, err
+	}
+	return awsUtils.DiffPolicyDocuments(from.Document, to.Document)
+}
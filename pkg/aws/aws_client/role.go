@@ -74,7 +74,10 @@ func (client *AWSClient) GetRole(roleName string) (*types.Role, error) {
 		RoleName: &roleName,
 	}
 	out, err := client.IamClient.GetRole(context.TODO(), input)
-	return out.Role, err
+	if err != nil {
+		return nil, err
+	}
+	return out.Role, nil
 }
 func (client *AWSClient) DeleteRole(roleName string) error {
 
@@ -113,9 +116,32 @@ func (client *AWSClient) DeleteRoleAndPolicy(roleName string, managedPolicy bool
 }
 
 func (client *AWSClient) ListRoles() ([]types.Role, error) {
-	input := &iam.ListRolesInput{}
-	out, err := client.IamClient.ListRoles(context.TODO(), input)
-	return out.Roles, err
+	roles := []types.Role{}
+	paginator := iam.NewListRolesPaginator(client.IamClient, &iam.ListRolesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, page.Roles...)
+	}
+	return roles, nil
+}
+
+// ListRoleTags returns the tags of the role, ListRoles doesn't return them
+func (client *AWSClient) ListRoleTags(roleName string) ([]types.Tag, error) {
+	tags := []types.Tag{}
+	paginator := iam.NewListRoleTagsPaginator(client.IamClient, &iam.ListRoleTagsInput{
+		RoleName: &roleName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+	}
+	return tags, nil
 }
 
 func (client *AWSClient) IsPolicyAttachedToRole(roleName string, policyArn string) (bool, error) {
@@ -161,6 +187,45 @@ func (client *AWSClient) DetachRolePolicies(roleName string) error {
 	return nil
 }
 
+// ListInstanceProfilesForRole returns the instance profiles the role is in
+func (client *AWSClient) ListInstanceProfilesForRole(roleName string) ([]types.InstanceProfile, error) {
+	instanceProfiles := []types.InstanceProfile{}
+	paginator := iam.NewListInstanceProfilesForRolePaginator(client.IamClient, &iam.ListInstanceProfilesForRoleInput{
+		RoleName: &roleName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		instanceProfiles = append(instanceProfiles, page.InstanceProfiles...)
+	}
+	return instanceProfiles, nil
+}
+
+// DeleteRoleInlinePolicies deletes the inline policies of the role, a role can't be deleted with them
+func (client *AWSClient) DeleteRoleInlinePolicies(roleName string) error {
+	paginator := iam.NewListRolePoliciesPaginator(client.IamClient, &iam.ListRolePoliciesInput{
+		RoleName: &roleName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return err
+		}
+		for _, policyName := range page.PolicyNames {
+			_, err = client.IamClient.DeleteRolePolicy(context.TODO(), &iam.DeleteRolePolicyInput{
+				RoleName:   &roleName,
+				PolicyName: aws.String(policyName),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (client *AWSClient) DeleteRoleInstanceProfiles(roleName string) error {
 	inProfileLister := iam.ListInstanceProfilesForRoleInput{
 		RoleName: &roleName,
@@ -2,7 +2,10 @@ package aws_client
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/openshift-online/ocm-common/pkg/log"
@@ -54,6 +57,62 @@ func (awsClient AWSClient) ListHostedZoneByDNSName(hostedZoneName string) (*rout
 	return awsClient.Route53Client.ListHostedZonesByName(context.TODO(), input)
 }
 
+// ListHostedZonesByName returns all the hosted zones with the name. A public and private zones, or private zones
+// associated with different VPCs, can have the same name.
+func (awsClient AWSClient) ListHostedZonesByName(hostedZoneName string) ([]types.HostedZone, error) {
+	hostedZones := []types.HostedZone{}
+	input := &route53.ListHostedZonesByNameInput{
+		DNSName: aws.String(hostedZoneName),
+	}
+	for {
+		output, err := awsClient.Route53Client.ListHostedZonesByName(context.TODO(), input)
+		if err != nil {
+			log.LogError("List hosted zones with name %s failed: %s", hostedZoneName, err)
+			return nil, err
+		}
+		// The zones are sorted by name, the ones with the name come first
+		for _, hostedZone := range output.HostedZones {
+			if !sameDNSName(aws.ToString(hostedZone.Name), hostedZoneName) {
+				return hostedZones, nil
+			}
+			hostedZones = append(hostedZones, hostedZone)
+		}
+		if !output.IsTruncated {
+			return hostedZones, nil
+		}
+		input.DNSName = output.NextDNSName
+		input.HostedZoneId = output.NextHostedZoneId
+	}
+}
+
+// ListHostedZones returns all the hosted zones of the account
+func (awsClient AWSClient) ListHostedZones() ([]types.HostedZone, error) {
+	hostedZones := []types.HostedZone{}
+	paginator := route53.NewListHostedZonesPaginator(awsClient.Route53Client, &route53.ListHostedZonesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List hosted zones failed: %s", err)
+			return nil, err
+		}
+		hostedZones = append(hostedZones, page.HostedZones...)
+	}
+	return hostedZones, nil
+}
+
+// GetHostedZoneTags returns the tags of the hosted zone. The ID can be given with or without the
+// '/hostedzone/' prefix
+func (awsClient AWSClient) GetHostedZoneTags(hostedZoneID string) ([]types.Tag, error) {
+	output, err := awsClient.Route53Client.ListTagsForResource(context.TODO(), &route53.ListTagsForResourceInput{
+		ResourceId:   aws.String(strings.TrimPrefix(hostedZoneID, "/hostedzone/")),
+		ResourceType: types.TagResourceTypeHostedzone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.ResourceTagSet.Tags, nil
+}
+
 func (awsClient AWSClient) DeleteHostedZone(hostedZoneID string) error {
 	input := &route53.DeleteHostedZoneInput{
 		Id: &hostedZoneID,
@@ -62,3 +121,107 @@ func (awsClient AWSClient) DeleteHostedZone(hostedZoneID string) error {
 	_, err := awsClient.Route53Client.DeleteHostedZone(context.TODO(), input)
 	return err
 }
+
+// ChangeResourceRecordSet applies the action to the record set of the hosted zone
+func (awsClient AWSClient) ChangeResourceRecordSet(hostedZoneID string, action types.ChangeAction,
+	recordSet *types.ResourceRecordSet) (*route53.ChangeResourceRecordSetsOutput, error) {
+	input := &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: &hostedZoneID,
+		ChangeBatch: &types.ChangeBatch{
+			Changes: []types.Change{
+				{
+					Action:            action,
+					ResourceRecordSet: recordSet,
+				},
+			},
+		},
+	}
+	resp, err := awsClient.Route53Client.ChangeResourceRecordSets(context.TODO(), input)
+	if err != nil {
+		log.LogError("%s record %s of type %s in hosted zone %s failed: %s", action, *recordSet.Name,
+			recordSet.Type, hostedZoneID, err.Error())
+	} else {
+		log.LogInfo("%s record %s of type %s in hosted zone %s succeed", action, *recordSet.Name,
+			recordSet.Type, hostedZoneID)
+	}
+	return resp, err
+}
+
+// UpsertNSRecord creates or updates the NS record delegating the name to the name servers
+func (awsClient AWSClient) UpsertNSRecord(hostedZoneID string, name string, nameServers []string,
+	ttl int64) (*route53.ChangeResourceRecordSetsOutput, error) {
+	records := []types.ResourceRecord{}
+	for _, nameServer := range nameServers {
+		records = append(records, types.ResourceRecord{Value: aws.String(nameServer)})
+	}
+	return awsClient.ChangeResourceRecordSet(hostedZoneID, types.ChangeActionUpsert, &types.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            types.RRTypeNs,
+		TTL:             aws.Int64(ttl),
+		ResourceRecords: records,
+	})
+}
+
+// ListResourceRecordSets returns all the record sets of the hosted zone
+func (awsClient AWSClient) ListResourceRecordSets(hostedZoneID string) ([]types.ResourceRecordSet, error) {
+	recordSets := []types.ResourceRecordSet{}
+	paginator := route53.NewListResourceRecordSetsPaginator(awsClient.Route53Client,
+		&route53.ListResourceRecordSetsInput{HostedZoneId: &hostedZoneID})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		recordSets = append(recordSets, page.ResourceRecordSets...)
+	}
+	return recordSets, nil
+}
+
+// GetResourceRecordSet returns the record set with the name and type from the hosted zone, or nil if not found
+func (awsClient AWSClient) GetResourceRecordSet(hostedZoneID string, name string,
+	recordType types.RRType) (*types.ResourceRecordSet, error) {
+	recordSets, err := awsClient.ListResourceRecordSets(hostedZoneID)
+	if err != nil {
+		return nil, err
+	}
+	for _, recordSet := range recordSets {
+		if recordSet.Type == recordType && sameDNSName(*recordSet.Name, name) {
+			return &recordSet, nil
+		}
+	}
+	return nil, nil
+}
+
+// DeleteHostedZoneRecords deletes all the record sets of the hosted zone except the NS and SOA ones of the zone
+// apex, as a hosted zone can't be deleted while it has other records
+func (awsClient AWSClient) DeleteHostedZoneRecords(hostedZoneID string) error {
+	zone, err := awsClient.GetHostedZone(hostedZoneID)
+	if err != nil {
+		return err
+	}
+	recordSets, err := awsClient.ListResourceRecordSets(hostedZoneID)
+	if err != nil {
+		return err
+	}
+	for _, recordSet := range recordSets {
+		apex := sameDNSName(*recordSet.Name, *zone.HostedZone.Name)
+		if apex && (recordSet.Type == types.RRTypeNs || recordSet.Type == types.RRTypeSoa) {
+			continue
+		}
+		_, err = awsClient.ChangeResourceRecordSet(hostedZoneID, types.ChangeActionDelete, &recordSet)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForRecordChangeInSync waits until the change is propagated to all the Route53 authoritative name servers
+func (awsClient AWSClient) WaitForRecordChangeInSync(changeID string, timeout time.Duration) error {
+	waiter := route53.NewResourceRecordSetsChangedWaiter(awsClient.Route53Client)
+	return waiter.Wait(context.TODO(), &route53.GetChangeInput{Id: &changeID}, timeout)
+}
+
+func sameDNSName(name1 string, name2 string) bool {
+	return strings.EqualFold(strings.TrimSuffix(name1, "."), strings.TrimSuffix(name2, "."))
+}
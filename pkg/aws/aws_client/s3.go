@@ -0,0 +1,57 @@
+package aws_client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	awsErrors "github.com/openshift-online/ocm-common/pkg/aws/errors"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// ListBuckets returns all the buckets of the account, whatever their region
+func (client *AWSClient) ListBuckets() ([]s3types.Bucket, error) {
+	output, err := client.S3Client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+	if err != nil {
+		log.LogError("List buckets failed: %s", err)
+		return nil, err
+	}
+	return output.Buckets, nil
+}
+
+// GetBucketRegion returns the region of the bucket
+func (client *AWSClient) GetBucketRegion(bucketName string) (string, error) {
+	output, err := client.S3Client.GetBucketLocation(context.TODO(), &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		log.LogError("Get location of bucket %s failed: %s", bucketName, err)
+		return "", err
+	}
+	// The buckets of us-east-1 have no location constraint, the ones of eu-west-1 may have the legacy EU one
+	switch output.LocationConstraint {
+	case "":
+		return "us-east-1", nil
+	case s3types.BucketLocationConstraintEu:
+		return "eu-west-1", nil
+	}
+	return string(output.LocationConstraint), nil
+}
+
+// GetBucketTags returns the tags of the bucket, which must be in the region of the client. A bucket without tag set
+// has no tags.
+func (client *AWSClient) GetBucketTags(bucketName string) ([]s3types.Tag, error) {
+	output, err := client.S3Client.GetBucketTagging(context.TODO(), &s3.GetBucketTaggingInput{
+		Bucket: aws.String(bucketName),
+	})
+	if awsErrors.IsErrorCode(err, awsErrors.NoSuchTagSet) {
+		return []s3types.Tag{}, nil
+	}
+	if err != nil {
+		log.LogError("Get tags of bucket %s failed: %s", bucketName, err)
+		return nil, err
+	}
+	return output.TagSet, nil
+}
@@ -37,6 +37,34 @@ func (client *AWSClient) ListSecurityGroups(vpcID string) ([]types.SecurityGroup
 	return customizedSGs, nil
 }
 
+// ListSecurityGroupsByFilters pass parameter like
+// map[string][]string{"vpc-id":[]string{"<id>" }}, map[string][]string{"tag-key":[]string{"<key>" }}
+// Unlike ListSecurityGroups the default security groups are returned too
+func (client *AWSClient) ListSecurityGroupsByFilters(filters ...map[string][]string) ([]types.SecurityGroup, error) {
+	filterInput := []types.Filter{}
+	for _, filter := range filters {
+		for k, v := range filter {
+			filterInput = append(filterInput, types.Filter{
+				Name:   aws.String(k),
+				Values: v,
+			})
+		}
+	}
+	securityGroups := []types.SecurityGroup{}
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(client.Ec2Client, &ec2.DescribeSecurityGroupsInput{
+		Filters: filterInput,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List security groups failed with filters %v: %s", filters, err)
+			return nil, err
+		}
+		securityGroups = append(securityGroups, page.SecurityGroups...)
+	}
+	return securityGroups, nil
+}
+
 func (client *AWSClient) ReleaseInboundOutboundRules(sgID string) error {
 	filterKey := "group-id"
 	filter := []types.Filter{
@@ -0,0 +1,52 @@
+package aws_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// GetCallerIdentity returns the identity of the principal the client is authenticated as
+func (client *AWSClient) GetCallerIdentity() (*sts.GetCallerIdentityOutput, error) {
+	output, err := client.StsClient.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		log.LogError("Get caller identity failed: %s", err)
+		return nil, err
+	}
+	return output, nil
+}
+
+// AssumeRole returns temporary credentials of the role. A zero duration uses the default of one hour.
+func (client *AWSClient) AssumeRole(roleArn string, sessionName string,
+	duration time.Duration) (*types.Credentials, error) {
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleArn),
+		RoleSessionName: aws.String(sessionName),
+	}
+	if duration != 0 {
+		input.DurationSeconds = aws.Int32(int32(duration.Seconds()))
+	}
+	output, err := client.StsClient.AssumeRole(context.TODO(), input)
+	if err != nil {
+		log.LogError("Assume role %s failed: %s", roleArn, err)
+		return nil, err
+	}
+	return output.Credentials, nil
+}
+
+// ConfigWithCredentials returns a copy of the AWS config of the client using the temporary credentials, to
+// build service clients acting as an assumed role
+func (client *AWSClient) ConfigWithCredentials(creds *types.Credentials) aws.Config {
+	cfg := client.AWSConfig.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(
+		aws.ToString(creds.AccessKeyId),
+		aws.ToString(creds.SecretAccessKey),
+		aws.ToString(creds.SessionToken),
+	))
+	return cfg
+}
@@ -3,7 +3,9 @@ package aws_client
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/openshift-online/ocm-common/pkg/log"
 )
 
@@ -18,3 +20,30 @@ func (client *AWSClient) DescribeVolumeByID(volumeID string) (*ec2.DescribeVolum
 	}
 	return output, err
 }
+
+// ListVolumes pass parameter like
+// map[string][]string{"tag-key":[]string{"<key>" }}, map[string][]string{"status":[]string{"available" }}
+func (client *AWSClient) ListVolumes(filters ...map[string][]string) ([]types.Volume, error) {
+	filterInput := []types.Filter{}
+	for _, filter := range filters {
+		for k, v := range filter {
+			filterInput = append(filterInput, types.Filter{
+				Name:   aws.String(k),
+				Values: v,
+			})
+		}
+	}
+	volumes := []types.Volume{}
+	paginator := ec2.NewDescribeVolumesPaginator(client.Ec2Client, &ec2.DescribeVolumesInput{
+		Filters: filterInput,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			log.LogError("List volumes failed with filters %v: %s", filters, err)
+			return nil, err
+		}
+		volumes = append(volumes, page.Volumes...)
+	}
+	return volumes, nil
+}
@@ -2,6 +2,7 @@ package consts
 
 import (
 	"os"
+	"time"
 )
 
 const (
@@ -27,6 +28,11 @@ const (
 	DefaultCIDRPrefix         = 24
 	RouteDestinationCidrBlock = "0.0.0.0/0"
 
+	// DefaultVPCCIDRSupernet and DefaultVPCNetmaskLength are the range the VPC CIDRs are allocated from and
+	// the size of the allocations when an allocator is used instead of DefaultVPCCIDR
+	DefaultVPCCIDRSupernet  = "10.0.0.0/8"
+	DefaultVPCNetmaskLength = 16
+
 	VpcDefaultName = "ocm-ci-vpc"
 
 	CreationPrivateSelector = "private"
@@ -56,6 +62,12 @@ const (
 	AWSInstanceUser       = "ec2-user"
 	BastionName           = "ocm-bastion"
 
+	// HelperExpiryTagKey is the tag holding the RFC3339 time after which a bastion or proxy instance, its key pair
+	// and its EIP can be swept
+	HelperExpiryTagKey = "ocm-ci-expiry"
+	// DefaultHelperInstanceTTL is the time to live of bastion and proxy instances when none is set
+	DefaultHelperInstanceTTL = 24 * time.Hour
+
 	SSHPort = "22"
 
 	// Squid related
@@ -35,6 +35,8 @@ const (
 	InvalidAllocationID          = "InvalidAllocationID.NotFound"
 	InvalidGroup                 = "InvalidGroup.NotFound"
 	InvalidSubnetID              = "InvalidSubnetId.NotFound"
+	InvalidInstanceID            = "InvalidInstanceID.NotFound"
+	NoSuchTagSet                 = "NoSuchTagSet"
 )
 
 func IsErrorCode(err error, code string) bool {
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// policyListElements are the statement elements that accept either a single string or a list of strings
+var policyListElements = []string{"Action", "NotAction", "Resource", "NotResource"}
+
+// PolicyDocumentDiff is the difference between two IAM policy documents at the statement level.
+// Statements are compared in their canonical JSON form, see CanonicalPolicyStatements.
+type PolicyDocumentDiff struct {
+	Added   []string
+	Removed []string
+}
+
+// Empty tells if the two documents grant the same statements
+func (d *PolicyDocumentDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DecodePolicyDocument returns the JSON of a policy document, IAM returns the documents URL encoded
+func DecodePolicyDocument(document string) (string, error) {
+	decoded, err := url.QueryUnescape(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode policy document: %v", err)
+	}
+	return decoded, nil
+}
+
+// CanonicalPolicyStatements returns the statements of the policy document as sorted canonical JSON strings:
+// object keys are sorted and the single string values of the action and resource elements are turned into
+// sorted lists, so that equivalent statements are equal
+func CanonicalPolicyStatements(document string) ([]string, error) {
+	policy := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy document: %v", err)
+	}
+	var statements []interface{}
+	switch statement := policy["Statement"].(type) {
+	case []interface{}:
+		statements = statement
+	case map[string]interface{}:
+		statements = []interface{}{statement}
+	case nil:
+	default:
+		return nil, fmt.Errorf("invalid policy document: 'Statement' must be an object or a list of objects")
+	}
+
+	canonical := []string{}
+	for _, statement := range statements {
+		object, ok := statement.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid policy document: statements must be objects")
+		}
+		for _, element := range policyListElements {
+			if value, ok := object[element]; ok {
+				object[element] = sortedStringList(value)
+			}
+		}
+		encoded, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		canonical = append(canonical, string(encoded))
+	}
+	sort.Strings(canonical)
+	return canonical, nil
+}
+
+// DiffPolicyDocuments returns the statements added and removed going from one policy document to the other
+func DiffPolicyDocuments(from string, to string) (*PolicyDocumentDiff, error) {
+	fromStatements, err := CanonicalPolicyStatements(from)
+	if err != nil {
+		return nil, err
+	}
+	toStatements, err := CanonicalPolicyStatements(to)
+	if err != nil {
+		return nil, err
+	}
+	diff := &PolicyDocumentDiff{
+		Added:   []string{},
+		Removed: []string{},
+	}
+	remaining := map[string]int{}
+	for _, statement := range fromStatements {
+		remaining[statement]++
+	}
+	for _, statement := range toStatements {
+		if remaining[statement] > 0 {
+			remaining[statement]--
+			continue
+		}
+		diff.Added = append(diff.Added, statement)
+	}
+	for _, statement := range fromStatements {
+		if remaining[statement] > 0 {
+			remaining[statement]--
+			diff.Removed = append(diff.Removed, statement)
+		}
+	}
+	return diff, nil
+}
+
+func sortedStringList(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}
+	case []interface{}:
+		values := []string{}
+		for _, item := range typed {
+			text, ok := item.(string)
+			if !ok {
+				return value
+			}
+			values = append(values, text)
+		}
+		sort.Strings(values)
+		return values
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/openshift-online/ocm-common/pkg/aws/utils"
+)
+
+var _ = Describe("Policy documents", func() {
+	It("decodes URL encoded documents", func() {
+		document, err := DecodePolicyDocument("%7B%22Version%22%3A%222012-10-17%22%7D")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(document).To(Equal(`{"Version":"2012-10-17"}`))
+	})
+
+	It("canonicalizes equivalent statements", func() {
+		single, err := CanonicalPolicyStatements(`{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}}`)
+		Expect(err).ToNot(HaveOccurred())
+		list, err := CanonicalPolicyStatements(`{"Statement":[{"Resource":["*"],"Action":["s3:GetObject"],"Effect":"Allow"}]}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(single).To(Equal(list))
+	})
+
+	It("fails on invalid documents", func() {
+		_, err := CanonicalPolicyStatements(`not json`)
+		Expect(err).To(HaveOccurred())
+		_, err = CanonicalPolicyStatements(`{"Statement":"Allow"}`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("diffs the statements of two documents", func() {
+		from := `{"Version":"2012-10-17","Statement":[
+			{"Effect":"Allow","Action":["ec2:DescribeInstances","ec2:DescribeVpcs"],"Resource":"*"},
+			{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket/*"}]}`
+		to := `{"Version":"2012-10-17","Statement":[
+			{"Effect":"Allow","Action":["ec2:DescribeVpcs","ec2:DescribeInstances"],"Resource":"*"},
+			{"Effect":"Allow","Action":"s3:PutObject","Resource":"arn:aws:s3:::bucket/*"}]}`
+		diff, err := DiffPolicyDocuments(from, to)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Empty()).To(BeFalse())
+		Expect(diff.Added).To(Equal([]string{
+			`{"Action":["s3:PutObject"],"Effect":"Allow","Resource":["arn:aws:s3:::bucket/*"]}`}))
+		Expect(diff.Removed).To(Equal([]string{
+			`{"Action":["s3:GetObject"],"Effect":"Allow","Resource":["arn:aws:s3:::bucket/*"]}`}))
+
+		diff, err = DiffPolicyDocuments(from, from)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.Empty()).To(BeTrue())
+	})
+})
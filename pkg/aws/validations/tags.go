@@ -1,5 +1,14 @@
 package validations
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
 const prefix = "rosa_"
 
 const ManagedPolicies = prefix + "managed_policies"
@@ -7,3 +16,131 @@ const ManagedPolicies = prefix + "managed_policies"
 // OpenShiftVersion is the name of the tag that will contain
 // the version of OpenShift that the resources are used for
 const OpenShiftVersion = prefix + "openshift_version"
+
+// ClusterIDTag is the name of the tag that will contain the ID of the cluster the resources are created for
+const ClusterIDTag = prefix + "cluster_id"
+
+// RedHatManaged is the name of the tag set to "true" on the IAM resources created by ROSA
+const RedHatManaged = "red-hat-managed"
+
+// OperatorNamespace and OperatorName are the names of the tags identifying the operator of an operator role
+const (
+	OperatorNamespace = "operator_namespace"
+	OperatorName      = "operator_name"
+)
+
+const (
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+	// MaxResourceTags is the maximum number of user-created tags of an AWS resource, see "Tag naming limits and
+	// requirements" in the AWS Tagging Resources user guide. The tags with the aws: prefix don't count.
+	MaxResourceTags = 50
+	// OCMAppliedTagsCount is the share of MaxResourceTags reserved to the tags OCM, the installer and the cluster
+	// operators apply to the AWS resources of a cluster, like kubernetes.io/cluster/<infra-id>, red-hat-managed,
+	// red-hat-clustertype, api.openshift.com/id, api.openshift.com/name, api.openshift.com/environment and Name.
+	// Half of the limit is reserved, leaving room for the tags later releases add: the user tags of an existing
+	// cluster can't be dropped to make room for them.
+	OCMAppliedTagsCount = MaxResourceTags / 2
+)
+
+// ReservedTagPrefixes are the prefixes of tag keys that users can't set, they are matched case-insensitively
+var ReservedTagPrefixes = []string{"aws:", "red-hat-", "kubernetes.io/", prefix}
+
+var tagCharactersRE = regexp.MustCompile(`^[\pL\pZ\pN_.:/=+\-@]*$`)
+
+// ValidateTagKey checks that the key of a user tag has between 1 and MaxTagKeyLength characters allowed by AWS
+// and doesn't start with one of ReservedTagPrefixes
+func ValidateTagKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("Tag key can't be empty")
+	}
+	if length := utf8.RuneCountInString(key); length > MaxTagKeyLength {
+		return fmt.Errorf("Tag key '%s' is %d characters long, the maximum is %d", key, length, MaxTagKeyLength)
+	}
+	if !tagCharactersRE.MatchString(key) {
+		return fmt.Errorf("Tag key '%s' can only contain letters, numbers, spaces and the characters '_.:/=+-@'",
+			key)
+	}
+	lowerKey := strings.ToLower(key)
+	for _, reservedPrefix := range ReservedTagPrefixes {
+		if strings.HasPrefix(lowerKey, reservedPrefix) {
+			return fmt.Errorf("Tag key '%s' can't start with the reserved prefix '%s'", key, reservedPrefix)
+		}
+	}
+	return nil
+}
+
+// ValidateTagValue checks that the value of a user tag has at most MaxTagValueLength characters allowed by AWS
+func ValidateTagValue(key string, value string) error {
+	if length := utf8.RuneCountInString(value); length > MaxTagValueLength {
+		return fmt.Errorf("Value of tag '%s' is %d characters long, the maximum is %d", key, length,
+			MaxTagValueLength)
+	}
+	if !tagCharactersRE.MatchString(value) {
+		return fmt.Errorf("Value of tag '%s' can only contain letters, numbers, spaces and the characters "+
+			"'_.:/=+-@'", key)
+	}
+	return nil
+}
+
+// ValidateClusterTags validates the user tags of a cluster:
+//
+// * Keys and values must be valid, see ValidateTagKey and ValidateTagValue.
+// * Keys must be unique case-insensitively, as some AWS services don't distinguish them.
+// * Together with the OCMAppliedTagsCount tags applied by OCM there can't be more than MaxResourceTags tags.
+//
+// All the issues are returned at once.
+func ValidateClusterTags(tags map[string]string) error {
+	return validateTags(tags, OCMAppliedTagsCount)
+}
+
+// ValidateMachinePoolTags validates the user tags of a machine pool like ValidateClusterTags. The resources of
+// the machine pool also get the tags of the cluster, so the keys can't duplicate the cluster ones and they count
+// towards MaxResourceTags.
+func ValidateMachinePoolTags(clusterTags map[string]string, machinePoolTags map[string]string) error {
+	var errs []error
+	clusterKeys := map[string]string{}
+	for key := range clusterTags {
+		clusterKeys[strings.ToLower(key)] = key
+	}
+	for _, key := range sortedKeys(machinePoolTags) {
+		if clusterKey, ok := clusterKeys[strings.ToLower(key)]; ok {
+			errs = append(errs, fmt.Errorf("Tag key '%s' duplicates the cluster tag '%s'", key, clusterKey))
+		}
+	}
+	errs = append(errs, validateTags(machinePoolTags, OCMAppliedTagsCount+len(clusterTags)))
+	return errors.Join(errs...)
+}
+
+func validateTags(tags map[string]string, appliedTagsCount int) error {
+	var errs []error
+	seen := map[string]string{}
+	for _, key := range sortedKeys(tags) {
+		if err := ValidateTagKey(key); err != nil {
+			errs = append(errs, err)
+		}
+		if err := ValidateTagValue(key, tags[key]); err != nil {
+			errs = append(errs, err)
+		}
+		lowerKey := strings.ToLower(key)
+		if previous, ok := seen[lowerKey]; ok {
+			errs = append(errs, fmt.Errorf("Tag keys '%s' and '%s' are duplicated, keys are case-insensitive",
+				previous, key))
+		}
+		seen[lowerKey] = key
+	}
+	if total := appliedTagsCount + len(tags); total > MaxResourceTags {
+		errs = append(errs, fmt.Errorf("At most %d user tags are allowed, got %d", MaxResourceTags-appliedTagsCount,
+			len(tags)))
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
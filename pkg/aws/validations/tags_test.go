@@ -0,0 +1,68 @@
+package validations
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("User tags validation", func() {
+	DescribeTable("tag key",
+		func(key string, valid bool) {
+			err := ValidateTagKey(key)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("simple key", "team", true),
+		Entry("key with allowed symbols", "cost-center/team_a:b=c+d@e.f", true),
+		Entry("unicode key", "équipe", true),
+		Entry("empty key", "", false),
+		Entry("too long key", strings.Repeat("k", MaxTagKeyLength+1), false),
+		Entry("invalid character", "team#1", false),
+		Entry("aws prefix", "AWS:createdBy", false),
+		Entry("red hat prefix", "red-hat-managed", false),
+		Entry("kubernetes prefix", "kubernetes.io/cluster/abc", false),
+		Entry("rosa prefix", "rosa_role", false),
+	)
+
+	It("validates tag values", func() {
+		Expect(ValidateTagValue("team", "")).To(Succeed())
+		Expect(ValidateTagValue("team", strings.Repeat("v", MaxTagValueLength+1))).ToNot(Succeed())
+		Expect(ValidateTagValue("team", "a*b")).ToNot(Succeed())
+	})
+
+	It("detects case-insensitive duplicates", func() {
+		err := ValidateClusterTags(map[string]string{"Team": "a", "team": "b"})
+		Expect(err).To(MatchError("Tag keys 'Team' and 'team' are duplicated, keys are case-insensitive"))
+	})
+
+	It("limits the number of tags after adding the OCM ones", func() {
+		tags := map[string]string{}
+		for i := 0; i < MaxResourceTags-OCMAppliedTagsCount; i++ {
+			tags[fmt.Sprintf("tag%d", i)] = "value"
+		}
+		Expect(ValidateClusterTags(tags)).To(Succeed())
+		tags["one-more"] = "value"
+		Expect(ValidateClusterTags(tags)).To(MatchError(fmt.Sprintf("At most %d user tags are allowed, got %d",
+			MaxResourceTags-OCMAppliedTagsCount, len(tags))))
+	})
+
+	It("counts and compares the cluster tags for machine pools", func() {
+		clusterTags := map[string]string{}
+		for i := 0; i < 20; i++ {
+			clusterTags[fmt.Sprintf("tag%d", i)] = "value"
+		}
+		Expect(ValidateMachinePoolTags(clusterTags, map[string]string{"pool": "a"})).To(Succeed())
+
+		err := ValidateMachinePoolTags(clusterTags, map[string]string{"TAG1": "b", "a": "", "b": "", "c": "",
+			"d": "", "e": ""})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Tag key 'TAG1' duplicates the cluster tag 'tag1'"))
+		Expect(err.Error()).To(ContainSubstring("At most 5 user tags are allowed, got 6"))
+	})
+})
@@ -0,0 +1,182 @@
+package validations
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift-online/ocm-common/pkg/utils"
+)
+
+const (
+	// MaxRegistryListEntries is the maximum number of entries of each registry list of the registry config
+	MaxRegistryListEntries = 100
+	// MaxAdditionalTrustedCAs is the maximum number of registries with an additional trusted CA
+	MaxAdditionalTrustedCAs = 50
+
+	registryWildcardPrefix = "*."
+	// registryCAPortSeparator replaces ':' between host and port in the keys of the additional trusted CAs,
+	// as ':' isn't allowed in the keys of the config map OpenShift stores them into
+	registryCAPortSeparator = ".."
+)
+
+var registryPathRE = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*$`)
+
+// ValidateRegistryConfig validates the image registry config of a cluster:
+//
+// * Allowed and blocked registries are mutually exclusive and insecure registries can't be blocked.
+// * Registries must be 'host[:port][/path]', where host can start with a '*.' wildcard when there is no path.
+// * Additional trusted CAs are keyed by 'host', 'host:port' or 'host..port' and must be PEM encoded certificates.
+// * The platform allowlist can only be used together with allowed registries.
+// * Lists can't have duplicates nor more than MaxRegistryListEntries entries, and there can't be more than
+// MaxAdditionalTrustedCAs additional trusted CAs.
+//
+// All the issues are returned at once.
+func ValidateRegistryConfig(registryConfig *cmv1.ClusterRegistryConfig) error {
+	var errs []error
+	if registryConfig == nil {
+		return nil
+	}
+
+	sources := registryConfig.RegistrySources()
+	allowed := sources.AllowedRegistries()
+	blocked := sources.BlockedRegistries()
+	insecure := sources.InsecureRegistries()
+	if len(allowed) > 0 && len(blocked) > 0 {
+		errs = append(errs, fmt.Errorf("Allowed registries and blocked registries are mutually exclusive"))
+	}
+	errs = append(errs,
+		validateRegistryList("allowed registries", allowed),
+		validateRegistryList("blocked registries", blocked),
+		validateRegistryList("insecure registries", insecure),
+	)
+	blockedSet := map[string]bool{}
+	for _, registry := range blocked {
+		blockedSet[registry] = true
+	}
+	for _, registry := range insecure {
+		if blockedSet[registry] {
+			errs = append(errs, fmt.Errorf("Registry '%s' can't be both insecure and blocked", registry))
+		}
+	}
+
+	locations := registryConfig.AllowedRegistriesForImport()
+	if len(locations) > MaxRegistryListEntries {
+		errs = append(errs, fmt.Errorf("At most %d allowed registries for import are allowed, got %d",
+			MaxRegistryListEntries, len(locations)))
+	}
+	seenLocations := map[string]bool{}
+	for _, location := range locations {
+		domainName := location.DomainName()
+		if err := ValidateRegistryHost(domainName); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid allowed registry for import: %v", err))
+		}
+		if seenLocations[domainName] {
+			errs = append(errs, fmt.Errorf("Allowed registry for import '%s' is duplicated", domainName))
+		}
+		seenLocations[domainName] = true
+	}
+
+	errs = append(errs, ValidateAdditionalTrustedCAs(registryConfig.AdditionalTrustedCa()))
+
+	if allowlistID, ok := registryConfig.PlatformAllowlist().GetID(); ok {
+		if allowlistID == "" {
+			errs = append(errs, fmt.Errorf("Platform allowlist ID can't be empty"))
+		}
+		if len(allowed) == 0 {
+			errs = append(errs, fmt.Errorf("Platform allowlist can only be used together with allowed registries"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAdditionalTrustedCAs checks the keys of the additional trusted CAs are registry hosts and the values
+// PEM encoded certificates
+func ValidateAdditionalTrustedCAs(additionalTrustedCAs map[string]string) error {
+	var errs []error
+	if len(additionalTrustedCAs) > MaxAdditionalTrustedCAs {
+		errs = append(errs, fmt.Errorf("At most %d additional trusted CAs are allowed, got %d",
+			MaxAdditionalTrustedCAs, len(additionalTrustedCAs)))
+	}
+	for registry, ca := range additionalTrustedCAs {
+		host := strings.Replace(registry, registryCAPortSeparator, ":", 1)
+		if err := ValidateRegistryHost(host); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid additional trusted CA registry: %v", err))
+		}
+		if _, err := utils.ParsePEMCertificates(ca); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid additional trusted CA for registry '%s': %v", registry, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateRegistry checks that the registry is 'host[:port][/path]', the host being optionally prefixed
+// by a '*.' wildcard when there is no path
+func ValidateRegistry(registry string) error {
+	host, path, hasPath := strings.Cut(registry, "/")
+	if hasPath {
+		if strings.HasPrefix(host, registryWildcardPrefix) {
+			return fmt.Errorf("Registry '%s' can't have both a wildcard and a path", registry)
+		}
+		if !registryPathRE.MatchString(path) {
+			return fmt.Errorf("Registry '%s' has an invalid repository path '%s'", registry, path)
+		}
+	}
+	return validateRegistryHost(registry, strings.TrimPrefix(host, registryWildcardPrefix))
+}
+
+// ValidateRegistryHost checks that the registry is 'host[:port]', where host is a domain name or an IP address
+func ValidateRegistryHost(registry string) error {
+	return validateRegistryHost(registry, registry)
+}
+
+func validateRegistryHost(registry string, hostPort string) error {
+	host := hostPort
+	if strings.Contains(hostPort, ":") && net.ParseIP(hostPort) == nil {
+		var port string
+		var err error
+		host, port, err = net.SplitHostPort(hostPort)
+		if err != nil {
+			return fmt.Errorf("Registry '%s' is invalid: %v", registry, err)
+		}
+		portNumber, err := strconv.Atoi(port)
+		if err != nil || portNumber < 1 || portNumber > 65535 {
+			return fmt.Errorf("Registry '%s' has an invalid port '%s'", registry, port)
+		}
+	}
+	if host == "" {
+		return fmt.Errorf("Registry '%s' has no host", registry)
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Subdomain(strings.ToLower(host)); len(msgs) > 0 {
+		return fmt.Errorf("Registry '%s' has an invalid host: %s", registry, msgs[0])
+	}
+	return nil
+}
+
+func validateRegistryList(name string, registries []string) error {
+	var errs []error
+	if len(registries) > MaxRegistryListEntries {
+		errs = append(errs, fmt.Errorf("At most %d %s are allowed, got %d", MaxRegistryListEntries, name,
+			len(registries)))
+	}
+	seen := map[string]bool{}
+	for _, registry := range registries {
+		if err := ValidateRegistry(registry); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid %s: %v", name, err))
+		}
+		if seen[registry] {
+			errs = append(errs, fmt.Errorf("Registry '%s' is duplicated in %s", registry, name))
+		}
+		seen[registry] = true
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package validations
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func generateCertificate() string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "registry.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func buildRegistryConfig(builder *cmv1.ClusterRegistryConfigBuilder) *cmv1.ClusterRegistryConfig {
+	registryConfig, err := builder.Build()
+	Expect(err).ToNot(HaveOccurred())
+	return registryConfig
+}
+
+var _ = Describe("Registry config validations", func() {
+	DescribeTable("registry syntax",
+		func(registry string, valid bool) {
+			err := ValidateRegistry(registry)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("host", "quay.io", true),
+		Entry("host and port", "registry.example.com:5000", true),
+		Entry("host and path", "quay.io/openshift/origin", true),
+		Entry("wildcard", "*.example.com", true),
+		Entry("IPv4 and port", "10.0.0.1:5000", true),
+		Entry("IPv6 and port", "[fd00::1]:5000", true),
+		Entry("wildcard and path", "*.example.com/openshift", false),
+		Entry("wildcard in the middle", "registry.*.com", false),
+		Entry("invalid port", "quay.io:99999", false),
+		Entry("invalid path", "quay.io/Open Shift", false),
+		Entry("empty", "", false),
+	)
+
+	It("accepts a valid config", func() {
+		registryConfig := buildRegistryConfig(cmv1.NewClusterRegistryConfig().
+			RegistrySources(cmv1.NewRegistrySources().
+				AllowedRegistries("quay.io", "*.example.com").
+				InsecureRegistries("registry.example.com:5000")).
+			AllowedRegistriesForImport(cmv1.NewRegistryLocation().DomainName("quay.io")).
+			AdditionalTrustedCa(map[string]string{"registry.example.com..5000": generateCertificate()}).
+			PlatformAllowlist(cmv1.NewRegistryAllowlist().ID("default")))
+		Expect(ValidateRegistryConfig(registryConfig)).To(Succeed())
+	})
+
+	It("reports every issue of the config", func() {
+		registryConfig := buildRegistryConfig(cmv1.NewClusterRegistryConfig().
+			RegistrySources(cmv1.NewRegistrySources().
+				AllowedRegistries("quay.io", "quay.io").
+				BlockedRegistries("docker.io").
+				InsecureRegistries("docker.io")).
+			AdditionalTrustedCa(map[string]string{"registry.example.com": "not a certificate"}))
+		err := ValidateRegistryConfig(registryConfig)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("mutually exclusive"))
+		Expect(err.Error()).To(ContainSubstring("Registry 'quay.io' is duplicated in allowed registries"))
+		Expect(err.Error()).To(ContainSubstring("Registry 'docker.io' can't be both insecure and blocked"))
+		Expect(err.Error()).To(ContainSubstring("Invalid additional trusted CA for registry 'registry.example.com'"))
+	})
+
+	It("requires allowed registries for the platform allowlist", func() {
+		registryConfig := buildRegistryConfig(cmv1.NewClusterRegistryConfig().
+			PlatformAllowlist(cmv1.NewRegistryAllowlist().ID("default")))
+		Expect(ValidateRegistryConfig(registryConfig)).To(MatchError(
+			"Platform allowlist can only be used together with allowed registries"))
+	})
+
+	It("limits the size of the lists", func() {
+		registries := make([]string, MaxRegistryListEntries+1)
+		for i := range registries {
+			registries[i] = fmt.Sprintf("registry%d.example.com", i)
+		}
+		registryConfig := buildRegistryConfig(cmv1.NewClusterRegistryConfig().
+			RegistrySources(cmv1.NewRegistrySources().BlockedRegistries(registries...)))
+		Expect(ValidateRegistryConfig(registryConfig)).To(MatchError(
+			fmt.Sprintf("At most %d blocked registries are allowed, got %d", MaxRegistryListEntries,
+				MaxRegistryListEntries+1)))
+	})
+})
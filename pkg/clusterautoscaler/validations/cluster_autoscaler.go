@@ -0,0 +1,153 @@
+package validations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/duration_parser"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/quantity_parser"
+)
+
+var (
+	utilizationThresholdParser = quantity_parser.NewQuantityParser(
+		quantity_parser.WithMinimum("0"),
+		quantity_parser.WithMaximum("1"),
+	)
+
+	resourceLimitParser = quantity_parser.NewQuantityParser(
+		quantity_parser.WithMinimum("0"),
+		quantity_parser.WithWholeNumbers(),
+	)
+
+	gibibyte = resource.MustParse("1Gi")
+)
+
+// ValidateAutoscalerDuration checks that the value of the autoscaler setting is a non-negative Go duration, like
+// `10m` or `1h30m`, the `d` unit isn't accepted. Empty values are accepted as the setting is then left to its
+// default.
+func ValidateAutoscalerDuration(setting string, value string) error {
+	if value == "" {
+		return nil
+	}
+	// the duration parsers keep their parsing state, they can't be shared
+	if _, err := duration_parser.NewDurationParser(duration_parser.WithNonNegative(),
+		duration_parser.WithoutDays()).Parse(value); err != nil {
+		return fmt.Errorf("Invalid value for autoscaler '%s': %v", setting, err)
+	}
+	return nil
+}
+
+// ValidateUtilizationThreshold checks that the scale down utilization threshold is a number between 0 and 1
+func ValidateUtilizationThreshold(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := utilizationThresholdParser.Parse(value); err != nil {
+		return fmt.Errorf("Invalid value for autoscaler 'utilization-threshold': %v", err)
+	}
+	return nil
+}
+
+// ParseAutoscalerCores parses a cores limit given as a resource quantity, like `8` or `8000m`
+func ParseAutoscalerCores(value string) (int, error) {
+	quantity, err := resourceLimitParser.Parse(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid cores limit: %v", err)
+	}
+	return int(quantity.Value()), nil
+}
+
+// ParseAutoscalerMemory parses a memory limit, expressed in GiB by the autoscaler. The limit is given as a resource
+// quantity, like `64Gi`, or as a number of GiB.
+func ParseAutoscalerMemory(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if gib, err := strconv.Atoi(value); err == nil {
+		value = fmt.Sprintf("%dGi", gib)
+	}
+	quantity, err := resourceLimitParser.Parse(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid memory limit: %v", err)
+	}
+	if quantity.Value()%gibibyte.Value() != 0 {
+		return 0, fmt.Errorf("Invalid memory limit: '%s' must be a whole number of GiB", value)
+	}
+	return int(quantity.Value() / gibibyte.Value()), nil
+}
+
+// ValidateResourceRange checks that the bounds of the resource limit are non-negative and ordered
+func ValidateResourceRange(resourceName string, min int, max int) error {
+	if min < 0 || max < 0 {
+		return fmt.Errorf("Invalid %s limit: the bounds must be non-negative, got %d-%d", resourceName, min, max)
+	}
+	if min > max {
+		return fmt.Errorf("Invalid %s limit: the minimum %d is greater than the maximum %d", resourceName, min, max)
+	}
+	return nil
+}
+
+// ValidateClusterAutoscaler checks the durations, the utilization threshold and the resource limits of the
+// autoscaler
+func ValidateClusterAutoscaler(autoscaler *cmv1.ClusterAutoscaler) error {
+	if err := ValidateAutoscalerDuration("max-node-provision-time", autoscaler.MaxNodeProvisionTime()); err != nil {
+		return err
+	}
+	if autoscaler.MaxPodGracePeriod() < 0 {
+		return fmt.Errorf("Invalid value for autoscaler 'max-pod-grace-period': must be non-negative, got %d",
+			autoscaler.MaxPodGracePeriod())
+	}
+	if autoscaler.LogVerbosity() < 0 {
+		return fmt.Errorf("Invalid value for autoscaler 'log-verbosity': must be non-negative, got %d",
+			autoscaler.LogVerbosity())
+	}
+
+	if scaleDown, ok := autoscaler.GetScaleDown(); ok {
+		durations := []struct {
+			setting string
+			value   string
+		}{
+			{"delay-after-add", scaleDown.DelayAfterAdd()},
+			{"delay-after-delete", scaleDown.DelayAfterDelete()},
+			{"delay-after-failure", scaleDown.DelayAfterFailure()},
+			{"unneeded-time", scaleDown.UnneededTime()},
+		}
+		for _, duration := range durations {
+			if err := ValidateAutoscalerDuration(duration.setting, duration.value); err != nil {
+				return err
+			}
+		}
+		if err := ValidateUtilizationThreshold(scaleDown.UtilizationThreshold()); err != nil {
+			return err
+		}
+	}
+
+	if limits, ok := autoscaler.GetResourceLimits(); ok {
+		if limits.MaxNodesTotal() < 0 {
+			return fmt.Errorf("Invalid value for autoscaler 'max-nodes-total': must be non-negative, got %d",
+				limits.MaxNodesTotal())
+		}
+		if cores, ok := limits.GetCores(); ok {
+			if err := ValidateResourceRange("cores", cores.Min(), cores.Max()); err != nil {
+				return err
+			}
+		}
+		if memory, ok := limits.GetMemory(); ok {
+			if err := ValidateResourceRange("memory", memory.Min(), memory.Max()); err != nil {
+				return err
+			}
+		}
+		for _, gpu := range limits.GPUS() {
+			if gpu.Type() == "" {
+				return fmt.Errorf("Invalid GPU limit: the GPU type is required")
+			}
+			if err := ValidateResourceRange(fmt.Sprintf("GPU '%s'", gpu.Type()), gpu.Range().Min(),
+				gpu.Range().Max()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package validations
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Cluster autoscaler validations", func() {
+	buildAutoscaler := func(scaleDown *cmv1.AutoscalerScaleDownConfigBuilder,
+		limits *cmv1.AutoscalerResourceLimitsBuilder) *cmv1.ClusterAutoscaler {
+		autoscaler, err := cmv1.NewClusterAutoscaler().
+			MaxNodeProvisionTime("15m").
+			ScaleDown(scaleDown).
+			ResourceLimits(limits).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		return autoscaler
+	}
+
+	It("accepts a valid autoscaler", func() {
+		autoscaler := buildAutoscaler(
+			cmv1.NewAutoscalerScaleDownConfig().DelayAfterAdd("10m").UnneededTime("1h30m").
+				UtilizationThreshold("0.5"),
+			cmv1.NewAutoscalerResourceLimits().MaxNodesTotal(10).
+				Cores(cmv1.NewResourceRange().Min(0).Max(100)).
+				GPUS(cmv1.NewAutoscalerResourceLimitsGPULimit().Type("nvidia.com/gpu").
+					Range(cmv1.NewResourceRange().Min(0).Max(4))),
+		)
+		Expect(ValidateClusterAutoscaler(autoscaler)).To(Succeed())
+	})
+
+	DescribeTable("rejects an invalid autoscaler",
+		func(scaleDown *cmv1.AutoscalerScaleDownConfigBuilder, limits *cmv1.AutoscalerResourceLimitsBuilder,
+			errMessage string) {
+			Expect(ValidateClusterAutoscaler(buildAutoscaler(scaleDown, limits))).
+				To(MatchError(ContainSubstring(errMessage)))
+		},
+		Entry("invalid duration", cmv1.NewAutoscalerScaleDownConfig().DelayAfterDelete("10 minutes"),
+			cmv1.NewAutoscalerResourceLimits(), "Invalid value for autoscaler 'delay-after-delete'"),
+		Entry("invalid duration `1d`", cmv1.NewAutoscalerScaleDownConfig().UnneededTime("1d"),
+			cmv1.NewAutoscalerResourceLimits(), "unknown unit `d`"),
+		Entry("negative duration", cmv1.NewAutoscalerScaleDownConfig().UnneededTime("-5m"),
+			cmv1.NewAutoscalerResourceLimits(), "duration '-5m' must be at least 0s"),
+		Entry("utilization threshold out of range", cmv1.NewAutoscalerScaleDownConfig().UtilizationThreshold("1.5"),
+			cmv1.NewAutoscalerResourceLimits(), "quantity '1.5' must be at most 1"),
+		Entry("inverted memory range", cmv1.NewAutoscalerScaleDownConfig(),
+			cmv1.NewAutoscalerResourceLimits().Memory(cmv1.NewResourceRange().Min(10).Max(5)),
+			"Invalid memory limit: the minimum 10 is greater than the maximum 5"),
+		Entry("GPU without type", cmv1.NewAutoscalerScaleDownConfig(),
+			cmv1.NewAutoscalerResourceLimits().GPUS(cmv1.NewAutoscalerResourceLimitsGPULimit().
+				Range(cmv1.NewResourceRange().Max(1))), "the GPU type is required"),
+	)
+
+	DescribeTable("parses the resource limits", func(parse func(string) (int, error), value string, expected int) {
+		parsed, err := parse(value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(expected))
+	},
+		Entry("cores", ParseAutoscalerCores, "8", 8),
+		Entry("cores in millicores", ParseAutoscalerCores, "8000m", 8),
+		Entry("memory in GiB", ParseAutoscalerMemory, "64", 64),
+		Entry("memory quantity", ParseAutoscalerMemory, "2Ti", 2048),
+	)
+
+	DescribeTable("rejects an invalid resource limit", func(parse func(string) (int, error), value string,
+		errMessage string) {
+		_, err := parse(value)
+		Expect(err).To(MatchError(ContainSubstring(errMessage)))
+	},
+		Entry("fractional cores", ParseAutoscalerCores, "2.5", "must be a whole number"),
+		Entry("negative cores", ParseAutoscalerCores, "-1", "must be at least 0"),
+		Entry("memory not in GiB", ParseAutoscalerMemory, "512Mi", "must be a whole number of GiB"),
+		Entry("invalid memory", ParseAutoscalerMemory, "lots", "Invalid memory limit"),
+	)
+})
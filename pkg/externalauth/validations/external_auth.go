@@ -0,0 +1,254 @@
+package validations
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift-online/ocm-common/pkg/utils"
+)
+
+const (
+	// MaxAudiences is the maximum number of audiences accepted by a token issuer
+	MaxAudiences = 10
+
+	UsernamePrefixPolicyNone     = ""
+	UsernamePrefixPolicyNoPrefix = "NoPrefix"
+	UsernamePrefixPolicyPrefix   = "Prefix"
+
+	discoveryPath = "/.well-known/openid-configuration"
+)
+
+// ValidUsernamePrefixPolicies are the accepted values of the username claim prefix policy
+var ValidUsernamePrefixPolicies = []string{
+	UsernamePrefixPolicyNone,
+	UsernamePrefixPolicyNoPrefix,
+	UsernamePrefixPolicyPrefix,
+}
+
+// ValidateExternalAuth validates an external authentication provider of a hosted cluster:
+//
+// * The name must be a valid DNS label.
+// * The issuer URL must be an HTTPS URL, the audiences must be valid and the CA, if any, must be PEM encoded.
+// * The username and groups claim mappings and the claim validation rules must be consistent.
+// * The clients must have an ID and a component.
+//
+// The discovery document of the issuer is not checked, see CheckIssuerDiscovery.
+// All the issues are returned at once.
+func ValidateExternalAuth(externalAuth *cmv1.ExternalAuth) error {
+	var errs []error
+	if msgs := validation.IsDNS1123Label(externalAuth.ID()); len(msgs) > 0 {
+		errs = append(errs, fmt.Errorf("Invalid external auth provider name '%s': %s", externalAuth.ID(), msgs[0]))
+	}
+	issuer := externalAuth.Issuer()
+	errs = append(errs,
+		ValidateIssuerURL(issuer.URL()),
+		ValidateAudiences(issuer.Audiences()),
+		ValidateIssuerCA(issuer.CA()),
+		ValidateUsernameClaim(externalAuth.Claim().Mappings().UserName()),
+		ValidateGroupsClaim(externalAuth.Claim().Mappings().Groups()),
+		ValidateClaimValidationRules(externalAuth.Claim().ValidationRules()),
+		ValidateClients(externalAuth.Clients()),
+	)
+	return errors.Join(errs...)
+}
+
+// ValidateIssuerURL checks that the issuer URL is an absolute HTTPS URL without query, fragment or user info
+func ValidateIssuerURL(issuerURL string) error {
+	if issuerURL == "" {
+		return fmt.Errorf("Issuer URL is required")
+	}
+	parsed, err := url.Parse(issuerURL)
+	if err != nil {
+		return fmt.Errorf("Invalid issuer URL '%s': %v", issuerURL, err)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("Invalid issuer URL '%s': must use the https scheme", issuerURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("Invalid issuer URL '%s': must have a host", issuerURL)
+	}
+	if parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("Invalid issuer URL '%s': must not contain user info, query or fragment", issuerURL)
+	}
+	return nil
+}
+
+// ValidateAudiences checks that there is at least one audience and at most MaxAudiences, without
+// empty or duplicated values
+func ValidateAudiences(audiences []string) error {
+	if len(audiences) == 0 {
+		return fmt.Errorf("At least one audience is required")
+	}
+	if len(audiences) > MaxAudiences {
+		return fmt.Errorf("At most %d audiences are allowed, got %d", MaxAudiences, len(audiences))
+	}
+	var errs []error
+	seen := map[string]bool{}
+	for _, audience := range audiences {
+		if strings.TrimSpace(audience) == "" {
+			errs = append(errs, fmt.Errorf("Audiences can't be empty"))
+			continue
+		}
+		if seen[audience] {
+			errs = append(errs, fmt.Errorf("Audience '%s' is duplicated", audience))
+		}
+		seen[audience] = true
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateIssuerCA checks that the CA of the issuer, when provided, contains only PEM encoded certificates
+func ValidateIssuerCA(ca string) error {
+	if ca == "" {
+		return nil
+	}
+	if _, err := utils.ParsePEMCertificates(ca); err != nil {
+		return fmt.Errorf("Invalid CA: %v", err)
+	}
+	return nil
+}
+
+// ValidateUsernameClaim checks the username claim mapping:
+//
+// * The claim is required.
+// * The prefix policy must be one of ValidUsernamePrefixPolicies.
+// * The prefix is required by the 'Prefix' policy and is not allowed by the other ones.
+func ValidateUsernameClaim(usernameClaim *cmv1.UsernameClaim) error {
+	if usernameClaim.Claim() == "" {
+		return fmt.Errorf("Username claim is required")
+	}
+	switch usernameClaim.PrefixPolicy() {
+	case UsernamePrefixPolicyPrefix:
+		if usernameClaim.Prefix() == "" {
+			return fmt.Errorf("Username prefix is required when the prefix policy is '%s'",
+				UsernamePrefixPolicyPrefix)
+		}
+	case UsernamePrefixPolicyNone, UsernamePrefixPolicyNoPrefix:
+		if usernameClaim.Prefix() != "" {
+			return fmt.Errorf("Username prefix can only be set when the prefix policy is '%s'",
+				UsernamePrefixPolicyPrefix)
+		}
+	default:
+		return fmt.Errorf("Invalid username prefix policy '%s', must be one of ['%s']", usernameClaim.PrefixPolicy(),
+			strings.Join(ValidUsernamePrefixPolicies, "', '"))
+	}
+	return nil
+}
+
+// ValidateGroupsClaim checks that the groups prefix is only set together with the groups claim
+func ValidateGroupsClaim(groupsClaim *cmv1.GroupsClaim) error {
+	if groupsClaim.Claim() == "" && groupsClaim.Prefix() != "" {
+		return fmt.Errorf("Groups prefix can only be set together with the groups claim")
+	}
+	return nil
+}
+
+// ValidateClaimValidationRules checks that every rule has a claim and a required value and that claims are
+// not repeated
+func ValidateClaimValidationRules(rules []*cmv1.TokenClaimValidationRule) error {
+	var errs []error
+	seen := map[string]bool{}
+	for i, rule := range rules {
+		if rule.Claim() == "" {
+			errs = append(errs, fmt.Errorf("Claim validation rule %d has no claim", i))
+			continue
+		}
+		if rule.RequiredValue() == "" {
+			errs = append(errs, fmt.Errorf("Claim validation rule for claim '%s' has no required value", rule.Claim()))
+		}
+		if seen[rule.Claim()] {
+			errs = append(errs, fmt.Errorf("Claim validation rule for claim '%s' is duplicated", rule.Claim()))
+		}
+		seen[rule.Claim()] = true
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateClients checks that every client has an ID and a component with name and namespace, and that
+// components are not repeated
+func ValidateClients(clients []*cmv1.ExternalAuthClientConfig) error {
+	var errs []error
+	seen := map[string]bool{}
+	for i, client := range clients {
+		if client.ID() == "" {
+			errs = append(errs, fmt.Errorf("Client %d has no ID", i))
+		}
+		component := client.Component()
+		if component.Name() == "" || component.Namespace() == "" {
+			errs = append(errs, fmt.Errorf("Client %d must have a component name and namespace", i))
+			continue
+		}
+		key := component.Namespace() + "/" + component.Name()
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("Client component '%s' is duplicated", key))
+		}
+		seen[key] = true
+	}
+	return errors.Join(errs...)
+}
+
+// discoveryDocument is the subset of the OpenID provider metadata that is checked
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// CheckIssuerDiscovery fetches the OpenID discovery document of the issuer and checks that it is reachable,
+// that it declares the same issuer and that it publishes its keys. When ca is not empty it is used instead of
+// the system certificates to verify the issuer.
+func CheckIssuerDiscovery(ctx context.Context, issuerURL string, ca string, timeout time.Duration) error {
+	if err := ValidateIssuerURL(issuerURL); err != nil {
+		return err
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca != "" {
+		certificates, err := utils.ParsePEMCertificates(ca)
+		if err != nil {
+			return fmt.Errorf("Invalid CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		for _, certificate := range certificates {
+			pool.AddCert(certificate)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	httpClient := &http.Client{Transport: transport, Timeout: timeout}
+
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + discoveryPath
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("Issuer discovery document '%s' is not reachable: %v", discoveryURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("Issuer discovery document '%s' returned status %d", discoveryURL, response.StatusCode)
+	}
+	document := discoveryDocument{}
+	if err = json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return fmt.Errorf("Failed to decode issuer discovery document '%s': %v", discoveryURL, err)
+	}
+	if document.Issuer != issuerURL {
+		return fmt.Errorf("Issuer discovery document declares issuer '%s' instead of '%s'", document.Issuer, issuerURL)
+	}
+	if document.JWKSURI == "" {
+		return fmt.Errorf("Issuer discovery document '%s' has no 'jwks_uri'", discoveryURL)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package validations
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("External auth validations", func() {
+	buildExternalAuth := func(issuer *cmv1.TokenIssuerBuilder, username *cmv1.UsernameClaimBuilder) *cmv1.ExternalAuth {
+		externalAuth, err := cmv1.NewExternalAuth().ID("entra").
+			Issuer(issuer).
+			Claim(cmv1.NewExternalAuthClaim().Mappings(cmv1.NewTokenClaimMappings().UserName(username))).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		return externalAuth
+	}
+
+	It("accepts a valid provider", func() {
+		externalAuth := buildExternalAuth(
+			cmv1.NewTokenIssuer().URL("https://login.example.com/tenant/v2.0").Audiences("console", "cli"),
+			cmv1.NewUsernameClaim().Claim("email").PrefixPolicy(UsernamePrefixPolicyNoPrefix))
+		Expect(ValidateExternalAuth(externalAuth)).To(Succeed())
+	})
+
+	It("reports every invalid field", func() {
+		externalAuth := buildExternalAuth(
+			cmv1.NewTokenIssuer().URL("http://login.example.com").Audiences("cli", "cli").CA("not a cert"),
+			cmv1.NewUsernameClaim().Claim("email").PrefixPolicy(UsernamePrefixPolicyPrefix))
+		err := ValidateExternalAuth(externalAuth)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("must use the https scheme"))
+		Expect(err.Error()).To(ContainSubstring("Audience 'cli' is duplicated"))
+		Expect(err.Error()).To(ContainSubstring("Invalid CA"))
+		Expect(err.Error()).To(ContainSubstring("Username prefix is required"))
+	})
+
+	DescribeTable("issuer URL",
+		func(issuerURL string, valid bool) {
+			err := ValidateIssuerURL(issuerURL)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("https", "https://issuer.example.com", true),
+		Entry("empty", "", false),
+		Entry("http", "http://issuer.example.com", false),
+		Entry("query", "https://issuer.example.com?tenant=1", false),
+		Entry("user info", "https://user@issuer.example.com", false),
+	)
+
+	It("limits the number of audiences", func() {
+		Expect(ValidateAudiences(nil)).ToNot(Succeed())
+		audiences := make([]string, MaxAudiences+1)
+		for i := range audiences {
+			audiences[i] = string(rune('a' + i))
+		}
+		Expect(ValidateAudiences(audiences)).ToNot(Succeed())
+		Expect(ValidateAudiences(audiences[:MaxAudiences])).To(Succeed())
+	})
+
+	It("validates the claim mappings and rules", func() {
+		username, err := cmv1.NewUsernameClaim().Claim("sub").Prefix("corp:").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateUsernameClaim(username)).ToNot(Succeed())
+		username, err = cmv1.NewUsernameClaim().Claim("sub").PrefixPolicy("Always").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateUsernameClaim(username)).ToNot(Succeed())
+
+		groups, err := cmv1.NewGroupsClaim().Prefix("corp:").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateGroupsClaim(groups)).ToNot(Succeed())
+
+		rule, err := cmv1.NewTokenClaimValidationRule().Claim("hd").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateClaimValidationRules([]*cmv1.TokenClaimValidationRule{rule})).To(
+			MatchError("Claim validation rule for claim 'hd' has no required value"))
+	})
+
+	Context("Issuer discovery", func() {
+		var server *httptest.Server
+		var issuer string
+		var ca string
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != discoveryPath {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(map[string]string{
+					"issuer":   issuer,
+					"jwks_uri": server.URL + "/keys",
+				})).To(Succeed())
+			}))
+			issuer = server.URL
+			ca = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("succeeds when the document matches the issuer", func() {
+			Expect(ValidateIssuerCA(ca)).To(Succeed())
+			Expect(CheckIssuerDiscovery(context.Background(), server.URL, ca, 5*time.Second)).To(Succeed())
+		})
+
+		It("fails when the issuer doesn't match", func() {
+			issuer = "https://other.example.com"
+			err := CheckIssuerDiscovery(context.Background(), server.URL, ca, 5*time.Second)
+			Expect(err).To(MatchError(ContainSubstring("declares issuer 'https://other.example.com'")))
+		})
+
+		It("fails when the certificate is not trusted", func() {
+			err := CheckIssuerDiscovery(context.Background(), server.URL, "", 5*time.Second)
+			Expect(err).To(MatchError(ContainSubstring("is not reachable")))
+		})
+	})
+})
@@ -0,0 +1,186 @@
+package validations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidWildcardPolicies are the accepted values of the route wildcard policy
+var ValidWildcardPolicies = []string{
+	string(cmv1.WildcardPolicyWildcardsDisallowed),
+	string(cmv1.WildcardPolicyWildcardsAllowed),
+}
+
+// ValidNamespaceOwnershipPolicies are the accepted values of the route namespace ownership policy
+var ValidNamespaceOwnershipPolicies = []string{
+	string(cmv1.NamespaceOwnershipPolicyStrict),
+	string(cmv1.NamespaceOwnershipPolicyInterNamespaceAllowed),
+}
+
+// ValidLoadBalancerTypes are the accepted values of the ingress load balancer type
+var ValidLoadBalancerTypes = []string{
+	string(cmv1.LoadBalancerFlavorClassic),
+	string(cmv1.LoadBalancerFlavorNlb),
+}
+
+// ValidListeningModes are the accepted values of the ingress listening mode
+var ValidListeningModes = []string{
+	string(cmv1.ListeningMethodExternal),
+	string(cmv1.ListeningMethodInternal),
+}
+
+// ParseRouteSelectors parses a comma separated list of 'key=value' route selectors using the same rules
+// Kubernetes applies to label selectors. An empty string returns an empty map.
+func ParseRouteSelectors(routeSelectors string) (map[string]string, error) {
+	if strings.TrimSpace(routeSelectors) == "" {
+		return map[string]string{}, nil
+	}
+	selectors, err := labels.ConvertSelectorToLabelsMap(routeSelectors)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid route selectors '%s': %v", routeSelectors, err)
+	}
+	return selectors, nil
+}
+
+// ValidateRouteSelectors checks that keys and values of the route selectors are valid label keys and values
+func ValidateRouteSelectors(routeSelectors map[string]string) error {
+	var errs []error
+	for key, value := range routeSelectors {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Errorf("Invalid route selector key '%s': %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, fmt.Errorf("Invalid route selector value '%s' for key '%s': %s", value, key, msg))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParseExcludedNamespaces parses a comma separated list of namespaces, ignoring blanks around the names,
+// and validates each of them. An empty string returns an empty list.
+func ParseExcludedNamespaces(excludedNamespaces string) ([]string, error) {
+	namespaces := []string{}
+	if strings.TrimSpace(excludedNamespaces) == "" {
+		return namespaces, nil
+	}
+	for _, namespace := range strings.Split(excludedNamespaces, ",") {
+		namespaces = append(namespaces, strings.TrimSpace(namespace))
+	}
+	return namespaces, ValidateExcludedNamespaces(namespaces)
+}
+
+// ValidateExcludedNamespaces checks that every excluded namespace is a valid namespace name and that
+// there are no duplicates
+func ValidateExcludedNamespaces(excludedNamespaces []string) error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, namespace := range excludedNamespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			errs = append(errs, fmt.Errorf("Invalid excluded namespace '%s': %s", namespace, msg))
+		}
+		if seen[namespace] {
+			errs = append(errs, fmt.Errorf("Excluded namespace '%s' is duplicated", namespace))
+		}
+		seen[namespace] = true
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateWildcardPolicy checks that the route wildcard policy is one of ValidWildcardPolicies
+func ValidateWildcardPolicy(policy string) error {
+	return validateOneOf("wildcard policy", policy, ValidWildcardPolicies)
+}
+
+// ValidateNamespaceOwnershipPolicy checks that the route namespace ownership policy is one of
+// ValidNamespaceOwnershipPolicies
+func ValidateNamespaceOwnershipPolicy(policy string) error {
+	return validateOneOf("namespace ownership policy", policy, ValidNamespaceOwnershipPolicies)
+}
+
+// ValidateLoadBalancerType checks that the load balancer type is one of ValidLoadBalancerTypes
+func ValidateLoadBalancerType(lbType string) error {
+	return validateOneOf("load balancer type", lbType, ValidLoadBalancerTypes)
+}
+
+// ValidateListeningMode checks that the listening mode is one of ValidListeningModes
+func ValidateListeningMode(mode string) error {
+	return validateOneOf("listening mode", mode, ValidListeningModes)
+}
+
+// ValidateIngress validates the settings of an ingress, either the default one or an additional one:
+//
+// * Route selectors, excluded namespaces and the enumerated policies must be valid.
+// * Hosted clusters only support the default ingress and its listening mode can be set, the router
+// settings (route selectors, excluded namespaces, wildcard and namespace ownership policies, load balancer type)
+// are managed by the service and can't be set.
+//
+// Fields that are not set on the ingress are not validated. All the issues are returned at once.
+func ValidateIngress(ingress *cmv1.Ingress, isHostedCP bool) error {
+	var errs []error
+	if ingress == nil {
+		return nil
+	}
+
+	routeSelectors, hasRouteSelectors := ingress.GetRouteSelectors()
+	excludedNamespaces, hasExcludedNamespaces := ingress.GetExcludedNamespaces()
+	wildcardPolicy, hasWildcardPolicy := ingress.GetRouteWildcardPolicy()
+	ownershipPolicy, hasOwnershipPolicy := ingress.GetRouteNamespaceOwnershipPolicy()
+	lbType, hasLBType := ingress.GetLoadBalancerType()
+	listening, hasListening := ingress.GetListening()
+
+	if isHostedCP {
+		if !ingress.Default() {
+			errs = append(errs, fmt.Errorf("Hosted clusters only support the default ingress"))
+		}
+		unsupported := []struct {
+			field string
+			set   bool
+		}{
+			{"route selectors", hasRouteSelectors},
+			{"excluded namespaces", hasExcludedNamespaces},
+			{"wildcard policy", hasWildcardPolicy},
+			{"namespace ownership policy", hasOwnershipPolicy},
+			{"load balancer type", hasLBType},
+		}
+		for _, setting := range unsupported {
+			if setting.set {
+				errs = append(errs, fmt.Errorf("Setting the ingress %s is not supported for Hosted clusters",
+					setting.field))
+			}
+		}
+	}
+
+	if hasRouteSelectors {
+		errs = append(errs, ValidateRouteSelectors(routeSelectors))
+	}
+	if hasExcludedNamespaces {
+		errs = append(errs, ValidateExcludedNamespaces(excludedNamespaces))
+	}
+	if hasWildcardPolicy {
+		errs = append(errs, ValidateWildcardPolicy(string(wildcardPolicy)))
+	}
+	if hasOwnershipPolicy {
+		errs = append(errs, ValidateNamespaceOwnershipPolicy(string(ownershipPolicy)))
+	}
+	if hasLBType {
+		errs = append(errs, ValidateLoadBalancerType(string(lbType)))
+	}
+	if hasListening {
+		errs = append(errs, ValidateListeningMode(string(listening)))
+	}
+	return errors.Join(errs...)
+}
+
+func validateOneOf(field string, value string, validValues []string) error {
+	for _, validValue := range validValues {
+		if value == validValue {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid %s '%s', must be one of [%s]", field, value, strings.Join(validValues, ", "))
+}
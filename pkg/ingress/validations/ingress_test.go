@@ -0,0 +1,105 @@
+package validations
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func buildIngress(builder *cmv1.IngressBuilder) *cmv1.Ingress {
+	ingress, err := builder.Build()
+	Expect(err).ToNot(HaveOccurred())
+	return ingress
+}
+
+var _ = Describe("Ingress validations", func() {
+	Context("Route selectors", func() {
+		It("parses valid selectors", func() {
+			selectors, err := ParseRouteSelectors("app=web, example.com/tier=frontend")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selectors).To(Equal(map[string]string{"app": "web", "example.com/tier": "frontend"}))
+		})
+		It("returns an empty map for an empty string", func() {
+			selectors, err := ParseRouteSelectors("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(selectors).To(BeEmpty())
+		})
+		It("rejects selectors that are not key=value pairs", func() {
+			_, err := ParseRouteSelectors("app")
+			Expect(err).To(HaveOccurred())
+		})
+		It("rejects invalid keys and values", func() {
+			err := ValidateRouteSelectors(map[string]string{"-app": "web", "tier": "front end"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid route selector key '-app'"))
+			Expect(err.Error()).To(ContainSubstring("Invalid route selector value 'front end'"))
+		})
+	})
+
+	Context("Excluded namespaces", func() {
+		It("parses valid namespaces", func() {
+			namespaces, err := ParseExcludedNamespaces("stage, dev")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(namespaces).To(Equal([]string{"stage", "dev"}))
+		})
+		It("rejects invalid and duplicated namespaces", func() {
+			_, err := ParseExcludedNamespaces("Stage,dev,dev")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Invalid excluded namespace 'Stage'"))
+			Expect(err.Error()).To(ContainSubstring("Excluded namespace 'dev' is duplicated"))
+		})
+	})
+
+	Context("Enumerated settings", func() {
+		It("accepts the known values", func() {
+			Expect(ValidateWildcardPolicy("WildcardsAllowed")).To(Succeed())
+			Expect(ValidateNamespaceOwnershipPolicy("Strict")).To(Succeed())
+			Expect(ValidateLoadBalancerType("nlb")).To(Succeed())
+			Expect(ValidateListeningMode("internal")).To(Succeed())
+		})
+		It("rejects unknown values", func() {
+			Expect(ValidateWildcardPolicy("Allowed")).To(MatchError(
+				"Invalid wildcard policy 'Allowed', must be one of [WildcardsDisallowed, WildcardsAllowed]"))
+			Expect(ValidateNamespaceOwnershipPolicy("strict")).ToNot(Succeed())
+			Expect(ValidateLoadBalancerType("alb")).ToNot(Succeed())
+			Expect(ValidateListeningMode("private")).ToNot(Succeed())
+		})
+	})
+
+	Context("Ingress", func() {
+		It("accepts a valid classic ingress", func() {
+			ingress := buildIngress(cmv1.NewIngress().
+				RouteSelectors(map[string]string{"app": "web"}).
+				ExcludedNamespaces("stage").
+				RouteWildcardPolicy(cmv1.WildcardPolicyWildcardsAllowed).
+				RouteNamespaceOwnershipPolicy(cmv1.NamespaceOwnershipPolicyStrict).
+				LoadBalancerType(cmv1.LoadBalancerFlavorNlb).
+				Listening(cmv1.ListeningMethodInternal))
+			Expect(ValidateIngress(ingress, false)).To(Succeed())
+		})
+		It("reports every invalid field", func() {
+			ingress := buildIngress(cmv1.NewIngress().
+				ExcludedNamespaces("Stage").
+				RouteWildcardPolicy("Allowed").
+				LoadBalancerType("alb"))
+			err := ValidateIngress(ingress, false)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("excluded namespace"))
+			Expect(err.Error()).To(ContainSubstring("wildcard policy"))
+			Expect(err.Error()).To(ContainSubstring("load balancer type"))
+		})
+		It("only allows the listening mode of the default ingress for Hosted clusters", func() {
+			ingress := buildIngress(cmv1.NewIngress().Default(true).Listening(cmv1.ListeningMethodExternal))
+			Expect(ValidateIngress(ingress, true)).To(Succeed())
+
+			ingress = buildIngress(cmv1.NewIngress().
+				RouteSelectors(map[string]string{"app": "web"}).
+				LoadBalancerType(cmv1.LoadBalancerFlavorNlb))
+			err := ValidateIngress(ingress, true)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Hosted clusters only support the default ingress"))
+			Expect(err.Error()).To(ContainSubstring("route selectors is not supported for Hosted clusters"))
+			Expect(err.Error()).To(ContainSubstring("load balancer type is not supported for Hosted clusters"))
+		})
+	})
+})
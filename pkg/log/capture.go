@@ -0,0 +1,162 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// Capture collects the log entries written through this package, so that they can be shown only when needed,
+// for example when a test fails
+type Capture struct {
+	mutex     sync.Mutex
+	buffer    bytes.Buffer
+	formatter logger.Formatter
+}
+
+type captureContextKey struct{}
+
+var (
+	captureHookOnce sync.Once
+	dispatcher      = &captureDispatcher{active: map[*Capture]bool{}}
+)
+
+// NewCapture creates a capture that isn't registered yet, use it with StartCapture or ContextWithCapture
+func NewCapture() *Capture {
+	return &Capture{
+		formatter: &logger.TextFormatter{
+			DisableColors:   true,
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		},
+	}
+}
+
+// Write appends the formatted entry to the capture
+func (c *Capture) Write(entry *logger.Entry) error {
+	data, err := c.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, err = c.buffer.Write(data)
+	return err
+}
+
+// String returns all the captured entries
+func (c *Capture) String() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.buffer.String()
+}
+
+// Reset discards the captured entries
+func (c *Capture) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.buffer.Reset()
+}
+
+// StartCapture registers a new capture that receives every entry logged through this package until the returned
+// stop function is called. When mute is true the entries aren't written to the output of the global logger while
+// the capture is active.
+func StartCapture(mute bool) (capture *Capture, stop func()) {
+	Initlogger()
+	capture = NewCapture()
+	dispatcher.add(capture, mute)
+	return capture, func() {
+		dispatcher.remove(capture)
+	}
+}
+
+// ContextWithCapture returns a copy of ctx carrying the capture. The entries logged with the context variants of
+// the log functions, like LogInfoContext, are captured by it even if it wasn't started.
+func ContextWithCapture(ctx context.Context, capture *Capture) context.Context {
+	return context.WithValue(ctx, captureContextKey{}, capture)
+}
+
+// CaptureFromContext returns the capture carried by ctx, or nil
+func CaptureFromContext(ctx context.Context) *Capture {
+	if ctx == nil {
+		return nil
+	}
+	capture, _ := ctx.Value(captureContextKey{}).(*Capture)
+	return capture
+}
+
+// captureDispatcher is the logrus hook forwarding the entries to the active captures and to the one of
+// the entry context
+type captureDispatcher struct {
+	mutex  sync.Mutex
+	active map[*Capture]bool
+	muted  int
+	output io.Writer
+}
+
+func (d *captureDispatcher) Levels() []logger.Level {
+	return logger.AllLevels
+}
+
+func (d *captureDispatcher) Fire(entry *logger.Entry) error {
+	d.mutex.Lock()
+	captures := make([]*Capture, 0, len(d.active)+1)
+	for capture := range d.active {
+		captures = append(captures, capture)
+	}
+	d.mutex.Unlock()
+	if capture := CaptureFromContext(entry.Context); capture != nil && !d.isActive(capture) {
+		captures = append(captures, capture)
+	}
+	for _, capture := range captures {
+		if err := capture.Write(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *captureDispatcher) isActive(capture *Capture) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	_, ok := d.active[capture]
+	return ok
+}
+
+func (d *captureDispatcher) add(capture *Capture, mute bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.active[capture] = mute
+	if mute {
+		if d.muted == 0 {
+			d.output = logger.StandardLogger().Out
+			logger.SetOutput(io.Discard)
+		}
+		d.muted++
+	}
+}
+
+func (d *captureDispatcher) remove(capture *Capture) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	mute, ok := d.active[capture]
+	if !ok {
+		return
+	}
+	delete(d.active, capture)
+	if mute {
+		d.muted--
+		if d.muted == 0 {
+			logger.SetOutput(d.output)
+		}
+	}
+}
+
+func installCaptureHook() {
+	captureHookOnce.Do(func() {
+		logger.AddHook(dispatcher)
+	})
+}
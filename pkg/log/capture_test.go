@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logger "github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Capture", func() {
+	var output *bytes.Buffer
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		previous := logger.StandardLogger().Out
+		logger.SetOutput(output)
+		DeferCleanup(func() {
+			logger.SetOutput(previous)
+		})
+	})
+
+	It("captures the entries while started", func() {
+		capture, stop := StartCapture(false)
+		LogInfo("first")
+		stop()
+		LogInfo("second")
+		Expect(capture.String()).To(ContainSubstring("msg=first"))
+		Expect(capture.String()).ToNot(ContainSubstring("second"))
+		Expect(output.String()).To(ContainSubstring("first"))
+		Expect(output.String()).To(ContainSubstring("second"))
+	})
+
+	It("mutes the output while a muting capture is started", func() {
+		capture, stop := StartCapture(true)
+		LogError("muted")
+		stop()
+		LogError("not muted")
+		Expect(capture.String()).To(ContainSubstring("muted"))
+		Expect(output.String()).ToNot(ContainSubstring("msg=muted"))
+		Expect(output.String()).To(ContainSubstring("not muted"))
+	})
+
+	It("captures the entries logged with its context", func() {
+		capture := NewCapture()
+		ctx := ContextWithCapture(context.Background(), capture)
+		LogWarningContext(ctx, "with context")
+		LogWarning("without context")
+		LogInfoContext(context.Background(), "other context")
+		Expect(capture.String()).To(ContainSubstring("with context"))
+		Expect(capture.String()).ToNot(ContainSubstring("without context"))
+		Expect(capture.String()).ToNot(ContainSubstring("other context"))
+		Expect(CaptureFromContext(ctx)).To(BeIdenticalTo(capture))
+	})
+
+	It("doesn't duplicate the entries of a started capture logged with its context", func() {
+		capture, stop := StartCapture(false)
+		defer stop()
+		LogInfoContext(ContextWithCapture(context.Background(), capture), "once")
+		Expect(bytes.Count([]byte(capture.String()), []byte("once"))).To(Equal(1))
+		capture.Reset()
+		Expect(capture.String()).To(BeEmpty())
+	})
+})
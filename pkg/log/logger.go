@@ -1,6 +1,8 @@
 package log
 
 import (
+	"context"
+
 	logger "github.com/sirupsen/logrus"
 )
 
@@ -9,6 +11,7 @@ func Initlogger() {
 	customFormatter.TimestampFormat = "2006-01-02 15:04:05"
 	logger.SetFormatter(customFormatter)
 	customFormatter.FullTimestamp = true
+	installCaptureHook()
 }
 func LogInfo(format string, args ...interface{}) {
 	Initlogger()
@@ -29,3 +32,27 @@ func LogWarning(format string, args ...interface{}) {
 	Initlogger()
 	logger.Warnf(format, args...)
 }
+
+// LogInfoContext logs like LogInfo and also into the capture carried by ctx, see ContextWithCapture
+func LogInfoContext(ctx context.Context, format string, args ...interface{}) {
+	Initlogger()
+	logger.WithContext(ctx).Infof(format, args...)
+}
+
+// LogErrorContext logs like LogError and also into the capture carried by ctx, see ContextWithCapture
+func LogErrorContext(ctx context.Context, format string, args ...interface{}) {
+	Initlogger()
+	logger.WithContext(ctx).Errorf(format, args...)
+}
+
+// LogDebugContext logs like LogDebug and also into the capture carried by ctx, see ContextWithCapture
+func LogDebugContext(ctx context.Context, format string, args ...interface{}) {
+	Initlogger()
+	logger.WithContext(ctx).Debugf(format, args...)
+}
+
+// LogWarningContext logs like LogWarning and also into the capture carried by ctx, see ContextWithCapture
+func LogWarningContext(ctx context.Context, format string, args ...interface{}) {
+	Initlogger()
+	logger.WithContext(ctx).Warnf(format, args...)
+}
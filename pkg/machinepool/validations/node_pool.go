@@ -0,0 +1,48 @@
+package validations
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/duration_parser"
+)
+
+const (
+	// nodeDrainGracePeriodMax is the maximum node drain grace period of a node pool, one week
+	nodeDrainGracePeriodMax  = 7 * duration_parser.Day
+	nodeDrainGracePeriodUnit = "minutes"
+)
+
+// ParseNodeDrainGracePeriod parses the node drain grace period of a node pool, given as a duration like `30m`, `1h`
+// or `1d`. The period must be a whole number of minutes, at most one week.
+func ParseNodeDrainGracePeriod(period string) (time.Duration, error) {
+	gracePeriod, err := duration_parser.NewDurationParser(
+		duration_parser.WithMinimum(0),
+		duration_parser.WithMaximum(nodeDrainGracePeriodMax),
+	).Parse(period)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid node drain grace period: %v", err)
+	}
+	if gracePeriod%time.Minute != 0 {
+		return 0, fmt.Errorf("Invalid node drain grace period: '%s' must be a whole number of minutes", period)
+	}
+	return gracePeriod, nil
+}
+
+// NodeDrainGracePeriodValue returns the value of the node drain grace period of a node pool, in minutes
+func NodeDrainGracePeriodValue(gracePeriod time.Duration) *cmv1.ValueBuilder {
+	return cmv1.NewValue().Value(gracePeriod.Minutes()).Unit(nodeDrainGracePeriodUnit)
+}
+
+// ValidateNodeDrainGracePeriod validates the node drain grace period of a node pool
+func ValidateNodeDrainGracePeriod(gracePeriod *cmv1.Value) error {
+	if gracePeriod.Unit() != "" && gracePeriod.Unit() != nodeDrainGracePeriodUnit {
+		return fmt.Errorf("Invalid node drain grace period unit '%s', expected '%s'", gracePeriod.Unit(),
+			nodeDrainGracePeriodUnit)
+	}
+	_, err := ParseNodeDrainGracePeriod(strconv.FormatFloat(gracePeriod.Value(), 'f', -1, 64) + "m")
+	return err
+}
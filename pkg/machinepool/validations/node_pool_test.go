@@ -0,0 +1,47 @@
+package validations
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Node pool validations", func() {
+	DescribeTable("parses the node drain grace period", func(period string, expected time.Duration) {
+		gracePeriod, err := ParseNodeDrainGracePeriod(period)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gracePeriod).To(Equal(expected))
+	},
+		Entry("minutes", "30m", 30*time.Minute),
+		Entry("hours and minutes", "1h30m", 90*time.Minute),
+		Entry("days", "7d", 7*24*time.Hour),
+		Entry("zero", "0", time.Duration(0)),
+	)
+
+	DescribeTable("rejects an invalid node drain grace period", func(period string, errMessage string) {
+		_, err := ParseNodeDrainGracePeriod(period)
+		Expect(err).To(MatchError(ContainSubstring(errMessage)))
+	},
+		Entry("longer than a week", "7d1m", "must be at most 7d"),
+		Entry("negative", "-1h", "must be at least 0s"),
+		Entry("seconds", "90s", "must be a whole number of minutes"),
+		Entry("without unit", "30", "invalid duration '30'"),
+	)
+
+	It("validates the node drain grace period of a node pool", func() {
+		value, err := NodeDrainGracePeriodValue(2 * time.Hour).Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value.Value()).To(Equal(120.0))
+		Expect(ValidateNodeDrainGracePeriod(value)).To(Succeed())
+
+		value, err = cmv1.NewValue().Value(20000).Unit("minutes").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateNodeDrainGracePeriod(value)).To(MatchError(ContainSubstring("must be at most 7d")))
+
+		value, err = cmv1.NewValue().Value(1).Unit("hours").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateNodeDrainGracePeriod(value)).To(MatchError(ContainSubstring("expected 'minutes'")))
+	})
+})
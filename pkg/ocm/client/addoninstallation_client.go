@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+
+	addonsv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+)
+
+// AddonInstallationClient wraps the add-ons management API of a cluster. Installations are identified
+// by the ID of the add-on they install
+//
+//go:generate mockgen -source=addoninstallation_client.go -package=test -destination=test/mock_addoninstallation_client.go
+type AddonInstallationClient interface {
+	CollectionClusterSubResource[addonsv1.AddonInstallation, string]
+}
+
+func NewAddonInstallationClient(collection *addonsv1.ClustersClient) AddonInstallationClient {
+	return &CollectionClusterSubResourceImpl[addonsv1.AddonInstallation, string]{
+		getFunc: func(ctx context.Context, clusterId string, instanceId string) (OcmInstanceResponse[addonsv1.AddonInstallation], error) {
+			return collection.Cluster(clusterId).Addons().Addon(instanceId).Get().SendContext(ctx)
+		},
+		updateFunc: func(ctx context.Context, clusterId string, instance *addonsv1.AddonInstallation) (OcmInstanceResponse[addonsv1.AddonInstallation], error) {
+			return collection.Cluster(clusterId).Addons().Addon(instance.ID()).Update().Body(instance).SendContext(ctx)
+		},
+		createFunc: func(ctx context.Context, clusterId string, instance *addonsv1.AddonInstallation) (OcmInstanceResponse[addonsv1.AddonInstallation], error) {
+			return collection.Cluster(clusterId).Addons().Add().Body(instance).SendContext(ctx)
+		},
+		deleteFunc: func(ctx context.Context, clusterId string, instanceId string) (OcmResponse, error) {
+			return collection.Cluster(clusterId).Addons().Addon(instanceId).Delete().SendContext(ctx)
+		},
+		listFunc: func(ctx context.Context, clusterId string, paging Paging) (OcmListResponse[addonsv1.AddonInstallation], error) {
+			resp, err := collection.Cluster(clusterId).Addons().List().Size(paging.size).Page(paging.page).SendContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return NewListResponse(resp.Status(), resp.Items().Slice()), nil
+		},
+	}
+}
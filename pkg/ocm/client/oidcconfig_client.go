@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+const clustersPageSize = 100
+
+// OidcConfigClient wraps the OIDC configs API of OCM. OIDC configs are not bound to a cluster, they are
+// registered once and referenced by the clusters using their issuer.
+//
+//go:generate mockgen -source=oidcconfig_client.go -package=test -destination=test/mock_oidcconfig_client.go
+type OidcConfigClient interface {
+	Get(ctx context.Context, oidcConfigId string) (*v1.OidcConfig, error)
+	Exists(ctx context.Context, oidcConfigId string) (bool, *v1.OidcConfig, error)
+	Create(ctx context.Context, instance *v1.OidcConfig) (*v1.OidcConfig, error)
+	Delete(ctx context.Context, oidcConfigId string) error
+	List(ctx context.Context, paging Paging) ([]*v1.OidcConfig, bool, error)
+	ClustersUsing(ctx context.Context, oidcConfigId string) ([]string, error)
+}
+
+type oidcConfigClient struct {
+	collection *v1.OidcConfigsClient
+	clusters   *v1.ClustersClient
+}
+
+var _ OidcConfigClient = &oidcConfigClient{}
+
+func NewOidcConfigClient(collection *v1.OidcConfigsClient, clusters *v1.ClustersClient) OidcConfigClient {
+	return &oidcConfigClient{
+		collection: collection,
+		clusters:   clusters,
+	}
+}
+
+func (c *oidcConfigClient) Get(ctx context.Context, oidcConfigId string) (*v1.OidcConfig, error) {
+	response, err := c.collection.OidcConfig(oidcConfigId).Get().SendContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body(), nil
+}
+
+func (c *oidcConfigClient) Exists(ctx context.Context, oidcConfigId string) (bool, *v1.OidcConfig, error) {
+	return exists[v1.OidcConfig](c.collection.OidcConfig(oidcConfigId).Get().SendContext(ctx))
+}
+
+// Create registers the OIDC config, see oidcconfigs.BuildManagedOidcConfig and
+// oidcconfigs.BuildUnmanagedOidcConfig
+func (c *oidcConfigClient) Create(ctx context.Context, instance *v1.OidcConfig) (*v1.OidcConfig, error) {
+	response, err := c.collection.Add().Body(instance).SendContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body(), nil
+}
+
+// Delete deregisters the OIDC config. It fails when clusters still use it.
+func (c *oidcConfigClient) Delete(ctx context.Context, oidcConfigId string) error {
+	clusterIds, err := c.ClustersUsing(ctx, oidcConfigId)
+	if err != nil {
+		return err
+	}
+	if len(clusterIds) > 0 {
+		return fmt.Errorf("OIDC config '%s' is still used by the clusters [%s]", oidcConfigId,
+			strings.Join(clusterIds, ", "))
+	}
+	_, err = c.collection.OidcConfig(oidcConfigId).Delete().SendContext(ctx)
+	return err
+}
+
+func (c *oidcConfigClient) List(ctx context.Context, paging Paging) ([]*v1.OidcConfig, bool, error) {
+	response, err := c.collection.List().Size(paging.size).Page(paging.page).SendContext(ctx)
+	if err != nil {
+		return make([]*v1.OidcConfig, 0), false, err
+	}
+	items := response.Items().Slice()
+	return items, len(items) != 0, nil
+}
+
+// ClustersUsing returns the IDs of the clusters whose OIDC endpoint is the issuer of the OIDC config
+func (c *oidcConfigClient) ClustersUsing(ctx context.Context, oidcConfigId string) ([]string, error) {
+	oidcConfig, err := c.Get(ctx, oidcConfigId)
+	if err != nil {
+		return nil, err
+	}
+	search := fmt.Sprintf("aws.sts.oidc_endpoint_url = '%s'", oidcConfig.IssuerUrl())
+	clusterIds := []string{}
+	for page := 1; ; page++ {
+		response, err := c.clusters.List().Search(search).Size(clustersPageSize).Page(page).SendContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		response.Items().Each(func(cluster *v1.Cluster) bool {
+			clusterIds = append(clusterIds, cluster.ID())
+			return true
+		})
+		if response.Size() < clustersPageSize {
+			return clusterIds, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oidcconfig_client.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oidcconfig_client.go -package=test -destination=test/mock_oidcconfig_client.go
+//
+// Package test is a generated GoMock package.
+package test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	client "github.com/openshift-online/ocm-common/pkg/ocm/client"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOidcConfigClient is a mock of OidcConfigClient interface.
+type MockOidcConfigClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockOidcConfigClientMockRecorder
+}
+
+// MockOidcConfigClientMockRecorder is the mock recorder for MockOidcConfigClient.
+type MockOidcConfigClientMockRecorder struct {
+	mock *MockOidcConfigClient
+}
+
+// NewMockOidcConfigClient creates a new mock instance.
+func NewMockOidcConfigClient(ctrl *gomock.Controller) *MockOidcConfigClient {
+	mock := &MockOidcConfigClient{ctrl: ctrl}
+	mock.recorder = &MockOidcConfigClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOidcConfigClient) EXPECT() *MockOidcConfigClientMockRecorder {
+	return m.recorder
+}
+
+// ClustersUsing mocks base method.
+func (m *MockOidcConfigClient) ClustersUsing(ctx context.Context, oidcConfigId string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClustersUsing", ctx, oidcConfigId)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClustersUsing indicates an expected call of ClustersUsing.
+func (mr *MockOidcConfigClientMockRecorder) ClustersUsing(ctx, oidcConfigId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClustersUsing", reflect.TypeOf((*MockOidcConfigClient)(nil).ClustersUsing), ctx, oidcConfigId)
+}
+
+// Create mocks base method.
+func (m *MockOidcConfigClient) Create(ctx context.Context, instance *v1.OidcConfig) (*v1.OidcConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, instance)
+	ret0, _ := ret[0].(*v1.OidcConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOidcConfigClientMockRecorder) Create(ctx, instance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOidcConfigClient)(nil).Create), ctx, instance)
+}
+
+// Delete mocks base method.
+func (m *MockOidcConfigClient) Delete(ctx context.Context, oidcConfigId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, oidcConfigId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOidcConfigClientMockRecorder) Delete(ctx, oidcConfigId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOidcConfigClient)(nil).Delete), ctx, oidcConfigId)
+}
+
+// Exists mocks base method.
+func (m *MockOidcConfigClient) Exists(ctx context.Context, oidcConfigId string) (bool, *v1.OidcConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, oidcConfigId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(*v1.OidcConfig)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockOidcConfigClientMockRecorder) Exists(ctx, oidcConfigId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockOidcConfigClient)(nil).Exists), ctx, oidcConfigId)
+}
+
+// Get mocks base method.
+func (m *MockOidcConfigClient) Get(ctx context.Context, oidcConfigId string) (*v1.OidcConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, oidcConfigId)
+	ret0, _ := ret[0].(*v1.OidcConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOidcConfigClientMockRecorder) Get(ctx, oidcConfigId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOidcConfigClient)(nil).Get), ctx, oidcConfigId)
+}
+
+// List mocks base method.
+func (m *MockOidcConfigClient) List(ctx context.Context, paging client.Paging) ([]*v1.OidcConfig, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, paging)
+	ret0, _ := ret[0].([]*v1.OidcConfig)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockOidcConfigClientMockRecorder) List(ctx, paging any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOidcConfigClient)(nil).List), ctx, paging)
+}
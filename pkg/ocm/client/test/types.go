@@ -1,6 +1,9 @@
 package test
 
-import v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+import (
+	addonsv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
 
 // NewKubeletConfig creates an empty KubeletConfig that can be used in tests. Tests can
 // mutate the KubeletConfig to their requirements via the variadic list of functions
@@ -41,3 +44,23 @@ func NewNodePool(modifyFn ...func(k *v1.NodePoolBuilder)) (*v1.NodePool, error)
 	}
 	return builder.Build()
 }
+
+// NewAddonInstallation creates an empty AddonInstallation that can be used in tests. Tests can
+// mutate the AddonInstallation to their requirements via the variadic list of functions
+func NewAddonInstallation(modifyFn ...func(k *addonsv1.AddonInstallationBuilder)) (*addonsv1.AddonInstallation, error) {
+	builder := &addonsv1.AddonInstallationBuilder{}
+	for _, f := range modifyFn {
+		f(builder)
+	}
+	return builder.Build()
+}
+
+// NewOidcConfig creates an empty OidcConfig that can be used in tests. Tests can
+// mutate the OidcConfig to their requirements via the variadic list of functions
+func NewOidcConfig(modifyFn ...func(k *v1.OidcConfigBuilder)) (*v1.OidcConfig, error) {
+	builder := &v1.OidcConfigBuilder{}
+	for _, f := range modifyFn {
+		f(builder)
+	}
+	return builder.Build()
+}
@@ -3,6 +3,7 @@ package test
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	addonsv1 "github.com/openshift-online/ocm-sdk-go/addonsmgmt/v1"
 	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
@@ -44,4 +45,23 @@ var _ = Describe("OCM Client Types", func() {
 		Expect(autoscaler.LogVerbosity()).To(Equal(10))
 	})
 
+	It("Allows customisation of AddonInstallation", func() {
+		installation, err := NewAddonInstallation(func(k *addonsv1.AddonInstallationBuilder) {
+			k.ID("my-addon")
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installation.ID()).To(Equal("my-addon"))
+	})
+
+	It("Allows customisation of OidcConfig", func() {
+		oidcConfig, err := NewOidcConfig(func(k *v1.OidcConfigBuilder) {
+			k.IssuerUrl("https://oidc.example.com").Managed(false)
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(oidcConfig.IssuerUrl()).To(Equal("https://oidc.example.com"))
+		Expect(oidcConfig.Managed()).To(BeFalse())
+	})
+
 })
@@ -19,6 +19,7 @@ import (
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/openshift-online/ocm-common/pkg/utils"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
 const (
@@ -67,6 +68,33 @@ func BuildOidcConfigInput(userPrefix, region string) (OidcConfigInput, error) {
 	}, nil
 }
 
+// BuildManagedOidcConfig builds the OCM OIDC config whose issuer is hosted and managed by Red Hat
+func BuildManagedOidcConfig() (*cmv1.OidcConfig, error) {
+	return cmv1.NewOidcConfig().Managed(true).Build()
+}
+
+// BuildUnmanagedOidcConfig builds the OCM OIDC config registering the issuer of the input, hosted by the
+// customer. The secret ARN is the one of the secret storing the private key of the input, named after its
+// PrivateKeySecretName.
+func BuildUnmanagedOidcConfig(input OidcConfigInput, secretArn string,
+	installerRoleArn string) (*cmv1.OidcConfig, error) {
+	if input.IssuerUrl == "" {
+		return nil, fmt.Errorf("The issuer URL is required to register an unmanaged OIDC config")
+	}
+	if secretArn == "" {
+		return nil, fmt.Errorf("The secret ARN is required to register an unmanaged OIDC config")
+	}
+	if installerRoleArn == "" {
+		return nil, fmt.Errorf("The installer role ARN is required to register an unmanaged OIDC config")
+	}
+	return cmv1.NewOidcConfig().
+		Managed(false).
+		IssuerUrl(input.IssuerUrl).
+		SecretArn(secretArn).
+		InstallerRoleArn(installerRoleArn).
+		Build()
+}
+
 func GenerateBucketName(userPrefix string) (string, error) {
 	randomLabel := utils.RandomLabel(defaultLengthRandomLabel)
 	bucketName := fmt.Sprintf("%s-%s", defaultPrefixForConfiguration, randomLabel)
@@ -0,0 +1,115 @@
+package orphans
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/validations"
+	"github.com/openshift-online/ocm-common/pkg/rosa/operatorroles"
+)
+
+// operatorNamespacePrefixes are the namespaces of the operators ROSA creates roles for, an operator role name is
+// '<prefix>-<namespace>-<secret name>' possibly truncated
+var operatorNamespacePrefixes = []string{"-openshift-", "-kube-system-"}
+
+// References are the IAM resources known by OCM to be in use
+type References struct {
+	ClusterIDs       map[string]bool
+	OperatorRoleARNs map[string]bool
+	// IssuerURLs are the issuer URLs of the clusters and of the registered OIDC configurations, without scheme
+	IssuerURLs map[string]bool
+}
+
+// NewReferences collects the operator roles and OIDC issuers of the live clusters, using the same shape as
+// GetOperatorRolesArnsMap, and the issuers of the registered OIDC configurations
+func NewReferences(clusters []*cmv1.Cluster, oidcConfigs []*cmv1.OidcConfig) *References {
+	references := &References{
+		ClusterIDs:       map[string]bool{},
+		OperatorRoleARNs: map[string]bool{},
+		IssuerURLs:       map[string]bool{},
+	}
+	for _, cluster := range clusters {
+		references.ClusterIDs[cluster.ID()] = true
+		for _, arn := range operatorroles.GetOperatorRolesArnsMap(cluster) {
+			references.OperatorRoleARNs[arn] = true
+		}
+		if issuerURL := cluster.AWS().STS().OIDCEndpointURL(); issuerURL != "" {
+			references.IssuerURLs[normalizeIssuerURL(issuerURL)] = true
+		}
+		if issuerURL := cluster.AWS().STS().OidcConfig().IssuerUrl(); issuerURL != "" {
+			references.IssuerURLs[normalizeIssuerURL(issuerURL)] = true
+		}
+	}
+	for _, oidcConfig := range oidcConfigs {
+		if oidcConfig.IssuerUrl() != "" {
+			references.IssuerURLs[normalizeIssuerURL(oidcConfig.IssuerUrl())] = true
+		}
+	}
+	return references
+}
+
+// IsOperatorRole tells if the role was created by ROSA for an operator, from its name and tags
+func IsOperatorRole(roleName string, tags []iamtypes.Tag) bool {
+	if !looksLikeOperatorRole(roleName) {
+		return false
+	}
+	return tagValue(tags, validations.OperatorNamespace) != "" && tagValue(tags, validations.OperatorName) != ""
+}
+
+// IsROSAOIDCProvider tells if the OIDC provider was created by ROSA, from its tags
+func IsROSAOIDCProvider(tags []iamtypes.Tag) bool {
+	return tagValue(tags, validations.RedHatManaged) == "true" || tagValue(tags, validations.ClusterIDTag) != ""
+}
+
+// RoleInUse tells if a live cluster references the operator role, either by its ARN or by the cluster ID tag
+func (r *References) RoleInUse(roleARN string, tags []iamtypes.Tag) bool {
+	if r.OperatorRoleARNs[roleARN] {
+		return true
+	}
+	clusterID := tagValue(tags, validations.ClusterIDTag)
+	return clusterID != "" && r.ClusterIDs[clusterID]
+}
+
+// OIDCProviderInUse tells if a live cluster or a registered OIDC configuration uses the issuer of the provider,
+// or if the provider is tagged for a live cluster
+func (r *References) OIDCProviderInUse(issuerURL string, tags []iamtypes.Tag) bool {
+	if r.IssuerURLs[normalizeIssuerURL(issuerURL)] {
+		return true
+	}
+	clusterID := tagValue(tags, validations.ClusterIDTag)
+	return clusterID != "" && r.ClusterIDs[clusterID]
+}
+
+// tooRecent tells if the resource may belong to a cluster being created, operator roles and OIDC providers can
+// be created before the cluster
+func tooRecent(createDate *time.Time, minAge time.Duration, now time.Time) bool {
+	return createDate != nil && now.Sub(*createDate) < minAge
+}
+
+func looksLikeOperatorRole(roleName string) bool {
+	for _, namespacePrefix := range operatorNamespacePrefixes {
+		if strings.Contains(roleName, namespacePrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeIssuerURL removes the scheme and the trailing slash, IAM OIDC providers store the URL without scheme
+func normalizeIssuerURL(issuerURL string) string {
+	issuerURL = strings.TrimPrefix(issuerURL, "https://")
+	return strings.TrimSuffix(issuerURL, "/")
+}
+
+func tagValue(tags []iamtypes.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package orphans_test
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/openshift-online/ocm-common/pkg/rosa/orphans"
+)
+
+func iamTags(keysAndValues ...string) []iamtypes.Tag {
+	tags := []iamtypes.Tag{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		tags = append(tags, iamtypes.Tag{Key: aws.String(keysAndValues[i]), Value: aws.String(keysAndValues[i+1])})
+	}
+	return tags
+}
+
+var _ = Describe("Orphan references", func() {
+	var references *References
+
+	BeforeEach(func() {
+		cluster, err := cmv1.NewCluster().ID("live-cluster").
+			AWS(cmv1.NewAWS().STS(cmv1.NewSTS().
+				OIDCEndpointURL("https://oidc.example.com/live-cluster").
+				OperatorIAMRoles(
+					cmv1.NewOperatorIAMRole().Name("ingress").
+						RoleARN("arn:aws:iam::123456789012:role/live-openshift-ingress-operator-cloud-credentials"),
+				))).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		oidcConfig, err := cmv1.NewOidcConfig().ID("config").IssuerUrl("https://oidc.example.com/registered/").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		references = NewReferences([]*cmv1.Cluster{cluster}, []*cmv1.OidcConfig{oidcConfig})
+	})
+
+	It("collects the references of the clusters and OIDC configurations", func() {
+		Expect(references.ClusterIDs).To(HaveKey("live-cluster"))
+		Expect(references.OperatorRoleARNs).To(HaveLen(1))
+		Expect(references.IssuerURLs).To(HaveKey("oidc.example.com/live-cluster"))
+		Expect(references.IssuerURLs).To(HaveKey("oidc.example.com/registered"))
+	})
+
+	It("finds the roles in use by ARN or cluster ID tag", func() {
+		Expect(references.RoleInUse("arn:aws:iam::123456789012:role/live-openshift-ingress-operator-cloud-credentials",
+			nil)).To(BeTrue())
+		Expect(references.RoleInUse("arn:aws:iam::123456789012:role/other-openshift-ingress-operator-cloud-credentials",
+			iamTags("rosa_cluster_id", "live-cluster"))).To(BeTrue())
+		Expect(references.RoleInUse("arn:aws:iam::123456789012:role/old-openshift-ingress-operator-cloud-credentials",
+			iamTags("rosa_cluster_id", "deleted-cluster"))).To(BeFalse())
+	})
+
+	It("finds the OIDC providers in use by issuer or cluster ID tag", func() {
+		Expect(references.OIDCProviderInUse("oidc.example.com/live-cluster", nil)).To(BeTrue())
+		Expect(references.OIDCProviderInUse("oidc.example.com/registered", nil)).To(BeTrue())
+		Expect(references.OIDCProviderInUse("oidc.example.com/other", iamTags("rosa_cluster_id", "live-cluster"))).
+			To(BeTrue())
+		Expect(references.OIDCProviderInUse("oidc.example.com/deleted", iamTags("rosa_cluster_id", "deleted"))).
+			To(BeFalse())
+	})
+})
+
+var _ = Describe("ROSA resources", func() {
+	It("recognizes operator roles by name and tags", func() {
+		operatorTags := iamTags("operator_namespace", "openshift-ingress-operator", "operator_name", "cloud-credentials")
+		Expect(IsOperatorRole("prefix-openshift-ingress-operator-cloud-credentials", operatorTags)).To(BeTrue())
+		Expect(IsOperatorRole("prefix-kube-system-kube-controller-manager", operatorTags)).To(BeTrue())
+		Expect(IsOperatorRole("prefix-openshift-ingress-operator-cloud-credentials", nil)).To(BeFalse())
+		Expect(IsOperatorRole("prefix-Installer-Role", operatorTags)).To(BeFalse())
+	})
+
+	It("recognizes the OIDC providers created by ROSA", func() {
+		Expect(IsROSAOIDCProvider(iamTags("red-hat-managed", "true"))).To(BeTrue())
+		Expect(IsROSAOIDCProvider(iamTags("rosa_cluster_id", "abc"))).To(BeTrue())
+		Expect(IsROSAOIDCProvider(iamTags("owner", "someone"))).To(BeFalse())
+	})
+})
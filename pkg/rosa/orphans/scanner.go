@@ -0,0 +1,275 @@
+package orphans
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	"github.com/openshift-online/ocm-common/pkg/aws/validations"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const (
+	// DefaultMinAge is the age under which resources are never reported, they may belong to a cluster whose
+	// creation is in progress
+	DefaultMinAge = 24 * time.Hour
+
+	listPageSize        = 100
+	awsManagedPolicyARN = "arn:aws:iam::aws:policy/"
+)
+
+// OrphanRole is an operator role that no live cluster uses
+type OrphanRole struct {
+	Name string
+	ARN  string
+	// ClusterID is the value of the cluster ID tag, empty for roles shared by prefix
+	ClusterID string
+	// Managed tells if the policies of the role are managed by AWS, see validations.IsManagedRole
+	Managed    bool
+	CreateDate time.Time
+}
+
+// OrphanOIDCProvider is an IAM OIDC provider that no live cluster nor registered OIDC configuration uses
+type OrphanOIDCProvider struct {
+	ARN        string
+	IssuerURL  string
+	ClusterID  string
+	CreateDate time.Time
+}
+
+// OrphanReport lists the orphans found by a scan
+type OrphanReport struct {
+	Roles         []OrphanRole
+	OIDCProviders []OrphanOIDCProvider
+	// Skipped are the unused resources younger than the minimum age
+	Skipped []string
+	// RolePrefix and IssuerPrefix are the scope of the scan, Delete refuses to delete orphans out of a scope
+	RolePrefix   string
+	IssuerPrefix string
+}
+
+// Scanner finds the operator roles and the OIDC providers of an AWS account that outlived their clusters
+type Scanner struct {
+	AWSClient *aws_client.AWSClient
+	OCMClient *cmv1.Client
+	// RolePrefix restricts the scan to the operator roles named '<prefix>-...' when set
+	RolePrefix string
+	// IssuerPrefix restricts the scan to the OIDC providers whose issuer URL starts with it when set
+	IssuerPrefix string
+	MinAge       time.Duration
+}
+
+// NewScanner creates a scanner of the account of the AWS client, cross-referencing the resources with the clusters
+// and OIDC configurations visible to the OCM client.
+//
+// The scanner only knows the clusters of the OCM environment and organization of the client: when the account is
+// shared with other environments, like stage and production, or other organizations, their roles and OIDC providers
+// are reported as orphans. Restrict the scan to the resources of the environment with Prefix and IssuerPrefix,
+// Delete refuses to delete the roles of a scan without role prefix and the OIDC providers of a scan without issuer
+// prefix.
+func NewScanner(awsClient *aws_client.AWSClient, ocmClient *cmv1.Client) *Scanner {
+	return &Scanner{
+		AWSClient: awsClient,
+		OCMClient: ocmClient,
+		MinAge:    DefaultMinAge,
+	}
+}
+
+// Prefix restricts the scan to the operator roles with the prefix
+func (s *Scanner) Prefix(rolePrefix string) *Scanner {
+	s.RolePrefix = rolePrefix
+	return s
+}
+
+// OIDCIssuerPrefix restricts the scan to the OIDC providers whose issuer URL starts with the prefix, like the
+// issuer host of the OCM environment
+func (s *Scanner) OIDCIssuerPrefix(issuerPrefix string) *Scanner {
+	s.IssuerPrefix = issuerPrefix
+	return s
+}
+
+// MinimumAge sets the age under which resources are never reported
+func (s *Scanner) MinimumAge(minAge time.Duration) *Scanner {
+	s.MinAge = minAge
+	return s
+}
+
+// Scan lists the live clusters and OIDC configurations in OCM and reports the operator roles and ROSA OIDC
+// providers none of them uses. Nothing is reported if any listing fails, so that an incomplete view of OCM can't
+// turn used resources into orphans.
+func (s *Scanner) Scan(ctx context.Context) (*OrphanReport, error) {
+	references, err := s.fetchReferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &OrphanReport{
+		RolePrefix:   s.RolePrefix,
+		IssuerPrefix: s.IssuerPrefix,
+	}
+	now := time.Now()
+
+	roles, err := s.AWSClient.ListRoles()
+	if err != nil {
+		log.LogError("List roles failed: %s", err)
+		return nil, err
+	}
+	for _, role := range roles {
+		roleName := aws.ToString(role.RoleName)
+		if s.RolePrefix != "" && !strings.HasPrefix(roleName, s.RolePrefix+"-") {
+			continue
+		}
+		if !looksLikeOperatorRole(roleName) {
+			continue
+		}
+		tags, err := s.AWSClient.ListRoleTags(roleName)
+		if err != nil {
+			log.LogError("List tags of role %s failed: %s", roleName, err)
+			return nil, err
+		}
+		if !IsOperatorRole(roleName, tags) || references.RoleInUse(aws.ToString(role.Arn), tags) {
+			continue
+		}
+		if tooRecent(role.CreateDate, s.MinAge, now) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("role %s is unused but younger than %s",
+				roleName, s.MinAge))
+			continue
+		}
+		report.Roles = append(report.Roles, OrphanRole{
+			Name:       roleName,
+			ARN:        aws.ToString(role.Arn),
+			ClusterID:  tagValue(tags, validations.ClusterIDTag),
+			Managed:    validations.IsManagedRole(tags),
+			CreateDate: aws.ToTime(role.CreateDate),
+		})
+	}
+
+	providers, err := s.AWSClient.ListOIDCProviders()
+	if err != nil {
+		log.LogError("List OIDC providers failed: %s", err)
+		return nil, err
+	}
+	for _, entry := range providers {
+		providerARN := aws.ToString(entry.Arn)
+		provider, err := s.AWSClient.GetOIDCProvider(providerARN)
+		if err != nil {
+			log.LogError("Get OIDC provider %s failed: %s", providerARN, err)
+			return nil, err
+		}
+		issuerURL := aws.ToString(provider.Url)
+		if s.IssuerPrefix != "" && !strings.HasPrefix(normalizeIssuerURL(issuerURL),
+			normalizeIssuerURL(s.IssuerPrefix)) {
+			continue
+		}
+		if !IsROSAOIDCProvider(provider.Tags) || references.OIDCProviderInUse(issuerURL, provider.Tags) {
+			continue
+		}
+		if tooRecent(provider.CreateDate, s.MinAge, now) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("OIDC provider %s is unused but younger than %s",
+				issuerURL, s.MinAge))
+			continue
+		}
+		report.OIDCProviders = append(report.OIDCProviders, OrphanOIDCProvider{
+			ARN:        providerARN,
+			IssuerURL:  issuerURL,
+			ClusterID:  tagValue(provider.Tags, validations.ClusterIDTag),
+			CreateDate: aws.ToTime(provider.CreateDate),
+		})
+	}
+	log.LogInfo("Found %d orphan operator roles and %d orphan OIDC providers", len(report.Roles),
+		len(report.OIDCProviders))
+	return report, nil
+}
+
+// Delete deletes the orphans of the report. The policies of the roles are detached; a policy is deleted only
+// when the role doesn't use managed policies, the policy is tagged for the same cluster and nothing else uses
+// it, as unmanaged operator policies are otherwise shared by all the roles with the same prefix.
+// The deletion continues on failures and the errors are returned together.
+// Nothing is deleted when the report has roles but no role prefix or OIDC providers but no issuer prefix, as the
+// resources of other environments sharing the account would be deleted, see NewScanner.
+func (s *Scanner) Delete(report *OrphanReport) error {
+	if len(report.Roles) > 0 && report.RolePrefix == "" {
+		return fmt.Errorf("refusing to delete the orphan roles of a scan without role prefix, " +
+			"the roles of other environments sharing the account would be deleted")
+	}
+	if len(report.OIDCProviders) > 0 && report.IssuerPrefix == "" {
+		return fmt.Errorf("refusing to delete the orphan OIDC providers of a scan without issuer prefix, " +
+			"the OIDC providers of other environments sharing the account would be deleted")
+	}
+	failures := []string{}
+	for _, role := range report.Roles {
+		if err := s.deleteRole(role); err != nil {
+			log.LogError("Delete orphan role %s failed: %s", role.Name, err)
+			failures = append(failures, fmt.Sprintf("role %s: %v", role.Name, err))
+		}
+	}
+	for _, provider := range report.OIDCProviders {
+		log.LogInfo("Deleting orphan OIDC provider %s", provider.IssuerURL)
+		if err := s.AWSClient.DeleteOIDCProvider(provider.ARN); err != nil {
+			log.LogError("Delete orphan OIDC provider %s failed: %s", provider.IssuerURL, err)
+			failures = append(failures, fmt.Sprintf("OIDC provider %s: %v", provider.IssuerURL, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to delete orphans: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (s *Scanner) deleteRole(role OrphanRole) error {
+	log.LogInfo("Deleting orphan operator role %s", role.Name)
+	policies, err := s.AWSClient.ListAttachedRolePolicies(role.Name)
+	if err != nil {
+		return err
+	}
+	for _, attached := range policies {
+		policyARN := aws.ToString(attached.PolicyArn)
+		if err = s.AWSClient.DetachIAMPolicy(role.Name, policyARN); err != nil {
+			return err
+		}
+		if role.Managed || role.ClusterID == "" || strings.HasPrefix(policyARN, awsManagedPolicyARN) {
+			continue
+		}
+		policy, err := s.AWSClient.GetIAMPolicy(policyARN)
+		if err != nil {
+			return err
+		}
+		if aws.ToInt32(policy.AttachmentCount) > 0 || tagValue(policy.Tags, validations.ClusterIDTag) != role.ClusterID {
+			continue
+		}
+		if err = s.AWSClient.DeletePolicy(policyARN); err != nil {
+			return err
+		}
+	}
+	return s.AWSClient.DeleteRole(role.Name)
+}
+
+func (s *Scanner) fetchReferences(ctx context.Context) (*References, error) {
+	clusters := []*cmv1.Cluster{}
+	for page := 1; ; page++ {
+		resp, err := s.OCMClient.Clusters().List().Page(page).Size(listPageSize).SendContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clusters: %v", err)
+		}
+		clusters = append(clusters, resp.Items().Slice()...)
+		if resp.Size() < listPageSize {
+			break
+		}
+	}
+	oidcConfigs := []*cmv1.OidcConfig{}
+	for page := 1; ; page++ {
+		resp, err := s.OCMClient.OidcConfigs().List().Page(page).Size(listPageSize).SendContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list OIDC configurations: %v", err)
+		}
+		oidcConfigs = append(oidcConfigs, resp.Items().Slice()...)
+		if resp.Size() < listPageSize {
+			break
+		}
+	}
+	return NewReferences(clusters, oidcConfigs), nil
+}
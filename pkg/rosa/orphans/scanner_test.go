@@ -0,0 +1,42 @@
+package orphans_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/openshift-online/ocm-common/pkg/rosa/orphans"
+)
+
+var _ = Describe("Orphan scanner", func() {
+	var scanner *Scanner
+
+	BeforeEach(func() {
+		scanner = NewScanner(nil, nil)
+	})
+
+	It("refuses to delete the roles of a scan without role prefix", func() {
+		err := scanner.Delete(&OrphanReport{
+			Roles:        []OrphanRole{{Name: "stage-openshift-ingress-operator-cloud-credentials"}},
+			IssuerPrefix: "oidc.example.com",
+		})
+		Expect(err).To(MatchError(ContainSubstring("scan without role prefix")))
+	})
+
+	It("refuses to delete the OIDC providers of a scan without issuer prefix", func() {
+		err := scanner.Delete(&OrphanReport{
+			OIDCProviders: []OrphanOIDCProvider{{IssuerURL: "oidc.example.com/stage-cluster"}},
+			RolePrefix:    "ci",
+		})
+		Expect(err).To(MatchError(ContainSubstring("scan without issuer prefix")))
+	})
+
+	It("deletes nothing for an empty report", func() {
+		Expect(scanner.Delete(&OrphanReport{})).To(Succeed())
+	})
+
+	It("keeps the scope of the scan", func() {
+		scanner.Prefix("ci").OIDCIssuerPrefix("https://oidc.example.com/")
+		Expect(scanner.RolePrefix).To(Equal("ci"))
+		Expect(scanner.IssuerPrefix).To(Equal("https://oidc.example.com/"))
+	})
+})
@@ -0,0 +1,209 @@
+package probes
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	awsUtils "github.com/openshift-online/ocm-common/pkg/aws/utils"
+	"github.com/openshift-online/ocm-common/pkg/log"
+	"github.com/openshift-online/ocm-common/pkg/rosa/accountroles"
+	"github.com/openshift-online/ocm-common/pkg/rosa/operatorroles"
+)
+
+const (
+	defaultSessionName = "ocm-common-probe"
+	probePlaintextSize = 32
+)
+
+// Prober checks that roles are usable for real, catching what static policy checks miss, like service control
+// policies or missing KMS key grants. Each role trusting the caller is assumed and, when a KMS key is set, the
+// key is used as that role.
+type Prober struct {
+	AWSClient   *aws_client.AWSClient
+	KMSKeyARN   string
+	SessionName string
+}
+
+// NewProber creates a prober acting with the credentials of the AWS client
+func NewProber(awsClient *aws_client.AWSClient) *Prober {
+	return &Prober{
+		AWSClient:   awsClient,
+		SessionName: defaultSessionName,
+	}
+}
+
+// KMSKey sets the KMS key to probe as each role
+func (p *Prober) KMSKey(keyARN string) *Prober {
+	p.KMSKeyARN = keyARN
+	return p
+}
+
+// Session sets the session name used to assume the roles, to find the probes in CloudTrail
+func (p *Prober) Session(sessionName string) *Prober {
+	p.SessionName = sessionName
+	return p
+}
+
+// ProbeCluster probes the account and operator roles of the cluster. When no KMS key is set the cluster one,
+// if any, is probed, the prober is left unchanged so that it can probe other clusters.
+func (p *Prober) ProbeCluster(ctx context.Context, cluster *cmv1.Cluster) (*ProbeReport, error) {
+	keyARN := p.KMSKeyARN
+	if keyARN == "" {
+		keyARN = cluster.AWS().KMSKeyArn()
+	}
+	roleARNs := []string{}
+	for _, roles := range []map[string]string{
+		accountroles.GetAccountRolesArnsMap(cluster),
+		operatorroles.GetOperatorRolesArnsMap(cluster),
+	} {
+		for _, roleARN := range roles {
+			if roleARN != "" {
+				roleARNs = append(roleARNs, roleARN)
+			}
+		}
+	}
+	sort.Strings(roleARNs)
+	return p.probe(ctx, keyARN, roleARNs)
+}
+
+// Probe assumes each of the roles trusting the caller and runs the KMS probes as that role. An error is only
+// returned when the caller identity can't be found, the issues of the roles are reported in the results.
+func (p *Prober) Probe(ctx context.Context, roleARNs ...string) (*ProbeReport, error) {
+	return p.probe(ctx, p.KMSKeyARN, roleARNs)
+}
+
+func (p *Prober) probe(ctx context.Context, keyARN string, roleARNs []string) (*ProbeReport, error) {
+	identity, err := p.AWSClient.GetCallerIdentity()
+	if err != nil {
+		return nil, err
+	}
+	callerARN := aws.ToString(identity.Arn)
+	report := &ProbeReport{}
+	for _, roleARN := range roleARNs {
+		p.probeRole(ctx, report, keyARN, callerARN, roleARN)
+	}
+	log.LogInfo("Probed %d roles, %d actions failed", len(roleARNs), len(report.Failed()))
+	return report, nil
+}
+
+func (p *Prober) probeRole(ctx context.Context, report *ProbeReport, keyARN string, callerARN string,
+	roleARN string) {
+	roleName, err := roleNameFromARN(roleARN)
+	if err != nil {
+		report.add(roleARN, ActionAssumeRole, err)
+		return
+	}
+	role, err := p.AWSClient.GetRole(roleName)
+	if err != nil {
+		report.add(roleARN, ActionAssumeRole, fmt.Errorf("failed to get role: %v", err))
+		return
+	}
+	trustPolicy, err := awsUtils.DecodePolicyDocument(aws.ToString(role.AssumeRolePolicyDocument))
+	if err == nil {
+		var trusted bool
+		trusted, err = IsTrusted(trustPolicy, callerARN)
+		if err == nil && !trusted {
+			report.skip(roleARN, ActionAssumeRole, "the role doesn't trust %s", callerARN)
+			return
+		}
+	}
+	if err != nil {
+		report.add(roleARN, ActionAssumeRole, err)
+		return
+	}
+
+	creds, err := p.AWSClient.AssumeRole(roleARN, p.SessionName, 0)
+	report.add(roleARN, ActionAssumeRole, err)
+	if err != nil || keyARN == "" {
+		return
+	}
+	kmsClient := kms.NewFromConfig(p.AWSClient.ConfigWithCredentials(creds))
+	p.probeKMSKey(ctx, report, kmsClient, keyARN, roleARN)
+}
+
+// probeKMSKey runs a data key generation, an encrypt/decrypt round trip and a dry-run grant creation
+func (p *Prober) probeKMSKey(ctx context.Context, report *ProbeReport, kmsClient *kms.Client, keyARN string,
+	roleARN string) {
+	_, err := kmsClient.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(keyARN),
+		KeySpec: kmstypes.DataKeySpecAes256,
+	})
+	report.add(roleARN, ActionGenerateDataKey, err)
+
+	plaintext := make([]byte, probePlaintextSize)
+	if _, err := rand.Read(plaintext); err != nil {
+		report.add(roleARN, ActionEncrypt, err)
+		return
+	}
+	encrypted, err := kmsClient.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(keyARN),
+		Plaintext: plaintext,
+	})
+	report.add(roleARN, ActionEncrypt, err)
+	if err != nil {
+		report.skip(roleARN, ActionDecrypt, "nothing to decrypt as encryption failed")
+	} else {
+		decrypted, err := kmsClient.Decrypt(ctx, &kms.DecryptInput{
+			KeyId:          aws.String(keyARN),
+			CiphertextBlob: encrypted.CiphertextBlob,
+		})
+		if err == nil && !bytes.Equal(decrypted.Plaintext, plaintext) {
+			err = fmt.Errorf("decrypted data doesn't match the encrypted one")
+		}
+		report.add(roleARN, ActionDecrypt, err)
+	}
+
+	report.add(roleARN, ActionCreateGrant, p.probeCreateGrant(ctx, kmsClient, keyARN, roleARN))
+}
+
+// probeCreateGrant creates a grant in dry-run mode, which succeeds with a DryRunOperationException. A grant
+// created anyway is revoked.
+func (p *Prober) probeCreateGrant(ctx context.Context, kmsClient *kms.Client, keyARN string,
+	roleARN string) error {
+	output, err := kmsClient.CreateGrant(ctx, &kms.CreateGrantInput{
+		KeyId:            aws.String(keyARN),
+		GranteePrincipal: aws.String(roleARN),
+		Operations:       []kmstypes.GrantOperation{kmstypes.GrantOperationDecrypt},
+		DryRun:           aws.Bool(true),
+	})
+	var dryRun *kmstypes.DryRunOperationException
+	if errors.As(err, &dryRun) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if output.GrantId == nil {
+		return nil
+	}
+	grantID := aws.ToString(output.GrantId)
+	log.LogWarning("Grant %s was created on %s despite the dry-run, revoking it", grantID, keyARN)
+	revokeInput := &kms.RevokeGrantInput{KeyId: aws.String(keyARN), GrantId: output.GrantId}
+	if _, err := kmsClient.RevokeGrant(ctx, revokeInput); err != nil {
+		if _, err := p.AWSClient.KmsClient.RevokeGrant(ctx, revokeInput); err != nil {
+			return fmt.Errorf("failed to revoke the grant %s created by the probe: %v", grantID, err)
+		}
+	}
+	return nil
+}
+
+func roleNameFromARN(roleARN string) (string, error) {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil || !strings.HasPrefix(parsed.Resource, "role/") {
+		return "", fmt.Errorf("Invalid role ARN '%s'", roleARN)
+	}
+	parts := strings.Split(parsed.Resource, "/")
+	return parts[len(parts)-1], nil
+}
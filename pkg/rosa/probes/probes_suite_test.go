@@ -0,0 +1,13 @@
+package probes
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestProbes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Probes Suite")
+}
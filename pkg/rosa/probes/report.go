@@ -0,0 +1,86 @@
+package probes
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ActionAssumeRole      = "sts:AssumeRole"
+	ActionGenerateDataKey = "kms:GenerateDataKey"
+	ActionEncrypt         = "kms:Encrypt"
+	ActionDecrypt         = "kms:Decrypt"
+	ActionCreateGrant     = "kms:CreateGrant"
+)
+
+// ProbeResult is the outcome of an action attempted as a role. Skipped actions were not attempted, the reason
+// tells why.
+type ProbeResult struct {
+	RoleARN string
+	Action  string
+	Skipped bool
+	Reason  string
+	Err     error
+}
+
+// Passed tells if the action was attempted and succeeded
+func (r ProbeResult) Passed() bool {
+	return !r.Skipped && r.Err == nil
+}
+
+func (r ProbeResult) String() string {
+	switch {
+	case r.Skipped:
+		return fmt.Sprintf("SKIP %s %s: %s", r.RoleARN, r.Action, r.Reason)
+	case r.Err != nil:
+		return fmt.Sprintf("FAIL %s %s: %s", r.RoleARN, r.Action, r.Err)
+	}
+	return fmt.Sprintf("PASS %s %s", r.RoleARN, r.Action)
+}
+
+// ProbeReport gathers the results of the probes, in the order they were run
+type ProbeReport struct {
+	Results []ProbeResult
+}
+
+func (r *ProbeReport) add(roleARN string, action string, err error) {
+	r.Results = append(r.Results, ProbeResult{RoleARN: roleARN, Action: action, Err: err})
+}
+
+func (r *ProbeReport) skip(roleARN string, action string, format string, args ...interface{}) {
+	r.Results = append(r.Results, ProbeResult{
+		RoleARN: roleARN,
+		Action:  action,
+		Skipped: true,
+		Reason:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Failed returns the results of the actions that were attempted and failed
+func (r *ProbeReport) Failed() []ProbeResult {
+	failed := []ProbeResult{}
+	for _, result := range r.Results {
+		if !result.Skipped && result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// ByRole returns the results grouped by role ARN
+func (r *ProbeReport) ByRole() map[string][]ProbeResult {
+	byRole := map[string][]ProbeResult{}
+	for _, result := range r.Results {
+		byRole[result.RoleARN] = append(byRole[result.RoleARN], result)
+	}
+	return byRole
+}
+
+// String returns a line per result
+func (r *ProbeReport) String() string {
+	lines := []string{}
+	for _, result := range r.Results {
+		lines = append(lines, result.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,27 @@
+package probes
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProbeReport", func() {
+	It("reports the results per role and action", func() {
+		report := &ProbeReport{}
+		report.add("role-a", ActionAssumeRole, nil)
+		report.add("role-a", ActionEncrypt, errors.New("AccessDenied"))
+		report.skip("role-a", ActionDecrypt, "nothing to decrypt")
+		report.skip("role-b", ActionAssumeRole, "not trusted")
+
+		Expect(report.Failed()).To(HaveLen(1))
+		Expect(report.Failed()[0].Action).To(Equal(ActionEncrypt))
+		Expect(report.ByRole()).To(HaveLen(2))
+		Expect(report.ByRole()["role-a"]).To(HaveLen(3))
+		Expect(report.String()).To(Equal("PASS role-a sts:AssumeRole\n" +
+			"FAIL role-a kms:Encrypt: AccessDenied\n" +
+			"SKIP role-a kms:Decrypt: nothing to decrypt\n" +
+			"SKIP role-b sts:AssumeRole: not trusted"))
+	})
+})
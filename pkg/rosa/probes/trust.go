@@ -0,0 +1,128 @@
+package probes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// assumeRoleActions are the trust policy actions that allow sts:AssumeRole
+var assumeRoleActions = []string{"sts:AssumeRole", "sts:*", "*"}
+
+// principal is the identity the probes run as, as it can appear in the trust policies
+type principal struct {
+	arn       string
+	partition string
+	account   string
+	roleName  string
+}
+
+// parsePrincipal parses the ARN returned by GetCallerIdentity. For an assumed role session the role name is kept,
+// as trust policies reference the role and not the session.
+func parsePrincipal(callerArn string) (*principal, error) {
+	parsed, err := arn.Parse(callerArn)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid caller ARN '%s': %v", callerArn, err)
+	}
+	p := &principal{
+		arn:       callerArn,
+		partition: parsed.Partition,
+		account:   parsed.AccountID,
+	}
+	if parsed.Service == "sts" && strings.HasPrefix(parsed.Resource, "assumed-role/") {
+		parts := strings.Split(parsed.Resource, "/")
+		if len(parts) >= 2 {
+			p.roleName = parts[1]
+		}
+	}
+	return p, nil
+}
+
+// matches tells if a principal of a trust policy designates this identity. Roles are matched by name so that
+// the paths of the roles, which are not part of the session ARNs, are ignored.
+func (p *principal) matches(trusted string) bool {
+	rootArn := fmt.Sprintf("arn:%s:iam::%s:root", p.partition, p.account)
+	if trusted == "*" || trusted == p.arn || trusted == p.account || trusted == rootArn {
+		return true
+	}
+	if p.roleName == "" {
+		return false
+	}
+	rolePrefix := fmt.Sprintf("arn:%s:iam::%s:role/", p.partition, p.account)
+	return strings.HasPrefix(trusted, rolePrefix) &&
+		(strings.TrimPrefix(trusted, rolePrefix) == p.roleName || strings.HasSuffix(trusted, "/"+p.roleName))
+}
+
+// IsTrusted tells if the trust policy document allows the caller to assume the role. Only the AWS principals
+// of the statements are considered, the conditions are not evaluated, so assuming the role can still be denied.
+func IsTrusted(trustPolicyDocument string, callerArn string) (bool, error) {
+	caller, err := parsePrincipal(callerArn)
+	if err != nil {
+		return false, err
+	}
+	policy := struct {
+		Statement json.RawMessage
+	}{}
+	if err := json.Unmarshal([]byte(trustPolicyDocument), &policy); err != nil {
+		return false, fmt.Errorf("failed to parse trust policy: %v", err)
+	}
+	statements := []map[string]interface{}{}
+	if err := json.Unmarshal(policy.Statement, &statements); err != nil {
+		statement := map[string]interface{}{}
+		if err := json.Unmarshal(policy.Statement, &statement); err != nil {
+			return false, fmt.Errorf("failed to parse trust policy statements: %v", err)
+		}
+		statements = append(statements, statement)
+	}
+
+	for _, statement := range statements {
+		if statement["Effect"] != "Allow" || !containsAny(stringList(statement["Action"]), assumeRoleActions) {
+			continue
+		}
+		for _, trusted := range trustedAWSPrincipals(statement["Principal"]) {
+			if caller.matches(trusted) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func trustedAWSPrincipals(value interface{}) []string {
+	switch principals := value.(type) {
+	case string:
+		return []string{principals}
+	case map[string]interface{}:
+		return stringList(principals["AWS"])
+	}
+	return nil
+}
+
+func stringList(value interface{}) []string {
+	switch values := value.(type) {
+	case string:
+		return []string{values}
+	case []interface{}:
+		list := []string{}
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func containsAny(values []string, expected []string) bool {
+	for _, value := range values {
+		for _, e := range expected {
+			if strings.EqualFold(value, e) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package probes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	userARN         = "arn:aws:iam::123456789012:user/probe"
+	assumedRoleARN  = "arn:aws:sts::123456789012:assumed-role/ci-runner/session-1"
+	otherAccountARN = "arn:aws:iam::210987654321:user/probe"
+)
+
+func trustPolicy(principal string) string {
+	return `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"Federated": "arn:aws:iam::123456789012:oidc-provider/example.com"},
+				"Action": "sts:AssumeRoleWithWebIdentity"
+			},
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": ` + principal + `},
+				"Action": ["sts:AssumeRole"]
+			}
+		]
+	}`
+}
+
+var _ = Describe("IsTrusted", func() {
+	DescribeTable("evaluates the AWS principals of the trust policy",
+		func(document string, callerARN string, expected bool) {
+			trusted, err := IsTrusted(document, callerARN)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trusted).To(Equal(expected))
+		},
+		Entry("same user", trustPolicy(`"`+userARN+`"`), userARN, true),
+		Entry("account root", trustPolicy(`"arn:aws:iam::123456789012:root"`), userARN, true),
+		Entry("account ID", trustPolicy(`["123456789012"]`), userARN, true),
+		Entry("other account", trustPolicy(`"arn:aws:iam::123456789012:root"`), otherAccountARN, false),
+		Entry("assumed role", trustPolicy(`"arn:aws:iam::123456789012:role/ci-runner"`), assumedRoleARN, true),
+		Entry("assumed role with path", trustPolicy(`"arn:aws:iam::123456789012:role/ci/ci-runner"`),
+			assumedRoleARN, true),
+		Entry("other role", trustPolicy(`"arn:aws:iam::123456789012:role/ci-runner-2"`), assumedRoleARN, false),
+		Entry("federated only", `{"Statement": {"Effect": "Allow", "Action": "sts:AssumeRoleWithWebIdentity",
+			"Principal": {"Federated": "example.com"}}}`, userARN, false),
+		Entry("denied", `{"Statement": {"Effect": "Deny", "Action": "sts:AssumeRole",
+			"Principal": {"AWS": "*"}}}`, userARN, false),
+	)
+
+	It("fails on invalid input", func() {
+		_, err := IsTrusted("{", userARN)
+		Expect(err).To(HaveOccurred())
+		_, err = IsTrusted(trustPolicy(`"*"`), "probe")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("roleNameFromARN", func() {
+	It("returns the role name without the path", func() {
+		Expect(roleNameFromARN("arn:aws:iam::123456789012:role/prefix/path/my-role")).To(Equal("my-role"))
+		Expect(roleNameFromARN("arn:aws:iam::123456789012:role/my-role")).To(Equal("my-role"))
+		_, err := roleNameFromARN("arn:aws:iam::123456789012:user/probe")
+		Expect(err).To(HaveOccurred())
+	})
+})
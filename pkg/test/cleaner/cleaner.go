@@ -0,0 +1,258 @@
+package cleaner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// serviceLinkedRolePath is the path of the roles owned by AWS services, they can't be deleted as other roles
+const serviceLinkedRolePath = "/aws-service-role/"
+
+// Cleaner selects the IAM resources of an account with a rule and deletes them once the plan is reviewed:
+//
+//	cleaner := NewCleaner(awsClient)
+//	plan, err := cleaner.Plan(And(OlderThan(7*duration_parser.Day), NamePrefix("ci-"), Unattached()))
+//	fmt.Println(plan)
+//	err = cleaner.Execute(plan)
+type Cleaner struct {
+	AWSClient     *aws_client.AWSClient
+	ResourceTypes []ResourceType
+}
+
+// NewCleaner creates a cleaner of the policies, roles and instance profiles of the account
+func NewCleaner(awsClient *aws_client.AWSClient) *Cleaner {
+	return &Cleaner{
+		AWSClient:     awsClient,
+		ResourceTypes: AllResourceTypes,
+	}
+}
+
+// Types restricts the types of resources listed
+func (c *Cleaner) Types(resourceTypes ...ResourceType) *Cleaner {
+	c.ResourceTypes = resourceTypes
+	return c
+}
+
+// Plan lists the resources and returns the ones the rule selects. The failures to list a type of resource are
+// reported in the plan errors.
+func (c *Cleaner) Plan(rule Rule) (*Plan, error) {
+	if c.AWSClient == nil {
+		return nil, fmt.Errorf("the cleaner has no AWS client")
+	}
+	resources := []*Resource{}
+	listErrors := map[string]error{}
+	for _, resourceType := range c.ResourceTypes {
+		listed, err := c.list(resourceType)
+		if err != nil {
+			log.LogError("List %s resources failed: %s", resourceType, err)
+			listErrors[string(resourceType)] = err
+			continue
+		}
+		resources = append(resources, listed...)
+	}
+	plan := BuildPlan(rule, resources, time.Now().UTC())
+	for key, err := range listErrors {
+		plan.addError(key, err)
+	}
+	log.LogInfo("Cleanup plan for rule %s selects %d of %d resources", plan.Rule, len(plan.Resources),
+		len(resources))
+	return plan, nil
+}
+
+func (c *Cleaner) list(resourceType ResourceType) ([]*Resource, error) {
+	resources := []*Resource{}
+	switch resourceType {
+	case ResourceTypePolicy:
+		policies, err := c.AWSClient.ListCustomerIAMPolicies()
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			resources = append(resources, c.withLoaders(policyResource(policy)))
+		}
+	case ResourceTypeRole:
+		roles, err := c.AWSClient.ListRoles()
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if strings.HasPrefix(aws.ToString(role.Path), serviceLinkedRolePath) {
+				continue
+			}
+			resources = append(resources, c.withLoaders(roleResource(role)))
+		}
+	case ResourceTypeInstanceProfile:
+		instanceProfiles, err := c.AWSClient.ListInstanceProfiles("")
+		if err != nil {
+			return nil, err
+		}
+		for _, instanceProfile := range instanceProfiles {
+			resources = append(resources, c.withLoaders(instanceProfileResource(instanceProfile)))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported resource type %s", resourceType)
+	}
+	return resources, nil
+}
+
+func policyResource(policy iamtypes.Policy) *Resource {
+	return NewResource(ResourceTypePolicy, aws.ToString(policy.PolicyName), aws.ToString(policy.Arn),
+		aws.ToTime(policy.CreateDate)).SetAttachmentCount(int(aws.ToInt32(policy.AttachmentCount)))
+}
+
+func roleResource(role iamtypes.Role) *Resource {
+	return NewResource(ResourceTypeRole, aws.ToString(role.RoleName), aws.ToString(role.Arn),
+		aws.ToTime(role.CreateDate))
+}
+
+func instanceProfileResource(instanceProfile iamtypes.InstanceProfile) *Resource {
+	resource := NewResource(ResourceTypeInstanceProfile, aws.ToString(instanceProfile.InstanceProfileName),
+		aws.ToString(instanceProfile.Arn), aws.ToTime(instanceProfile.CreateDate)).
+		SetAttachmentCount(len(instanceProfile.Roles))
+	roleARNs := []string{}
+	for _, role := range instanceProfile.Roles {
+		roleARNs = append(roleARNs, aws.ToString(role.Arn))
+	}
+	resource.lastUsedRoleARNs = roleARNs
+	return resource
+}
+
+// withLoaders sets the functions loading the information of the resource from AWS
+func (c *Cleaner) withLoaders(resource *Resource) *Resource {
+	switch resource.Type {
+	case ResourceTypePolicy:
+		resource.LoadTagsWith(func() (map[string]string, error) {
+			return tagsMap(c.AWSClient.ListPolicyTags(resource.ARN))
+		})
+		resource.LoadLastUsedWith(func() (*time.Time, error) {
+			return c.AWSClient.GetLastAccessedTime(resource.ARN)
+		})
+	case ResourceTypeRole:
+		resource.LoadTagsWith(func() (map[string]string, error) {
+			return tagsMap(c.AWSClient.ListRoleTags(resource.Name))
+		})
+		resource.LoadAttachmentCountWith(func() (int, error) {
+			instanceProfiles, err := c.AWSClient.ListInstanceProfilesForRole(resource.Name)
+			return len(instanceProfiles), err
+		})
+		resource.LoadLastUsedWith(func() (*time.Time, error) {
+			return c.AWSClient.GetLastAccessedTime(resource.ARN)
+		})
+	case ResourceTypeInstanceProfile:
+		resource.LoadTagsWith(func() (map[string]string, error) {
+			return tagsMap(c.AWSClient.GetTagsOfInstanceProfile(resource.Name))
+		})
+		// an instance profile is used through its roles
+		resource.LoadLastUsedWith(func() (*time.Time, error) {
+			var lastUsed *time.Time
+			for _, roleARN := range resource.lastUsedRoleARNs {
+				roleLastUsed, err := c.AWSClient.GetLastAccessedTime(roleARN)
+				if err != nil {
+					return nil, err
+				}
+				if roleLastUsed != nil && (lastUsed == nil || roleLastUsed.After(*lastUsed)) {
+					lastUsed = roleLastUsed
+				}
+			}
+			return lastUsed, nil
+		})
+	}
+	return resource
+}
+
+// PolicyCleanRule adapts the rule to the policy filters of aws_client, like CleanPolicies. The tags and the last
+// used time are loaded with the AWS client when the rule asks for them. A policy whose information can't be loaded,
+// or whose rule needs information while the client is nil, is kept.
+func PolicyCleanRule(awsClient *aws_client.AWSClient, r Rule) func(iamtypes.Policy) bool {
+	return func(policy iamtypes.Policy) bool {
+		resource := policyResource(policy)
+		if awsClient != nil {
+			NewCleaner(awsClient).withLoaders(resource)
+		} else {
+			unavailable := fmt.Errorf("no AWS client to load the information of policy %s", resource.Name)
+			resource.LoadTagsWith(func() (map[string]string, error) {
+				return nil, unavailable
+			})
+			resource.LoadLastUsedWith(func() (*time.Time, error) {
+				return nil, unavailable
+			})
+		}
+		matched := r.Match(resource, time.Now().UTC())
+		if err := resource.Err(); err != nil {
+			log.LogWarning("Keep policy %s as rule %s can't be evaluated: %s", resource.Name, r, err)
+			return false
+		}
+		return matched
+	}
+}
+
+func tagsMap(tags []iamtypes.Tag, err error) (map[string]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for _, tag := range tags {
+		result[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return result, nil
+}
+
+// Execute deletes the resources of the plan: the instance profiles first, then the roles with their inline
+// policies, then the policies, so that the policies attached to the deleted roles are free. A policy still attached
+// to a role not in the plan is kept. The failures don't stop the execution, they are all returned.
+func (c *Cleaner) Execute(plan *Plan) error {
+	if c.AWSClient == nil {
+		return fmt.Errorf("the cleaner has no AWS client")
+	}
+	byType := plan.ByType()
+	var errs []error
+	for _, resource := range byType[ResourceTypeInstanceProfile] {
+		errs = append(errs, c.delete(resource, func() error {
+			return c.AWSClient.DeleteInstanceProfile(resource.Name)
+		}))
+	}
+	for _, resource := range byType[ResourceTypeRole] {
+		errs = append(errs, c.delete(resource, func() error {
+			if err := c.AWSClient.DeleteRoleInstanceProfiles(resource.Name); err != nil {
+				return err
+			}
+			if err := c.AWSClient.DetachRolePolicies(resource.Name); err != nil {
+				return err
+			}
+			if err := c.AWSClient.DeleteRoleInlinePolicies(resource.Name); err != nil {
+				return err
+			}
+			return c.AWSClient.DeleteRole(resource.Name)
+		}))
+	}
+	for _, resource := range byType[ResourceTypePolicy] {
+		errs = append(errs, c.delete(resource, func() error {
+			policy, err := c.AWSClient.GetIAMPolicy(resource.ARN)
+			if err != nil {
+				return err
+			}
+			if attachments := aws.ToInt32(policy.AttachmentCount); attachments > 0 {
+				return fmt.Errorf("still attached to %d entities", attachments)
+			}
+			return c.AWSClient.DeletePolicy(resource.ARN)
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Cleaner) delete(resource *Resource, deleteFunc func() error) error {
+	if err := deleteFunc(); err != nil {
+		log.LogError("Delete %s %s failed: %s", resource.Type, resource.Name, err)
+		return fmt.Errorf("failed to delete %s %s: %v", resource.Type, resource.Name, err)
+	}
+	log.LogInfo("Deleted %s %s", resource.Type, resource.Name)
+	return nil
+}
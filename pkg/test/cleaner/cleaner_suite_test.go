@@ -0,0 +1,13 @@
+package cleaner_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCleaner(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cleaner Suite")
+}
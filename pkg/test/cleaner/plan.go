@@ -0,0 +1,138 @@
+package cleaner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Plan lists the resources a rule selects, to be reviewed before it is executed
+type Plan struct {
+	Rule        string
+	GeneratedAt time.Time
+	Resources   []*Resource
+	// Errors are the failures to evaluate a resource by ARN, or to list a type of resource by type. Those resources
+	// are kept.
+	Errors map[string]error
+}
+
+// BuildPlan evaluates the rule on the resources
+func BuildPlan(rule Rule, resources []*Resource, now time.Time) *Plan {
+	plan := newPlan(rule, now)
+	for _, resource := range resources {
+		matched := rule.Match(resource, now)
+		if err := resource.Err(); err != nil {
+			plan.addError(resource.ARN, err)
+			continue
+		}
+		if matched {
+			plan.Resources = append(plan.Resources, resource)
+		}
+	}
+	return plan
+}
+
+func newPlan(rule Rule, now time.Time) *Plan {
+	return &Plan{
+		Rule:        rule.String(),
+		GeneratedAt: now,
+		Resources:   []*Resource{},
+		Errors:      map[string]error{},
+	}
+}
+
+func (p *Plan) addError(key string, err error) {
+	if err != nil {
+		p.Errors[key] = err
+	}
+}
+
+// Empty tells if the plan cleans nothing
+func (p *Plan) Empty() bool {
+	return len(p.Resources) == 0
+}
+
+// ByType returns the resources of the plan grouped by resource type
+func (p *Plan) ByType() map[ResourceType][]*Resource {
+	byType := map[ResourceType][]*Resource{}
+	for _, resource := range p.Resources {
+		byType[resource.Type] = append(byType[resource.Type], resource)
+	}
+	return byType
+}
+
+// String returns a human readable plan, with the resources grouped by resource type and sorted by name
+func (p *Plan) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "Cleanup plan for rule %s: %d resources", p.Rule, len(p.Resources))
+
+	byType := p.ByType()
+	types := []string{}
+	for resourceType := range byType {
+		types = append(types, string(resourceType))
+	}
+	sort.Strings(types)
+	for _, resourceType := range types {
+		resources := byType[ResourceType(resourceType)]
+		sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+		fmt.Fprintf(builder, "\n  %s (%d):", resourceType, len(resources))
+		for _, resource := range resources {
+			fmt.Fprintf(builder, "\n    - %s [%s]", resource.Name, resourceDetails(resource))
+		}
+	}
+
+	keys := []string{}
+	for key := range p.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(builder, "\n  failed to evaluate %s: %v", key, p.Errors[key])
+	}
+	return builder.String()
+}
+
+// resourceDetails describes the resource with the information loaded to evaluate it
+func resourceDetails(resource *Resource) string {
+	details := []string{resource.ARN, "created " + resource.CreateDate.UTC().Format(time.RFC3339)}
+	if resource.attachmentCount != nil {
+		details = append(details, fmt.Sprintf("attachments %d", *resource.attachmentCount))
+	}
+	if len(resource.tags) > 0 {
+		tags := []string{}
+		for key, value := range resource.tags {
+			tags = append(tags, key+"="+value)
+		}
+		sort.Strings(tags)
+		details = append(details, "tags "+strings.Join(tags, ","))
+	}
+	if resource.lastUsedLoaded {
+		lastUsed := "never"
+		if resource.lastUsed != nil {
+			lastUsed = resource.lastUsed.UTC().Format(time.RFC3339)
+		}
+		details = append(details, "last used "+lastUsed)
+	}
+	return strings.Join(details, ", ")
+}
+
+// MarshalJSON gives the plan with the errors as messages
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	errs := map[string]string{}
+	for key, err := range p.Errors {
+		errs[key] = err.Error()
+	}
+	return json.Marshal(struct {
+		Rule        string            `json:"rule"`
+		GeneratedAt time.Time         `json:"generated_at"`
+		Resources   []*Resource       `json:"resources"`
+		Errors      map[string]string `json:"errors,omitempty"`
+	}{
+		Rule:        p.Rule,
+		GeneratedAt: p.GeneratedAt,
+		Resources:   p.Resources,
+		Errors:      errs,
+	})
+}
@@ -0,0 +1,106 @@
+package cleaner_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-common/pkg/test/cleaner"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/duration_parser"
+)
+
+var _ = Describe("Plan", func() {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * duration_parser.Day)
+
+	var resources []*cleaner.Resource
+
+	newResource := func(resourceType cleaner.ResourceType, name string) *cleaner.Resource {
+		arn := fmt.Sprintf("arn:aws:iam::123456789012:%s/%s", resourceType, name)
+		return cleaner.NewResource(resourceType, name, arn, created)
+	}
+
+	BeforeEach(func() {
+		lastUsed := now.Add(-20 * duration_parser.Day)
+		failing := newResource(cleaner.ResourceTypeRole, "ci-failing")
+		failing.LoadLastUsedWith(func() (*time.Time, error) {
+			return nil, fmt.Errorf("access denied")
+		})
+		resources = []*cleaner.Resource{
+			newResource(cleaner.ResourceTypePolicy, "ci-policy-b").
+				SetAttachmentCount(0),
+			newResource(cleaner.ResourceTypePolicy, "ci-policy-a").
+				SetAttachmentCount(0).SetTags(map[string]string{"owner": "ci"}),
+			newResource(cleaner.ResourceTypeRole, "ci-role").
+				SetLastUsed(&lastUsed),
+			newResource(cleaner.ResourceTypeRole, "prod-role"),
+			failing,
+		}
+	})
+
+	ciUnused := cleaner.And(cleaner.NamePrefix("ci-"), cleaner.UnusedFor(7*duration_parser.Day))
+
+	It("selects the resources matching the rule", func() {
+		plan := cleaner.BuildPlan(ciUnused, resources, now)
+		Expect(plan.Rule).To(Equal("(name starts with 'ci-' AND unused for 7d)"))
+		Expect(plan.GeneratedAt).To(Equal(now))
+		Expect(plan.Empty()).To(BeFalse())
+		names := []string{}
+		for _, resource := range plan.Resources {
+			names = append(names, resource.Name)
+		}
+		Expect(names).To(Equal([]string{"ci-policy-b", "ci-policy-a", "ci-role"}))
+		Expect(plan.ByType()[cleaner.ResourceTypeRole]).To(HaveLen(1))
+	})
+
+	It("keeps the resources that failed to be evaluated", func() {
+		plan := cleaner.BuildPlan(cleaner.NamePrefix("ci-"), resources, now)
+		Expect(plan.Errors).To(BeEmpty())
+
+		plan = cleaner.BuildPlan(cleaner.UnusedFor(7*duration_parser.Day), resources, now)
+		Expect(plan.Errors).To(HaveKey("arn:aws:iam::123456789012:role/ci-failing"))
+		for _, resource := range plan.Resources {
+			Expect(resource.Name).ToNot(Equal("ci-failing"))
+		}
+	})
+
+	It("is empty when nothing matches", func() {
+		Expect(cleaner.BuildPlan(cleaner.Or(), resources, now).Empty()).To(BeTrue())
+	})
+
+	It("prints the plan for review", func() {
+		plan := cleaner.BuildPlan(ciUnused, resources, now)
+		Expect(plan.String()).To(Equal(
+			"Cleanup plan for rule (name starts with 'ci-' AND unused for 7d): 3 resources\n" +
+				"  policy (2):\n" +
+				"    - ci-policy-a [arn:aws:iam::123456789012:policy/ci-policy-a, created 2024-06-05T12:00:00Z, " +
+				"attachments 0, tags owner=ci, last used never]\n" +
+				"    - ci-policy-b [arn:aws:iam::123456789012:policy/ci-policy-b, created 2024-06-05T12:00:00Z, " +
+				"attachments 0, last used never]\n" +
+				"  role (1):\n" +
+				"    - ci-role [arn:aws:iam::123456789012:role/ci-role, created 2024-06-05T12:00:00Z, " +
+				"last used 2024-05-26T12:00:00Z]\n" +
+				"  failed to evaluate arn:aws:iam::123456789012:role/ci-failing: access denied"))
+	})
+
+	It("marshals the plan to JSON", func() {
+		plan := cleaner.BuildPlan(cleaner.And(cleaner.NamePrefix("ci-role"), cleaner.UnusedFor(7*duration_parser.Day)),
+			resources, now)
+		data, err := json.Marshal(plan)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"rule": "(name starts with 'ci-role' AND unused for 7d)",
+			"generated_at": "2024-06-15T12:00:00Z",
+			"resources": [{
+				"type": "role",
+				"name": "ci-role",
+				"arn": "arn:aws:iam::123456789012:role/ci-role",
+				"create_date": "2024-06-05T12:00:00Z",
+				"last_used": "2024-05-26T12:00:00Z"
+			}]
+		}`))
+	})
+})
@@ -0,0 +1,156 @@
+package cleaner
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ResourceType is the kind of IAM resource the cleaner selects
+type ResourceType string
+
+const (
+	ResourceTypePolicy          ResourceType = "policy"
+	ResourceTypeRole            ResourceType = "role"
+	ResourceTypeInstanceProfile ResourceType = "instance profile"
+)
+
+// AllResourceTypes are the types the cleaner lists by default
+var AllResourceTypes = []ResourceType{ResourceTypeInstanceProfile, ResourceTypeRole, ResourceTypePolicy}
+
+// Resource is an IAM resource evaluated by the rules. The information that costs an API call per resource, like the
+// tags or the last used time, is only loaded when a rule asks for it.
+type Resource struct {
+	Type       ResourceType
+	Name       string
+	ARN        string
+	CreateDate time.Time
+
+	attachmentCount       *int
+	attachmentCountLoader func() (int, error)
+	tags                  map[string]string
+	tagsLoader            func() (map[string]string, error)
+	lastUsed              *time.Time
+	lastUsedLoaded        bool
+	lastUsedLoader        func() (*time.Time, error)
+	lastUsedRoleARNs      []string
+	errs                  []error
+}
+
+// NewResource creates a resource, the setters give its information and the loaders the functions loading it when a
+// rule asks for it
+func NewResource(resourceType ResourceType, name string, arn string, createDate time.Time) *Resource {
+	return &Resource{
+		Type:       resourceType,
+		Name:       name,
+		ARN:        arn,
+		CreateDate: createDate,
+	}
+}
+
+func (r *Resource) SetAttachmentCount(count int) *Resource {
+	r.attachmentCount = &count
+	return r
+}
+
+func (r *Resource) SetTags(tags map[string]string) *Resource {
+	r.tags = tags
+	return r
+}
+
+// SetLastUsed sets the last time the resource was used, nil when it was never used
+func (r *Resource) SetLastUsed(lastUsed *time.Time) *Resource {
+	r.lastUsed = lastUsed
+	r.lastUsedLoaded = true
+	return r
+}
+
+// LoadAttachmentCountWith sets the function loading the attachment count when it isn't set
+func (r *Resource) LoadAttachmentCountWith(loader func() (int, error)) *Resource {
+	r.attachmentCountLoader = loader
+	return r
+}
+
+// LoadTagsWith sets the function loading the tags when they aren't set
+func (r *Resource) LoadTagsWith(loader func() (map[string]string, error)) *Resource {
+	r.tagsLoader = loader
+	return r
+}
+
+// LoadLastUsedWith sets the function loading the last used time when it isn't set
+func (r *Resource) LoadLastUsedWith(loader func() (*time.Time, error)) *Resource {
+	r.lastUsedLoader = loader
+	return r
+}
+
+// AttachmentCount returns the number of entities the policy is attached to, the number of instance profiles the role
+// is in or the number of roles in the instance profile
+func (r *Resource) AttachmentCount() int {
+	if r.attachmentCount == nil && r.attachmentCountLoader != nil {
+		count, err := r.attachmentCountLoader()
+		r.addError(err)
+		r.attachmentCount = &count
+	}
+	if r.attachmentCount == nil {
+		return 0
+	}
+	return *r.attachmentCount
+}
+
+// Tags returns the tags of the resource
+func (r *Resource) Tags() map[string]string {
+	if r.tags == nil && r.tagsLoader != nil {
+		tags, err := r.tagsLoader()
+		r.addError(err)
+		r.tags = tags
+	}
+	if r.tags == nil {
+		r.tags = map[string]string{}
+	}
+	return r.tags
+}
+
+// LastUsed returns the last time the resource was used to access an AWS service, as reported by the IAM access
+// advisor. It is nil when the resource was never used in the tracking period.
+func (r *Resource) LastUsed() *time.Time {
+	if !r.lastUsedLoaded && r.lastUsedLoader != nil {
+		lastUsed, err := r.lastUsedLoader()
+		r.addError(err)
+		r.lastUsed = lastUsed
+	}
+	r.lastUsedLoaded = true
+	return r.lastUsed
+}
+
+// Err returns the failures to load the information of the resource. The rules can't be trusted for a resource with
+// errors, so the cleaner keeps it.
+func (r *Resource) Err() error {
+	return errors.Join(r.errs...)
+}
+
+func (r *Resource) addError(err error) {
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
+
+// MarshalJSON gives the information of the resource, without loading the one no rule asked for
+func (r *Resource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type            ResourceType      `json:"type"`
+		Name            string            `json:"name"`
+		ARN             string            `json:"arn"`
+		CreateDate      time.Time         `json:"create_date"`
+		AttachmentCount *int              `json:"attachment_count,omitempty"`
+		Tags            map[string]string `json:"tags,omitempty"`
+		LastUsed        *time.Time        `json:"last_used,omitempty"`
+	}{
+		Type:            r.Type,
+		Name:            r.Name,
+		ARN:             r.ARN,
+		CreateDate:      r.CreateDate,
+		AttachmentCount: r.attachmentCount,
+		Tags:            r.tags,
+		LastUsed:        r.lastUsed,
+	})
+}
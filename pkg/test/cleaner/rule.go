@@ -0,0 +1,175 @@
+package cleaner
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/duration_parser"
+)
+
+// Rule selects the resources to clean. The description is shown in the plans, so that the reviewers know why a
+// resource is selected.
+type Rule interface {
+	Match(resource *Resource, now time.Time) bool
+	String() string
+}
+
+type rule struct {
+	description string
+	match       func(resource *Resource, now time.Time) bool
+}
+
+func (r *rule) Match(resource *Resource, now time.Time) bool {
+	return r.match(resource, now)
+}
+
+func (r *rule) String() string {
+	return r.description
+}
+
+// NewRule creates a custom rule
+func NewRule(description string, match func(resource *Resource, now time.Time) bool) Rule {
+	return &rule{description: description, match: match}
+}
+
+// OfType selects the resources of the types
+func OfType(resourceTypes ...ResourceType) Rule {
+	names := []string{}
+	for _, resourceType := range resourceTypes {
+		names = append(names, string(resourceType))
+	}
+	return NewRule("type is "+strings.Join(names, " or "), func(resource *Resource, _ time.Time) bool {
+		for _, resourceType := range resourceTypes {
+			if resource.Type == resourceType {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// OlderThan selects the resources created more than age ago
+func OlderThan(age time.Duration) Rule {
+	return NewRule("older than "+duration_parser.FormatDuration(age), func(resource *Resource, now time.Time) bool {
+		return resource.CreateDate.Add(age).Before(now)
+	})
+}
+
+// NamePrefix selects the resources whose name starts with the prefix
+func NamePrefix(prefix string) Rule {
+	return NewRule(fmt.Sprintf("name starts with '%s'", prefix), func(resource *Resource, _ time.Time) bool {
+		return strings.HasPrefix(resource.Name, prefix)
+	})
+}
+
+// NameContains selects the resources whose name contains the substring
+func NameContains(substring string) Rule {
+	return NewRule(fmt.Sprintf("name contains '%s'", substring), func(resource *Resource, _ time.Time) bool {
+		return strings.Contains(resource.Name, substring)
+	})
+}
+
+// NameGlob selects the resources whose name matches the shell pattern, like `ci-*-role`. An invalid pattern
+// matches nothing.
+func NameGlob(pattern string) Rule {
+	return NewRule(fmt.Sprintf("name matches '%s'", pattern), func(resource *Resource, _ time.Time) bool {
+		matched, err := path.Match(pattern, resource.Name)
+		return err == nil && matched
+	})
+}
+
+// NameRegexp selects the resources whose name matches the regular expression
+func NameRegexp(expression *regexp.Regexp) Rule {
+	return NewRule(fmt.Sprintf("name matches /%s/", expression), func(resource *Resource, _ time.Time) bool {
+		return expression.MatchString(resource.Name)
+	})
+}
+
+// HasTag selects the resources with the tag. Any value matches when value is empty.
+func HasTag(key string, value string) Rule {
+	description := fmt.Sprintf("tagged %s=%s", key, value)
+	if value == "" {
+		description = fmt.Sprintf("tagged %s", key)
+	}
+	return NewRule(description, func(resource *Resource, _ time.Time) bool {
+		tagValue, found := resource.Tags()[key]
+		return found && (value == "" || tagValue == value)
+	})
+}
+
+// AttachmentCountAtMost selects the resources with at most count attachments, see Resource.AttachmentCount
+func AttachmentCountAtMost(count int) Rule {
+	return NewRule(fmt.Sprintf("at most %d attachments", count), func(resource *Resource, _ time.Time) bool {
+		return resource.AttachmentCount() <= count
+	})
+}
+
+// Unattached selects the resources without attachment
+func Unattached() Rule {
+	return NewRule("unattached", func(resource *Resource, _ time.Time) bool {
+		return resource.AttachmentCount() == 0
+	})
+}
+
+// UnusedFor selects the resources not used for the duration. A resource never used is selected when it is older
+// than the duration, so that the resources just created are kept.
+func UnusedFor(duration time.Duration) Rule {
+	return NewRule("unused for "+duration_parser.FormatDuration(duration), func(resource *Resource, now time.Time) bool {
+		since := resource.CreateDate
+		if lastUsed := resource.LastUsed(); lastUsed != nil {
+			since = *lastUsed
+		}
+		return since.Add(duration).Before(now)
+	})
+}
+
+// And selects the resources matching all the rules, it matches everything without rules. The rules are evaluated in
+// order and the evaluation stops at the first one not matching, so the rules loading information from AWS, like
+// UnusedFor, should come last.
+func And(rules ...Rule) Rule {
+	return NewRule(combine("AND", rules, "everything"), func(resource *Resource, now time.Time) bool {
+		for _, r := range rules {
+			if !r.Match(resource, now) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or selects the resources matching any of the rules, it matches nothing without rules. The evaluation stops at the
+// first rule matching.
+func Or(rules ...Rule) Rule {
+	return NewRule(combine("OR", rules, "nothing"), func(resource *Resource, now time.Time) bool {
+		for _, r := range rules {
+			if r.Match(resource, now) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not selects the resources not matching the rule
+func Not(r Rule) Rule {
+	return NewRule(fmt.Sprintf("NOT %s", r), func(resource *Resource, now time.Time) bool {
+		return !r.Match(resource, now)
+	})
+}
+
+func combine(operator string, rules []Rule, empty string) string {
+	if len(rules) == 0 {
+		return empty
+	}
+	descriptions := []string{}
+	for _, r := range rules {
+		descriptions = append(descriptions, r.String())
+	}
+	if len(descriptions) == 1 {
+		return descriptions[0]
+	}
+	return "(" + strings.Join(descriptions, " "+operator+" ") + ")"
+}
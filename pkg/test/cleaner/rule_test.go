@@ -0,0 +1,164 @@
+package cleaner_test
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-common/pkg/test/cleaner"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/duration_parser"
+)
+
+var _ = Describe("Rules", func() {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	newPolicy := func(name string, age time.Duration) *cleaner.Resource {
+		return cleaner.NewResource(cleaner.ResourceTypePolicy, name, "arn:aws:iam::123456789012:policy/"+name,
+			now.Add(-age))
+	}
+
+	It("selects the resources by type", func() {
+		rule := cleaner.OfType(cleaner.ResourceTypeRole, cleaner.ResourceTypeInstanceProfile)
+		Expect(rule.String()).To(Equal("type is role or instance profile"))
+		Expect(rule.Match(newPolicy("ci-policy", time.Hour), now)).To(BeFalse())
+		role := cleaner.NewResource(cleaner.ResourceTypeRole, "ci-role", "arn:aws:iam::123456789012:role/ci-role", now)
+		Expect(rule.Match(role, now)).To(BeTrue())
+	})
+
+	It("selects the resources by age", func() {
+		rule := cleaner.OlderThan(7 * duration_parser.Day)
+		Expect(rule.String()).To(Equal("older than 7d"))
+		Expect(rule.Match(newPolicy("ci-policy", 8*duration_parser.Day), now)).To(BeTrue())
+		Expect(rule.Match(newPolicy("ci-policy", 6*duration_parser.Day), now)).To(BeFalse())
+	})
+
+	DescribeTable("selects the resources by name",
+		func(rule cleaner.Rule, description string, name string, expected bool) {
+			Expect(rule.String()).To(Equal(description))
+			Expect(rule.Match(newPolicy(name, time.Hour), now)).To(Equal(expected))
+		},
+		Entry("prefix", cleaner.NamePrefix("sdq-ci-"), "name starts with 'sdq-ci-'", "sdq-ci-policy", true),
+		Entry("other prefix", cleaner.NamePrefix("sdq-ci-"), "name starts with 'sdq-ci-'", "prod-policy", false),
+		Entry("substring", cleaner.NameContains("-ci-"), "name contains '-ci-'", "sdq-ci-policy", true),
+		Entry("glob", cleaner.NameGlob("ci-*-policy"), "name matches 'ci-*-policy'", "ci-abc-policy", true),
+		Entry("other glob", cleaner.NameGlob("ci-*-policy"), "name matches 'ci-*-policy'", "ci-abc-role", false),
+		Entry("invalid glob", cleaner.NameGlob("ci-["), "name matches 'ci-['", "ci-[", false),
+		Entry("regexp", cleaner.NameRegexp(regexp.MustCompile(`^ci-[0-9]+$`)), "name matches /^ci-[0-9]+$/", "ci-42",
+			true),
+		Entry("other regexp", cleaner.NameRegexp(regexp.MustCompile(`^ci-[0-9]+$`)), "name matches /^ci-[0-9]+$/",
+			"ci-abc", false),
+	)
+
+	It("selects the resources by tag", func() {
+		resource := newPolicy("ci-policy", time.Hour).SetTags(map[string]string{"owner": "ci"})
+		Expect(cleaner.HasTag("owner", "ci").Match(resource, now)).To(BeTrue())
+		Expect(cleaner.HasTag("owner", "").Match(resource, now)).To(BeTrue())
+		Expect(cleaner.HasTag("owner", "qe").Match(resource, now)).To(BeFalse())
+		Expect(cleaner.HasTag("team", "").Match(resource, now)).To(BeFalse())
+		Expect(cleaner.HasTag("owner", "ci").String()).To(Equal("tagged owner=ci"))
+		Expect(cleaner.HasTag("owner", "").String()).To(Equal("tagged owner"))
+	})
+
+	It("selects the resources by attachment count", func() {
+		resource := newPolicy("ci-policy", time.Hour).SetAttachmentCount(2)
+		Expect(cleaner.AttachmentCountAtMost(2).Match(resource, now)).To(BeTrue())
+		Expect(cleaner.AttachmentCountAtMost(1).Match(resource, now)).To(BeFalse())
+		Expect(cleaner.Unattached().Match(resource, now)).To(BeFalse())
+		Expect(cleaner.Unattached().Match(newPolicy("ci-policy", time.Hour).SetAttachmentCount(0), now)).To(BeTrue())
+	})
+
+	It("selects the resources by last used time", func() {
+		rule := cleaner.UnusedFor(30 * duration_parser.Day)
+		Expect(rule.String()).To(Equal("unused for 30d"))
+
+		lastUsed := now.Add(-40 * duration_parser.Day)
+		Expect(rule.Match(newPolicy("ci-policy", 90*duration_parser.Day).SetLastUsed(&lastUsed), now)).To(BeTrue())
+		lastUsed = now.Add(-duration_parser.Day)
+		Expect(rule.Match(newPolicy("ci-policy", 90*duration_parser.Day).SetLastUsed(&lastUsed), now)).To(BeFalse())
+
+		By("keeping the resources never used but just created")
+		Expect(rule.Match(newPolicy("ci-policy", 90*duration_parser.Day).SetLastUsed(nil), now)).To(BeTrue())
+		Expect(rule.Match(newPolicy("ci-policy", duration_parser.Day).SetLastUsed(nil), now)).To(BeFalse())
+	})
+
+	It("combines the rules", func() {
+		rule := cleaner.And(
+			cleaner.OlderThan(7*duration_parser.Day),
+			cleaner.Or(cleaner.NamePrefix("sdq-ci-"), cleaner.NamePrefix("ci-")),
+			cleaner.Not(cleaner.HasTag("keep", "")),
+		)
+		Expect(rule.String()).To(Equal(
+			"(older than 7d AND (name starts with 'sdq-ci-' OR name starts with 'ci-') AND NOT tagged keep)"))
+
+		Expect(rule.Match(newPolicy("ci-policy", 8*duration_parser.Day), now)).To(BeTrue())
+		Expect(rule.Match(newPolicy("sdq-ci-policy", 8*duration_parser.Day), now)).To(BeTrue())
+		Expect(rule.Match(newPolicy("prod-policy", 8*duration_parser.Day), now)).To(BeFalse())
+		Expect(rule.Match(newPolicy("ci-policy", duration_parser.Day), now)).To(BeFalse())
+		kept := newPolicy("ci-policy", 8*duration_parser.Day).SetTags(map[string]string{"keep": "true"})
+		Expect(rule.Match(kept, now)).To(BeFalse())
+	})
+
+	It("describes the empty and single combinations", func() {
+		Expect(cleaner.And().String()).To(Equal("everything"))
+		Expect(cleaner.And().Match(newPolicy("ci-policy", time.Hour), now)).To(BeTrue())
+		Expect(cleaner.Or().String()).To(Equal("nothing"))
+		Expect(cleaner.Or().Match(newPolicy("ci-policy", time.Hour), now)).To(BeFalse())
+		Expect(cleaner.And(cleaner.Unattached()).String()).To(Equal("unattached"))
+	})
+
+	It("loads the information only when a rule asks for it", func() {
+		loads := 0
+		resource := newPolicy("prod-policy", time.Hour)
+		resource.LoadTagsWith(func() (map[string]string, error) {
+			loads++
+			return map[string]string{"owner": "ci"}, nil
+		})
+
+		Expect(cleaner.And(cleaner.NamePrefix("ci-"), cleaner.HasTag("owner", "ci")).Match(resource, now)).To(BeFalse())
+		Expect(loads).To(Equal(0))
+
+		Expect(cleaner.And(cleaner.NamePrefix("prod-"), cleaner.HasTag("owner", "ci")).Match(resource, now)).To(BeTrue())
+		Expect(cleaner.HasTag("owner", "").Match(resource, now)).To(BeTrue())
+		Expect(loads).To(Equal(1))
+	})
+
+	It("records the failures to load the information", func() {
+		resource := newPolicy("ci-policy", time.Hour)
+		resource.LoadLastUsedWith(func() (*time.Time, error) {
+			return nil, fmt.Errorf("access denied")
+		})
+		cleaner.UnusedFor(duration_parser.Day).Match(resource, now)
+		Expect(resource.Err()).To(MatchError("access denied"))
+	})
+
+	It("adapts the rules to the policy filters", func() {
+		filter := cleaner.PolicyCleanRule(nil, cleaner.And(cleaner.NamePrefix("sdq-ci-"), cleaner.Unattached()))
+		Expect(filter(iamtypes.Policy{
+			PolicyName:      aws.String("sdq-ci-policy"),
+			Arn:             aws.String("arn:aws:iam::123456789012:policy/sdq-ci-policy"),
+			AttachmentCount: aws.Int32(0),
+		})).To(BeTrue())
+		Expect(filter(iamtypes.Policy{
+			PolicyName:      aws.String("sdq-ci-policy"),
+			Arn:             aws.String("arn:aws:iam::123456789012:policy/sdq-ci-policy"),
+			AttachmentCount: aws.Int32(1),
+		})).To(BeFalse())
+	})
+
+	It("keeps the policies whose rule needs information the filter can't load", func() {
+		policy := iamtypes.Policy{
+			PolicyName: aws.String("sdq-ci-policy"),
+			Arn:        aws.String("arn:aws:iam::123456789012:policy/sdq-ci-policy"),
+			CreateDate: aws.Time(time.Now().Add(-30 * duration_parser.Day)),
+		}
+		Expect(cleaner.PolicyCleanRule(nil, cleaner.And(cleaner.OlderThan(7*duration_parser.Day),
+			cleaner.Not(cleaner.HasTag("keep", ""))))(policy)).To(BeFalse())
+		Expect(cleaner.PolicyCleanRule(nil, cleaner.UnusedFor(7*duration_parser.Day))(policy)).To(BeFalse())
+		Expect(cleaner.PolicyCleanRule(nil, cleaner.OlderThan(7*duration_parser.Day))(policy)).To(BeTrue())
+	})
+})
@@ -0,0 +1,13 @@
+package cluster_client
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestClusterClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cluster Client Suite")
+}
@@ -0,0 +1,174 @@
+package cluster_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	idpUtils "github.com/openshift-online/ocm-common/pkg/idp/utils"
+	"github.com/openshift-online/ocm-common/pkg/log"
+	"github.com/openshift-online/ocm-common/pkg/utils"
+)
+
+const (
+	adminUserNamePrefix = "ocm-admin"
+	adminIDPNamePrefix  = "ocm-htpasswd"
+)
+
+// GetCluster returns the cluster as it is known by OCM
+func (c *ClusterClient) GetCluster(ctx context.Context) (*cmv1.Cluster, error) {
+	resp, err := c.ClustersClient.Cluster(c.ClusterID).Get().SendContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster %s: %v", c.ClusterID, err)
+	}
+	return resp.Body(), nil
+}
+
+// GetAdminKubeconfig returns the admin kubeconfig OCM stored when the cluster was installed.
+// Only clusters installed with admin credentials expose it.
+func (c *ClusterClient) GetAdminKubeconfig(ctx context.Context) (string, error) {
+	resp, err := c.ClustersClient.Cluster(c.ClusterID).Credentials().Get().SendContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials of cluster %s: %v", c.ClusterID, err)
+	}
+	kubeconfig, ok := resp.Body().GetKubeconfig()
+	if !ok || kubeconfig == "" {
+		return "", fmt.Errorf("cluster %s has no admin kubeconfig", c.ClusterID)
+	}
+	return kubeconfig, nil
+}
+
+// CreateBreakGlassKubeconfig creates a break glass credential for a hosted cluster with external authentication
+// and waits until it is issued. The username is generated when it is empty and expiration defaults to OCM's
+// value when it is zero.
+func (c *ClusterClient) CreateBreakGlassKubeconfig(ctx context.Context, username string, expiration time.Duration,
+	timeout time.Duration) (string, error) {
+	if username == "" {
+		username = fmt.Sprintf("%s-%s", adminUserNamePrefix, utils.RandomLabel(5))
+	}
+	builder := cmv1.NewBreakGlassCredential().Username(username)
+	if expiration > 0 {
+		builder = builder.ExpirationTimestamp(time.Now().Add(expiration))
+	}
+	credential, err := builder.Build()
+	if err != nil {
+		return "", err
+	}
+	credentialsClient := c.ClustersClient.Cluster(c.ClusterID).BreakGlassCredentials()
+	resp, err := credentialsClient.Add().Body(credential).SendContext(ctx)
+	if err != nil {
+		log.LogError("Create break glass credential for cluster %s failed: %s", c.ClusterID, err)
+		return "", err
+	}
+	credentialID := resp.Body().ID()
+	log.LogInfo("Created break glass credential %s for user %s on cluster %s", credentialID, username, c.ClusterID)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		getResp, err := credentialsClient.BreakGlassCredential(credentialID).Get().SendContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		credential = getResp.Body()
+		switch credential.Status() {
+		case cmv1.BreakGlassCredentialStatusIssued:
+			return credential.Kubeconfig(), nil
+		case cmv1.BreakGlassCredentialStatusFailed, cmv1.BreakGlassCredentialStatusRevoked,
+			cmv1.BreakGlassCredentialStatusExpired:
+			return "", fmt.Errorf("break glass credential %s is in status %s", credentialID, credential.Status())
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timeout waiting for break glass credential %s to be issued, last status: %s",
+				credentialID, credential.Status())
+		}
+		log.LogDebug("Break glass credential %s is in status %s, waiting", credentialID, credential.Status())
+		if err = sleepWithContext(ctx, c.PollInterval); err != nil {
+			return "", err
+		}
+	}
+}
+
+// RevokeBreakGlassCredentials revokes all the break glass credentials of the cluster
+func (c *ClusterClient) RevokeBreakGlassCredentials(ctx context.Context) error {
+	_, err := c.ClustersClient.Cluster(c.ClusterID).BreakGlassCredentials().Delete().SendContext(ctx)
+	return err
+}
+
+// CreateAdminHTPasswdUser adds an htpasswd identity provider with a single user with a generated password
+// and makes that user a member of the cluster-admins group.
+// If idpName is empty a random one will be generated.
+func (c *ClusterClient) CreateAdminHTPasswdUser(ctx context.Context, idpName string) (*AdminUser, error) {
+	if idpName == "" {
+		idpName = fmt.Sprintf("%s-%s", adminIDPNamePrefix, utils.RandomLabel(5))
+	}
+	username := fmt.Sprintf("%s-%s", adminUserNamePrefix, utils.RandomLabel(5))
+	password, err := idpUtils.GenerateRandomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	idp, err := cmv1.NewIdentityProvider().
+		Type(cmv1.IdentityProviderTypeHtpasswd).
+		Name(idpName).
+		MappingMethod(cmv1.IdentityProviderMappingMethodClaim).
+		Htpasswd(cmv1.NewHTPasswdIdentityProvider().Users(
+			cmv1.NewHTPasswdUserList().Items(
+				cmv1.NewHTPasswdUser().Username(username).Password(password),
+			),
+		)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	clusterResource := c.ClustersClient.Cluster(c.ClusterID)
+	resp, err := clusterResource.IdentityProviders().Add().Body(idp).SendContext(ctx)
+	if err != nil {
+		log.LogError("Create htpasswd identity provider %s for cluster %s failed: %s", idpName, c.ClusterID, err)
+		return nil, err
+	}
+	adminUser := &AdminUser{
+		Username: username,
+		Password: password,
+		IDPID:    resp.Body().ID(),
+		IDPName:  idpName,
+	}
+	log.LogInfo("Created htpasswd identity provider %s with user %s on cluster %s", idpName, username, c.ClusterID)
+
+	user, err := cmv1.NewUser().ID(username).Build()
+	if err != nil {
+		return adminUser, err
+	}
+	_, err = clusterResource.Groups().Group(utils.ClusterAdminGroup).Users().Add().Body(user).SendContext(ctx)
+	if err != nil {
+		log.LogError("Add user %s to group %s failed: %s", username, utils.ClusterAdminGroup, err)
+		return adminUser, err
+	}
+	return adminUser, nil
+}
+
+// DeleteAdminHTPasswdUser removes the user from the cluster-admins group and deletes its identity provider
+func (c *ClusterClient) DeleteAdminHTPasswdUser(ctx context.Context, adminUser *AdminUser) error {
+	clusterResource := c.ClustersClient.Cluster(c.ClusterID)
+	_, err := clusterResource.Groups().Group(utils.ClusterAdminGroup).Users().User(adminUser.Username).Delete().
+		SendContext(ctx)
+	if err != nil {
+		log.LogError("Remove user %s from group %s failed: %s", adminUser.Username, utils.ClusterAdminGroup, err)
+		return err
+	}
+	_, err = clusterResource.IdentityProviders().IdentityProvider(adminUser.IDPID).Delete().SendContext(ctx)
+	if err != nil {
+		log.LogError("Delete identity provider %s failed: %s", adminUser.IDPName, err)
+	}
+	return err
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,214 @@
+package cluster_client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// RESTConfigFromKubeconfig builds a REST config from the content of a kubeconfig file
+func RESTConfigFromKubeconfig(kubeconfig string) (*rest.Config, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	return restConfig, nil
+}
+
+// RESTConfigForUser builds a REST config authenticated with an OAuth token of the user.
+// The htpasswd identity provider takes a while to be synced to the cluster after OCM created it,
+// so the token request is retried until it succeeds or the timeout is reached.
+func (c *ClusterClient) RESTConfigForUser(ctx context.Context, username string, password string,
+	timeout time.Duration) (*rest.Config, error) {
+	cluster, err := c.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := cluster.API().URL()
+	if apiURL == "" {
+		return nil, fmt.Errorf("cluster %s has no API URL yet", c.ClusterID)
+	}
+	token, err := c.WaitForOAuthToken(ctx, apiURL, username, password, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &rest.Config{
+		Host:        apiURL,
+		BearerToken: token,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure: c.InsecureSkipTLSVerify,
+		},
+	}, nil
+}
+
+// AdminRESTConfig returns a REST config with cluster-admin permissions.
+// Clusters using external authentication get a break glass credential, the other ones get a new htpasswd user
+// which is returned so that the caller can delete it with DeleteAdminHTPasswdUser once done.
+func (c *ClusterClient) AdminRESTConfig(ctx context.Context, timeout time.Duration) (*rest.Config, *AdminUser, error) {
+	cluster, err := c.GetCluster(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cluster.ExternalAuthConfig().Enabled() {
+		kubeconfig, err := c.CreateBreakGlassKubeconfig(ctx, "", 0, timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		restConfig, err := RESTConfigFromKubeconfig(kubeconfig)
+		return restConfig, nil, err
+	}
+	adminUser, err := c.CreateAdminHTPasswdUser(ctx, "")
+	if err != nil {
+		return nil, adminUser, err
+	}
+	restConfig, err := c.RESTConfigForUser(ctx, adminUser.Username, adminUser.Password, timeout)
+	return restConfig, adminUser, err
+}
+
+// NewKubernetesClient creates a Kubernetes client from the REST config and waits for the API to answer
+func NewKubernetesClient(ctx context.Context, restConfig *rest.Config, timeout time.Duration) (kubernetes.Interface, error) {
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = WaitForAPI(ctx, client, defaultPollInterval, timeout)
+	return client, err
+}
+
+// WaitForAPI waits until the API server answers to the authenticated version request
+func WaitForAPI(ctx context.Context, client kubernetes.Interface, interval time.Duration, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		version, err := client.Discovery().ServerVersion()
+		if err == nil {
+			log.LogInfo("Cluster API is ready with version %s", version.GitVersion)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for the cluster API to be ready: %v", err)
+		}
+		log.LogDebug("Cluster API is not ready yet: %s", err)
+		if err = sleepWithContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitForOAuthToken waits until the OAuth server of the cluster is reachable and issues a token for the user
+func (c *ClusterClient) WaitForOAuthToken(ctx context.Context, apiURL string, username string, password string,
+	timeout time.Duration) (string, error) {
+	httpClient := newOAuthHTTPClient(c.InsecureSkipTLSVerify)
+	deadline := time.Now().Add(timeout)
+	for {
+		token, err := requestOAuthToken(ctx, httpClient, apiURL, username, password)
+		if err == nil {
+			log.LogInfo("Got OAuth token for user %s", username)
+			return token, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timeout waiting for an OAuth token for user %s: %v", username, err)
+		}
+		log.LogDebug("OAuth token for user %s is not available yet: %s", username, err)
+		if err = sleepWithContext(ctx, c.PollInterval); err != nil {
+			return "", err
+		}
+	}
+}
+
+func newOAuthHTTPClient(insecure bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// #nosec G402 -- only enabled on request for test clusters with self-signed certificates
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+		// The token is returned in the redirect location, so redirects must not be followed
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// oauthMetadata is the subset of the OAuth server metadata published by the API server that we need
+type oauthMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+}
+
+func discoverOAuthAuthorizeEndpoint(ctx context.Context, httpClient *http.Client, apiURL string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		apiURL+"/.well-known/oauth-authorization-server", nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OAuth metadata request returned status %d", response.StatusCode)
+	}
+	metadata := oauthMetadata{}
+	if err = json.NewDecoder(response.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("failed to decode OAuth metadata: %v", err)
+	}
+	if metadata.AuthorizationEndpoint == "" {
+		return "", fmt.Errorf("OAuth metadata has no authorization endpoint")
+	}
+	return metadata.AuthorizationEndpoint, nil
+}
+
+func requestOAuthToken(ctx context.Context, httpClient *http.Client, apiURL string, username string,
+	password string) (string, error) {
+	authorizeEndpoint, err := discoverOAuthAuthorizeEndpoint(ctx, httpClient, apiURL)
+	if err != nil {
+		return "", err
+	}
+	authorizeURL, err := url.Parse(authorizeEndpoint)
+	if err != nil {
+		return "", err
+	}
+	query := authorizeURL.Query()
+	query.Set("response_type", "token")
+	query.Set("client_id", challengingClientID)
+	authorizeURL.RawQuery = query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, authorizeURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	request.SetBasicAuth(username, password)
+	request.Header.Set("X-CSRF-Token", "1")
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusFound {
+		return "", fmt.Errorf("OAuth authorize request returned status %d", response.StatusCode)
+	}
+	location, err := response.Location()
+	if err != nil {
+		return "", err
+	}
+	fragment, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		return "", err
+	}
+	token := fragment.Get("access_token")
+	if token == "" {
+		return "", fmt.Errorf("OAuth redirect has no access token: %s", fragment.Get("error"))
+	}
+	return token, nil
+}
@@ -0,0 +1,64 @@
+package cluster_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newFakeOAuthServer(username string, password string) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer": "%[1]s", "authorization_endpoint": "%[1]s/oauth/authorize"}`, server.URL)
+	})
+	mux.HandleFunc("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if r.URL.Query().Get("client_id") != challengingClientID || r.Header.Get("X-CSRF-Token") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !ok || user != username || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, server.URL+"/oauth/token/implicit#access_token=sha256~token&token_type=Bearer",
+			http.StatusFound)
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+var _ = Describe("OAuth token request", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = newFakeOAuthServer("admin", "secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the access token of the redirect", func() {
+		token, err := requestOAuthToken(context.Background(), newOAuthHTTPClient(false), server.URL, "admin", "secret")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token).To(Equal("sha256~token"))
+	})
+
+	It("fails with wrong credentials", func() {
+		_, err := requestOAuthToken(context.Background(), newOAuthHTTPClient(false), server.URL, "admin", "wrong")
+		Expect(err).To(MatchError("OAuth authorize request returned status 401"))
+	})
+
+	It("fails when the OAuth metadata is not published", func() {
+		emptyServer := httptest.NewServer(http.NotFoundHandler())
+		defer emptyServer.Close()
+		_, err := requestOAuthToken(context.Background(), newOAuthHTTPClient(false), emptyServer.URL, "admin", "secret")
+		Expect(err).To(MatchError("OAuth metadata request returned status 404"))
+	})
+})
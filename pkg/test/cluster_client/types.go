@@ -0,0 +1,49 @@
+package cluster_client
+
+import (
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+const (
+	defaultPollInterval = 10 * time.Second
+
+	// OpenShift OAuth client which answers basic auth challenges instead of redirecting to a login page
+	challengingClientID = "openshift-challenging-client"
+)
+
+// ClusterClient gives access to a cluster provisioned by OCM
+type ClusterClient struct {
+	ClustersClient        *cmv1.ClustersClient
+	ClusterID             string
+	InsecureSkipTLSVerify bool
+	PollInterval          time.Duration
+}
+
+// AdminUser is a cluster-admin user added through an htpasswd identity provider
+type AdminUser struct {
+	Username string
+	Password string
+	IDPID    string
+	IDPName  string
+}
+
+func NewClusterClient(clustersClient *cmv1.ClustersClient, clusterID string) *ClusterClient {
+	return &ClusterClient{
+		ClustersClient: clustersClient,
+		ClusterID:      clusterID,
+		PollInterval:   defaultPollInterval,
+	}
+}
+
+// Insecure disables the TLS verification of the API and OAuth endpoints, useful for clusters with self-signed certificates
+func (c *ClusterClient) Insecure(insecure bool) *ClusterClient {
+	c.InsecureSkipTLSVerify = insecure
+	return c
+}
+
+func (c *ClusterClient) Interval(interval time.Duration) *ClusterClient {
+	c.PollInterval = interval
+	return c
+}
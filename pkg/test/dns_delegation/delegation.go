@@ -0,0 +1,171 @@
+package dns_delegation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const (
+	nsRecordTTL         = 300
+	dnsPort             = "53"
+	propagationInterval = 10 * time.Second
+)
+
+// Delegation is a public hosted zone for a subdomain delegated from a parent hosted zone, which can be owned by
+// another AWS account
+type Delegation struct {
+	// Domain is the fully qualified subdomain, without trailing dot
+	Domain       string
+	HostedZoneID string
+	NameServers  []string
+	ParentZoneID string
+
+	zoneClient   *aws_client.AWSClient
+	parentClient *aws_client.AWSClient
+}
+
+// CreateDelegatedSubdomain creates a public hosted zone for the subdomain of the parent zone, upserts the NS
+// records delegating it in the parent zone and waits until the parent zone authoritative name servers serve the
+// delegation. The subdomain can be a single label, like "ci-abc12", or a fully qualified name under the parent zone.
+// The parent client is the client of the account owning the parent zone; when nil the zone client is used.
+// If any step fails the created resources are removed.
+func CreateDelegatedSubdomain(zoneClient *aws_client.AWSClient, parentClient *aws_client.AWSClient,
+	parentZoneID string, subdomain string, timeout time.Duration) (*Delegation, error) {
+	if parentClient == nil {
+		parentClient = zoneClient
+	}
+	parentZone, err := parentClient.GetHostedZone(parentZoneID)
+	if err != nil {
+		log.LogError("Get parent hosted zone %s failed: %s", parentZoneID, err)
+		return nil, err
+	}
+	if parentZone.HostedZone.Config != nil && parentZone.HostedZone.Config.PrivateZone {
+		return nil, fmt.Errorf("parent hosted zone %s is private, only public zones can delegate subdomains",
+			parentZoneID)
+	}
+	domain := subdomainOf(subdomain, *parentZone.HostedZone.Name)
+
+	callerReference := fmt.Sprintf("%s-%d", domain, time.Now().UnixNano())
+	zone, err := zoneClient.CreateHostedZone(domain, callerReference, "", "", false)
+	if err != nil {
+		return nil, err
+	}
+	delegation := &Delegation{
+		Domain:       domain,
+		HostedZoneID: *zone.HostedZone.Id,
+		NameServers:  zone.DelegationSet.NameServers,
+		ParentZoneID: parentZoneID,
+		zoneClient:   zoneClient,
+		parentClient: parentClient,
+	}
+
+	err = delegation.delegate(timeout)
+	if err == nil {
+		err = delegation.WaitForPropagation(timeout)
+	}
+	if err != nil {
+		if deleteErr := delegation.Delete(timeout); deleteErr != nil {
+			log.LogError("Clean up of the delegation of %s failed: %s", domain, deleteErr)
+		}
+		return nil, err
+	}
+	log.LogInfo("Subdomain %s is delegated to hosted zone %s", domain, delegation.HostedZoneID)
+	return delegation, nil
+}
+
+// delegate upserts the NS records of the subdomain in the parent zone and waits for the change to be in sync
+func (d *Delegation) delegate(timeout time.Duration) error {
+	resp, err := d.parentClient.UpsertNSRecord(d.ParentZoneID, d.Domain, d.NameServers, nsRecordTTL)
+	if err != nil {
+		return err
+	}
+	return d.parentClient.WaitForRecordChangeInSync(*resp.ChangeInfo.Id, timeout)
+}
+
+// WaitForPropagation waits until every authoritative name server of the parent zone answers the NS query for the
+// subdomain with the name servers of the delegated zone
+func (d *Delegation) WaitForPropagation(timeout time.Duration) error {
+	parentZone, err := d.parentClient.GetHostedZone(d.ParentZoneID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for {
+		err = checkDelegation(ctx, parentZone.DelegationSet.NameServers, d.Domain, d.NameServers)
+		if err == nil {
+			return nil
+		}
+		log.LogDebug("Delegation of %s is not propagated yet: %s", d.Domain, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for the delegation of %s to propagate: %v", d.Domain, err)
+		case <-time.After(propagationInterval):
+		}
+	}
+}
+
+// Delete removes the NS records from the parent zone, waits for the change to be in sync and deletes the hosted
+// zone of the subdomain with all its records
+func (d *Delegation) Delete(timeout time.Duration) error {
+	var errs []error
+	record, err := d.parentClient.GetResourceRecordSet(d.ParentZoneID, d.Domain, types.RRTypeNs)
+	if err != nil {
+		errs = append(errs, err)
+	} else if record != nil {
+		resp, err := d.parentClient.ChangeResourceRecordSet(d.ParentZoneID, types.ChangeActionDelete, record)
+		if err != nil {
+			errs = append(errs, err)
+		} else if err = d.parentClient.WaitForRecordChangeInSync(*resp.ChangeInfo.Id, timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		// Keep the zone: deleting it while still delegated would allow the subdomain to be taken over
+		return errors.Join(errs...)
+	}
+
+	if err = d.zoneClient.DeleteHostedZoneRecords(d.HostedZoneID); err != nil {
+		return err
+	}
+	if err = d.zoneClient.DeleteHostedZone(d.HostedZoneID); err != nil {
+		log.LogError("Delete hosted zone %s failed: %s", d.HostedZoneID, err)
+		return err
+	}
+	log.LogInfo("Deleted delegated hosted zone %s of %s", d.HostedZoneID, d.Domain)
+	return nil
+}
+
+func checkDelegation(ctx context.Context, parentNameServers []string, domain string, expected []string) error {
+	if len(parentNameServers) == 0 {
+		return fmt.Errorf("parent zone has no name servers")
+	}
+	for _, server := range parentNameServers {
+		nameServers, err := queryNameServers(ctx, net.JoinHostPort(server, dnsPort), domain)
+		if err != nil {
+			return fmt.Errorf("name server %s: %v", server, err)
+		}
+		if !sameNameServers(nameServers, expected) {
+			return fmt.Errorf("name server %s delegates %s to %v", server, domain, nameServers)
+		}
+	}
+	return nil
+}
+
+func subdomainOf(subdomain string, parentDomain string) string {
+	subdomain = strings.ToLower(strings.TrimSuffix(subdomain, "."))
+	parentDomain = strings.ToLower(strings.TrimSuffix(parentDomain, "."))
+	if strings.HasSuffix(subdomain, "."+parentDomain) {
+		return subdomain
+	}
+	return subdomain + "." + parentDomain
+}
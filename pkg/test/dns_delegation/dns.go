@@ -0,0 +1,100 @@
+package dns_delegation
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const dnsQueryTimeout = 5 * time.Second
+
+// queryNameServers sends a non recursive NS query for the name to the DNS server and returns the name servers
+// found in the answer or, for a referral, in the authority section
+func queryNameServers(ctx context.Context, address string, name string) ([]string, error) {
+	fqdn, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+	id := uint16(rand.Intn(1 << 16)) // #nosec G404
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id},
+		Questions: []dnsmessage.Question{
+			{Name: fqdn, Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET},
+		},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(dnsQueryTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(packet); err != nil {
+		return nil, err
+	}
+	buffer := make([]byte, 4096)
+	n, err := conn.Read(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dnsmessage.Message{}
+	if err = response.Unpack(buffer[:n]); err != nil {
+		return nil, err
+	}
+	if response.ID != id {
+		return nil, fmt.Errorf("unexpected DNS response ID %d", response.ID)
+	}
+	if response.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("DNS query for %s failed with %s", name, response.RCode)
+	}
+	nameServers := []string{}
+	for _, resource := range append(response.Answers, response.Authorities...) {
+		ns, ok := resource.Body.(*dnsmessage.NSResource)
+		if !ok || !strings.EqualFold(resource.Header.Name.String(), fqdn.String()) {
+			continue
+		}
+		nameServers = append(nameServers, ns.NS.String())
+	}
+	return nameServers, nil
+}
+
+// sameNameServers compares two lists of name servers ignoring order, case and trailing dots
+func sameNameServers(nameServers1 []string, nameServers2 []string) bool {
+	normalize := func(nameServers []string) []string {
+		normalized := []string{}
+		for _, nameServer := range nameServers {
+			normalized = append(normalized, strings.ToLower(strings.TrimSuffix(nameServer, ".")))
+		}
+		sort.Strings(normalized)
+		return normalized
+	}
+	normalized1 := normalize(nameServers1)
+	normalized2 := normalize(nameServers2)
+	if len(normalized1) != len(normalized2) {
+		return false
+	}
+	for i := range normalized1 {
+		if normalized1[i] != normalized2[i] {
+			return false
+		}
+	}
+	return true
+}
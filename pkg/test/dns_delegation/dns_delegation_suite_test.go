@@ -0,0 +1,13 @@
+package dns_delegation
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDNSDelegation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DNS Delegation Suite")
+}
@@ -0,0 +1,65 @@
+package dns_delegation
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startFakeDNSServer answers every query with a referral of the queried name to the name servers
+func startFakeDNSServer(nameServers ...string) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	DeferCleanup(conn.Close)
+	go func() {
+		defer GinkgoRecover()
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			query := dnsmessage.Message{}
+			Expect(query.Unpack(buffer[:n])).To(Succeed())
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true},
+				Questions: query.Questions,
+			}
+			for _, nameServer := range nameServers {
+				response.Authorities = append(response.Authorities, dnsmessage.Resource{
+					Header: dnsmessage.ResourceHeader{
+						Name:  query.Questions[0].Name,
+						Type:  dnsmessage.TypeNS,
+						Class: dnsmessage.ClassINET,
+						TTL:   300,
+					},
+					Body: &dnsmessage.NSResource{NS: dnsmessage.MustNewName(nameServer)},
+				})
+			}
+			packet, err := response.Pack()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = conn.WriteTo(packet, addr)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+var _ = Describe("DNS delegation", func() {
+	It("reads the name servers of a referral", func() {
+		address := startFakeDNSServer("ns-1.awsdns-01.org.", "ns-2.awsdns-02.com.")
+		nameServers, err := queryNameServers(context.Background(), address, "ci.example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nameServers).To(Equal([]string{"ns-1.awsdns-01.org.", "ns-2.awsdns-02.com."}))
+		Expect(sameNameServers(nameServers, []string{"NS-2.awsdns-02.com", "ns-1.awsdns-01.org"})).To(BeTrue())
+		Expect(sameNameServers(nameServers, []string{"ns-1.awsdns-01.org"})).To(BeFalse())
+	})
+
+	It("builds the subdomain under the parent zone", func() {
+		Expect(subdomainOf("ci-abc12", "example.com.")).To(Equal("ci-abc12.example.com"))
+		Expect(subdomainOf("CI-abc12.example.com.", "example.com.")).To(Equal("ci-abc12.example.com"))
+	})
+})
@@ -0,0 +1,13 @@
+package forward_proxy
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestForwardProxy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Forward Proxy Suite")
+}
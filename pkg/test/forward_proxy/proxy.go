@@ -0,0 +1,446 @@
+package forward_proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const dialTimeout = 10 * time.Second
+
+// hopHeaders are the headers meaningful only for a single connection, they are not forwarded
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Proxy is an in-process HTTP/HTTPS forward proxy, meant to test proxy-aware clients without launching a
+// proxy instance. It supports basic authentication, CONNECT tunnels, optional TLS interception with a
+// generated CA and domain allow/deny lists. Every request is recorded in the access log.
+type Proxy struct {
+	Username       string
+	Password       string
+	AllowedDomains []string
+	DeniedDomains  []string
+	CA             *CA
+	LogWriter      io.Writer
+
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+	mutex     sync.Mutex
+	accessLog []AccessLogEntry
+}
+
+// AccessLogEntry records a request handled by the proxy
+type AccessLogEntry struct {
+	Time        time.Time
+	ClientAddr  string
+	User        string
+	Method      string
+	Host        string
+	URL         string
+	Status      int
+	Intercepted bool
+	Error       string
+}
+
+// String formats the entry like a proxy access log line
+func (e AccessLogEntry) String() string {
+	user := e.User
+	if user == "" {
+		user = "-"
+	}
+	line := fmt.Sprintf("%s %s %s %s %s %d", e.Time.Format(time.RFC3339), e.ClientAddr, user, e.Method,
+		e.URL, e.Status)
+	if e.Intercepted {
+		line += " intercepted"
+	}
+	if e.Error != "" {
+		line += fmt.Sprintf(" error=%q", e.Error)
+	}
+	return line
+}
+
+// NewProxy creates a proxy without authentication, domain restrictions nor TLS interception
+func NewProxy() *Proxy {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	return &Proxy{
+		transport: transport,
+	}
+}
+
+// BasicAuth requires the clients to authenticate with the user and password
+func (p *Proxy) BasicAuth(username string, password string) *Proxy {
+	p.Username = username
+	p.Password = password
+	return p
+}
+
+// Allow restricts the destinations to the domains and their subdomains
+func (p *Proxy) Allow(domains ...string) *Proxy {
+	p.AllowedDomains = append(p.AllowedDomains, domains...)
+	return p
+}
+
+// Deny rejects the domains and their subdomains, it takes precedence over Allow
+func (p *Proxy) Deny(domains ...string) *Proxy {
+	p.DeniedDomains = append(p.DeniedDomains, domains...)
+	return p
+}
+
+// InterceptTLS decrypts the CONNECT tunnels presenting certificates signed by the CA, so the inner requests
+// are recorded in the access log like plain HTTP ones. The clients must trust the CA.
+func (p *Proxy) InterceptTLS(ca *CA) *Proxy {
+	p.CA = ca
+	return p
+}
+
+// UpstreamTLSConfig sets the TLS configuration used to connect to the destinations of the intercepted requests
+func (p *Proxy) UpstreamTLSConfig(config *tls.Config) *Proxy {
+	p.transport.TLSClientConfig = config
+	return p
+}
+
+// AccessLogWriter writes every access log entry as a line to the writer as well
+func (p *Proxy) AccessLogWriter(writer io.Writer) *Proxy {
+	p.LogWriter = writer
+	return p
+}
+
+// Start listens on a random port of the loopback interface and serves the requests in background
+func (p *Proxy) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+	p.listener = listener
+	p.server = &http.Server{Handler: p}
+	go func() {
+		err := p.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.LogError("Forward proxy stopped: %s", err)
+		}
+	}()
+	log.LogInfo("Forward proxy listening on %s", listener.Addr())
+	return nil
+}
+
+// Close stops the proxy
+func (p *Proxy) Close() error {
+	if p.server == nil {
+		return nil
+	}
+	p.transport.CloseIdleConnections()
+	return p.server.Close()
+}
+
+// Addr returns the address the proxy listens on
+func (p *Proxy) Addr() string {
+	if p.listener == nil {
+		return ""
+	}
+	return p.listener.Addr().String()
+}
+
+// URL returns the URL of the proxy, including the credentials when authentication is required
+func (p *Proxy) URL() *url.URL {
+	proxyURL := &url.URL{Scheme: "http", Host: p.Addr()}
+	if p.Username != "" {
+		proxyURL.User = url.UserPassword(p.Username, p.Password)
+	}
+	return proxyURL
+}
+
+// AccessLog returns a copy of the entries recorded so far
+func (p *Proxy) AccessLog() []AccessLogEntry {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]AccessLogEntry{}, p.accessLog...)
+}
+
+// ServeHTTP implements http.Handler
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	entry := AccessLogEntry{
+		Time:       time.Now(),
+		ClientAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Host:       r.Host,
+		URL:        r.URL.String(),
+	}
+	if r.Method == http.MethodConnect {
+		entry.URL = r.Host
+	}
+	defer func() { p.record(entry) }()
+
+	user, ok := p.authenticate(r)
+	entry.User = user
+	if !ok {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+		entry.Status = http.StatusProxyAuthRequired
+		http.Error(w, "proxy authentication required", entry.Status)
+		return
+	}
+	if !p.Allowed(r.Host) {
+		entry.Status = http.StatusForbidden
+		http.Error(w, fmt.Sprintf("destination '%s' is not allowed", r.Host), entry.Status)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		entry.Status, entry.Error = p.connect(w, r, user)
+		return
+	}
+	if !r.URL.IsAbs() {
+		entry.Status = http.StatusBadRequest
+		http.Error(w, "only absolute URLs can be proxied", entry.Status)
+		return
+	}
+	resp, err := p.roundTrip(r)
+	if err != nil {
+		entry.Status = http.StatusBadGateway
+		entry.Error = err.Error()
+		http.Error(w, err.Error(), entry.Status)
+		return
+	}
+	defer resp.Body.Close()
+	removeHopHeaders(resp.Header)
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	entry.Status = resp.StatusCode
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// Allowed checks the host, with or without port, against the denied and allowed domains
+func (p *Proxy) Allowed(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	for _, domain := range p.DeniedDomains {
+		if matchesDomain(host, domain) {
+			return false
+		}
+	}
+	if len(p.AllowedDomains) == 0 {
+		return true
+	}
+	for _, domain := range p.AllowedDomains {
+		if matchesDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Proxy) authenticate(r *http.Request) (string, bool) {
+	header := r.Header.Get("Proxy-Authorization")
+	user, password, ok := parseBasicAuth(header)
+	if p.Username == "" {
+		return user, true
+	}
+	return user, ok && user == p.Username && password == p.Password
+}
+
+// connect handles a CONNECT request, either tunnelling the connection to the destination or intercepting it.
+// It returns the status sent to the client and the error, if any.
+func (p *Proxy) connect(w http.ResponseWriter, r *http.Request, user string) (int, string) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection hijacking not supported", http.StatusInternalServerError)
+		return http.StatusInternalServerError, "connection hijacking not supported"
+	}
+
+	var upstream net.Conn
+	if p.CA == nil {
+		var err error
+		upstream, err = net.DialTimeout("tcp", r.Host, dialTimeout)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return http.StatusBadGateway, err.Error()
+		}
+	}
+
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		if upstream != nil {
+			upstream.Close()
+		}
+		return http.StatusInternalServerError, err.Error()
+	}
+	_, err = client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	if err != nil {
+		client.Close()
+		if upstream != nil {
+			upstream.Close()
+		}
+		return http.StatusOK, err.Error()
+	}
+
+	if upstream != nil {
+		go tunnel(client, upstream)
+	} else {
+		go p.intercept(client, r.Host, r.RemoteAddr, user)
+	}
+	return http.StatusOK, ""
+}
+
+// intercept terminates the TLS connection of the client with a certificate for the host signed by the CA
+// and forwards each of the requests received to the destination
+func (p *Proxy) intercept(client net.Conn, host string, clientAddr string, user string) {
+	defer client.Close()
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	cert, err := p.CA.certificateFor(hostname)
+	if err != nil {
+		log.LogError("Failed to generate the certificate for %s: %s", hostname, err)
+		return
+	}
+	conn := tls.Server(client, &tls.Config{Certificates: []tls.Certificate{*cert}})
+	defer conn.Close()
+	if err := conn.Handshake(); err != nil {
+		log.LogDebug("TLS handshake with %s for %s failed: %s", clientAddr, host, err)
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		r, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		r.URL.Scheme = "https"
+		r.URL.Host = host
+		entry := AccessLogEntry{
+			Time:        time.Now(),
+			ClientAddr:  clientAddr,
+			User:        user,
+			Method:      r.Method,
+			Host:        host,
+			URL:         r.URL.String(),
+			Intercepted: true,
+		}
+		resp, err := p.interceptedResponse(r)
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entry.Status = resp.StatusCode
+		p.record(entry)
+
+		removeHopHeaders(resp.Header)
+		err = resp.Write(conn)
+		resp.Body.Close()
+		if err != nil || r.Close {
+			return
+		}
+	}
+}
+
+func (p *Proxy) interceptedResponse(r *http.Request) (*http.Response, error) {
+	resp, err := p.roundTrip(r)
+	if err != nil {
+		return errorResponse(r, http.StatusBadGateway, err.Error()), err
+	}
+	return resp, nil
+}
+
+func (p *Proxy) roundTrip(r *http.Request) (*http.Response, error) {
+	outgoing := r.Clone(r.Context())
+	outgoing.RequestURI = ""
+	removeHopHeaders(outgoing.Header)
+	return p.transport.RoundTrip(outgoing)
+}
+
+func (p *Proxy) record(entry AccessLogEntry) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.accessLog = append(p.accessLog, entry)
+	if p.LogWriter != nil {
+		fmt.Fprintln(p.LogWriter, entry.String())
+	}
+}
+
+func tunnel(client net.Conn, upstream net.Conn) {
+	done := make(chan struct{}, 2)
+	pipe := func(dst net.Conn, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go pipe(upstream, client)
+	go pipe(client, upstream)
+	<-done
+	client.Close()
+	upstream.Close()
+}
+
+func errorResponse(r *http.Request, status int, message string) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Request:       r,
+		Header:        http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+		ContentLength: int64(len(message)),
+		Body:          io.NopCloser(strings.NewReader(message)),
+	}
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, name := range header.Values("Connection") {
+		for _, field := range strings.Split(name, ",") {
+			header.Del(strings.TrimSpace(field))
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+func parseBasicAuth(header string) (string, string, bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	user, password, ok := strings.Cut(string(decoded), ":")
+	return user, password, ok
+}
+
+// matchesDomain checks if the host is the domain or one of its subdomains. A leading '.' or '*.' in the
+// domain is ignored.
+func matchesDomain(host string, domain string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "*"), ".")
+	if domain == "" {
+		return false
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,180 @@
+package forward_proxy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func proxiedClient(proxyURL *url.URL, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: tlsConfig,
+		},
+	}
+}
+
+func get(client *http.Client, target string) (int, string) {
+	resp, err := client.Get(target)
+	Expect(err).ToNot(HaveOccurred())
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return resp.StatusCode, string(body)
+}
+
+var _ = Describe("Forward proxy", func() {
+	var (
+		proxy   *Proxy
+		backend *httptest.Server
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s", r.URL.Path)
+	})
+
+	AfterEach(func() {
+		if proxy != nil {
+			Expect(proxy.Close()).To(Succeed())
+			proxy = nil
+		}
+		if backend != nil {
+			backend.Close()
+			backend = nil
+		}
+	})
+
+	Context("plain HTTP", func() {
+		BeforeEach(func() {
+			backend = httptest.NewServer(handler)
+		})
+
+		It("forwards the requests and records them", func() {
+			logs := &bytes.Buffer{}
+			proxy = NewProxy().AccessLogWriter(logs)
+			Expect(proxy.Start()).To(Succeed())
+
+			status, body := get(proxiedClient(proxy.URL(), nil), backend.URL+"/test")
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(Equal("hello /test"))
+
+			entries := proxy.AccessLog()
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Method).To(Equal(http.MethodGet))
+			Expect(entries[0].URL).To(Equal(backend.URL + "/test"))
+			Expect(entries[0].Status).To(Equal(http.StatusOK))
+			Expect(logs.String()).To(ContainSubstring("GET " + backend.URL + "/test 200"))
+		})
+
+		It("requires the credentials", func() {
+			proxy = NewProxy().BasicAuth("user", "secret")
+			Expect(proxy.Start()).To(Succeed())
+
+			status, _ := get(proxiedClient(proxy.URL(), nil), backend.URL)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(proxy.AccessLog()[0].User).To(Equal("user"))
+
+			wrongURL := proxy.URL()
+			wrongURL.User = url.UserPassword("user", "wrong")
+			status, _ = get(proxiedClient(wrongURL, nil), backend.URL)
+			Expect(status).To(Equal(http.StatusProxyAuthRequired))
+
+			noAuthURL := proxy.URL()
+			noAuthURL.User = nil
+			status, _ = get(proxiedClient(noAuthURL, nil), backend.URL)
+			Expect(status).To(Equal(http.StatusProxyAuthRequired))
+		})
+
+		It("rejects the denied domains", func() {
+			proxy = NewProxy().Deny("127.0.0.1")
+			Expect(proxy.Start()).To(Succeed())
+
+			status, _ := get(proxiedClient(proxy.URL(), nil), backend.URL)
+			Expect(status).To(Equal(http.StatusForbidden))
+			Expect(proxy.AccessLog()[0].Status).To(Equal(http.StatusForbidden))
+		})
+
+		It("rejects the domains not allowed", func() {
+			proxy = NewProxy().Allow("example.com")
+			Expect(proxy.Start()).To(Succeed())
+
+			status, _ := get(proxiedClient(proxy.URL(), nil), backend.URL)
+			Expect(status).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("HTTPS", func() {
+		BeforeEach(func() {
+			backend = httptest.NewTLSServer(handler)
+		})
+
+		It("tunnels the CONNECT requests", func() {
+			proxy = NewProxy().BasicAuth("user", "secret")
+			Expect(proxy.Start()).To(Succeed())
+			backendTLS := backend.Client().Transport.(*http.Transport).TLSClientConfig
+
+			status, body := get(proxiedClient(proxy.URL(), backendTLS), backend.URL+"/tunnel")
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(Equal("hello /tunnel"))
+
+			Eventually(proxy.AccessLog).Should(HaveLen(1))
+			entries := proxy.AccessLog()
+			Expect(entries[0].Method).To(Equal(http.MethodConnect))
+			Expect(entries[0].URL).To(Equal(backend.Listener.Addr().String()))
+			Expect(entries[0].Intercepted).To(BeFalse())
+		})
+
+		It("intercepts the TLS connections with the generated CA", func() {
+			ca, err := NewCA()
+			Expect(err).ToNot(HaveOccurred())
+			proxy = NewProxy().
+				InterceptTLS(ca).
+				UpstreamTLSConfig(backend.Client().Transport.(*http.Transport).TLSClientConfig)
+			Expect(proxy.Start()).To(Succeed())
+
+			client := proxiedClient(proxy.URL(), &tls.Config{RootCAs: ca.CertPool()})
+			status, body := get(client, backend.URL+"/intercepted")
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(Equal("hello /intercepted"))
+
+			Eventually(proxy.AccessLog).Should(HaveLen(2))
+			entries := proxy.AccessLog()
+			Expect(entries).To(ContainElement(And(
+				HaveField("Method", http.MethodGet),
+				HaveField("URL", backend.URL+"/intercepted"),
+				HaveField("Intercepted", BeTrue()),
+			)))
+			Expect(entries).To(ContainElement(HaveField("Method", http.MethodConnect)))
+		})
+
+		It("fails the handshake when the client doesn't trust the CA", func() {
+			ca, err := NewCA()
+			Expect(err).ToNot(HaveOccurred())
+			proxy = NewProxy().InterceptTLS(ca)
+			Expect(proxy.Start()).To(Succeed())
+
+			_, err = proxiedClient(proxy.URL(), nil).Get(backend.URL)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Allowed", func() {
+		It("matches the domains and their subdomains", func() {
+			proxy = NewProxy().Allow("example.com", "*.openshift.com").Deny("blocked.example.com")
+			Expect(proxy.Allowed("example.com:443")).To(BeTrue())
+			Expect(proxy.Allowed("api.example.com")).To(BeTrue())
+			Expect(proxy.Allowed("api.openshift.com")).To(BeTrue())
+			Expect(proxy.Allowed("blocked.example.com:443")).To(BeFalse())
+			Expect(proxy.Allowed("a.blocked.example.com")).To(BeFalse())
+			Expect(proxy.Allowed("badexample.com")).To(BeFalse())
+			Expect(proxy.Allowed("quay.io")).To(BeFalse())
+		})
+	})
+})
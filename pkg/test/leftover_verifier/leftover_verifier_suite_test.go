@@ -0,0 +1,13 @@
+package leftover_verifier
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLeftoverVerifier(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Leftover Verifier Suite")
+}
@@ -0,0 +1,116 @@
+package leftover_verifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResourceType is the kind of AWS resource a leftover is
+type ResourceType string
+
+const (
+	ResourceTypeInstance            ResourceType = "instance"
+	ResourceTypeVolume              ResourceType = "volume"
+	ResourceTypeLoadBalancer        ResourceType = "load balancer"
+	ResourceTypeClassicLoadBalancer ResourceType = "classic load balancer"
+	ResourceTypeSecurityGroup       ResourceType = "security group"
+	ResourceTypeNetworkInterface    ResourceType = "network interface"
+	ResourceTypeHostedZone          ResourceType = "hosted zone"
+	ResourceTypeRecordSet           ResourceType = "record set"
+	ResourceTypeInstanceProfile     ResourceType = "instance profile"
+	ResourceTypeBucket              ResourceType = "bucket"
+)
+
+// Leftover is an AWS resource of the cluster that still exists
+type Leftover struct {
+	Type ResourceType
+	ID   string
+	// Details describes the state of the resource, like its name or status
+	Details string
+}
+
+// LeftoverReport is the result of a scan of the AWS resources of a cluster
+type LeftoverReport struct {
+	InfraID   string
+	ClusterID string
+	Leftovers []Leftover
+	// Errors are the failures to list a type of resource, the report can't prove those types are clean
+	Errors map[ResourceType]error
+}
+
+func newLeftoverReport(infraID string, clusterID string) *LeftoverReport {
+	return &LeftoverReport{
+		InfraID:   infraID,
+		ClusterID: clusterID,
+		Leftovers: []Leftover{},
+		Errors:    map[ResourceType]error{},
+	}
+}
+
+func (r *LeftoverReport) add(resourceType ResourceType, id string, details string) {
+	for _, leftover := range r.Leftovers {
+		if leftover.Type == resourceType && leftover.ID == id {
+			return
+		}
+	}
+	r.Leftovers = append(r.Leftovers, Leftover{Type: resourceType, ID: id, Details: details})
+}
+
+func (r *LeftoverReport) addError(resourceType ResourceType, err error) {
+	if err != nil {
+		r.Errors[resourceType] = err
+	}
+}
+
+// Clean tells if the scan found no leftover and could check all the resource types
+func (r *LeftoverReport) Clean() bool {
+	return len(r.Leftovers) == 0 && len(r.Errors) == 0
+}
+
+// ByType returns the leftovers grouped by resource type
+func (r *LeftoverReport) ByType() map[ResourceType][]Leftover {
+	byType := map[ResourceType][]Leftover{}
+	for _, leftover := range r.Leftovers {
+		byType[leftover.Type] = append(byType[leftover.Type], leftover)
+	}
+	return byType
+}
+
+// String returns a human readable report, with the leftovers grouped by resource type and sorted by ID
+func (r *LeftoverReport) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "Leftovers of cluster %s (infra ID %s):", r.ClusterID, r.InfraID)
+	if r.Clean() {
+		builder.WriteString(" none")
+		return builder.String()
+	}
+
+	byType := r.ByType()
+	types := []string{}
+	for resourceType := range byType {
+		types = append(types, string(resourceType))
+	}
+	sort.Strings(types)
+	for _, resourceType := range types {
+		leftovers := byType[ResourceType(resourceType)]
+		sort.Slice(leftovers, func(i, j int) bool { return leftovers[i].ID < leftovers[j].ID })
+		fmt.Fprintf(builder, "\n  %s (%d):", resourceType, len(leftovers))
+		for _, leftover := range leftovers {
+			fmt.Fprintf(builder, "\n    - %s", leftover.ID)
+			if leftover.Details != "" {
+				fmt.Fprintf(builder, " [%s]", leftover.Details)
+			}
+		}
+	}
+
+	errorTypes := []string{}
+	for resourceType := range r.Errors {
+		errorTypes = append(errorTypes, string(resourceType))
+	}
+	sort.Strings(errorTypes)
+	for _, resourceType := range errorTypes {
+		fmt.Fprintf(builder, "\n  failed to check %s: %v", resourceType, r.Errors[ResourceType(resourceType)])
+	}
+	return builder.String()
+}
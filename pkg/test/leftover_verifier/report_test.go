@@ -0,0 +1,54 @@
+package leftover_verifier
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Leftover report", func() {
+	It("is clean without leftovers nor errors", func() {
+		report := newLeftoverReport("abc12", "123")
+		Expect(report.Clean()).To(BeTrue())
+		Expect(report.String()).To(Equal("Leftovers of cluster 123 (infra ID abc12): none"))
+	})
+
+	It("is not clean when a resource type couldn't be checked", func() {
+		report := newLeftoverReport("abc12", "123")
+		report.addError(ResourceTypeBucket, fmt.Errorf("access denied"))
+		Expect(report.Clean()).To(BeFalse())
+		Expect(report.String()).To(ContainSubstring("failed to check bucket: access denied"))
+	})
+
+	It("groups the leftovers by type and ignores duplicates", func() {
+		report := newLeftoverReport("abc12", "123")
+		report.add(ResourceTypeVolume, "vol-2", "")
+		report.add(ResourceTypeInstance, "i-1", details("name", "abc12-master-0", "state", "stopped"))
+		report.add(ResourceTypeVolume, "vol-1", details("state", "available"))
+		report.add(ResourceTypeVolume, "vol-1", details("state", "available"))
+
+		Expect(report.Clean()).To(BeFalse())
+		Expect(report.Leftovers).To(HaveLen(3))
+		Expect(report.ByType()[ResourceTypeVolume]).To(HaveLen(2))
+		Expect(report.String()).To(Equal("Leftovers of cluster 123 (infra ID abc12):" +
+			"\n  instance (1):" +
+			"\n    - i-1 [name: abc12-master-0, state: stopped]" +
+			"\n  volume (2):" +
+			"\n    - vol-1 [state: available]" +
+			"\n    - vol-2"))
+	})
+})
+
+var _ = Describe("Domain matching", func() {
+	DescribeTable("inDomain",
+		func(name string, domain string, expected bool) {
+			Expect(inDomain(name, domain)).To(Equal(expected))
+		},
+		Entry("same domain", "mycluster.example.com.", "mycluster.example.com", true),
+		Entry("subdomain", "api.mycluster.example.com", "mycluster.example.com", true),
+		Entry("case insensitive", "API.MyCluster.example.com", "mycluster.example.com", true),
+		Entry("parent domain", "example.com", "mycluster.example.com", false),
+		Entry("sibling with same suffix", "othermycluster.example.com", "mycluster.example.com", false),
+	)
+})
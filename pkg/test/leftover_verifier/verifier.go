@@ -0,0 +1,352 @@
+package leftover_verifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const (
+	clusterIDTagKey     = "api.openshift.com/id"
+	defaultPollInterval = 30 * time.Second
+)
+
+// LeftoverVerifier looks for the AWS resources of a cluster that remain after its uninstallation.
+// Resources are attributed to the cluster by the 'kubernetes.io/cluster/<infra ID>' tag, the
+// 'api.openshift.com/id' tag with the cluster ID or, for the resources which can't be tagged, by their name.
+type LeftoverVerifier struct {
+	AWSClient *aws_client.AWSClient
+	InfraID   string
+	ClusterID string
+	// ClusterDomain is the DNS domain of the cluster, like '<name>.<base domain>'. When set, the records under it
+	// are reported in any hosted zone of the account
+	ClusterDomain string
+	Interval      time.Duration
+}
+
+// NewLeftoverVerifier creates a verifier for the cluster. The cluster ID can be empty, then only the infra ID is
+// used to find the resources. The infra ID is required, Scan fails without it.
+func NewLeftoverVerifier(awsClient *aws_client.AWSClient, infraID string, clusterID string) *LeftoverVerifier {
+	return &LeftoverVerifier{
+		AWSClient: awsClient,
+		InfraID:   infraID,
+		ClusterID: clusterID,
+		Interval:  defaultPollInterval,
+	}
+}
+
+// Domain sets the DNS domain of the cluster
+func (v *LeftoverVerifier) Domain(clusterDomain string) *LeftoverVerifier {
+	v.ClusterDomain = strings.TrimSuffix(clusterDomain, ".")
+	return v
+}
+
+// PollInterval sets the interval between two scans of WaitForNoLeftovers
+func (v *LeftoverVerifier) PollInterval(interval time.Duration) *LeftoverVerifier {
+	v.Interval = interval
+	return v
+}
+
+// WaitForNoLeftovers scans the resources of the cluster until none is left or the timeout is reached.
+// The last report is returned in both cases, with an error detailing the leftovers on timeout.
+func (v *LeftoverVerifier) WaitForNoLeftovers(timeout time.Duration) (*LeftoverReport, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		report, err := v.Scan()
+		if err != nil {
+			return nil, err
+		}
+		if report.Clean() {
+			log.LogInfo("No leftover found for cluster %s", v.InfraID)
+			return report, nil
+		}
+		if time.Now().After(deadline) {
+			return report, fmt.Errorf("timeout after %s waiting for the resources of cluster %s to be deleted\n%s",
+				timeout, v.InfraID, report.String())
+		}
+		log.LogDebug("Found %d leftovers for cluster %s, waiting", len(report.Leftovers), v.InfraID)
+		time.Sleep(v.Interval)
+	}
+}
+
+// Scan lists the resources of the cluster which still exist. It fails when the infra ID is empty, as the resources
+// of every cluster would be reported.
+func (v *LeftoverVerifier) Scan() (*LeftoverReport, error) {
+	if v.InfraID == "" {
+		return nil, fmt.Errorf("the infra ID of the cluster is required to find its resources")
+	}
+	report := newLeftoverReport(v.InfraID, v.ClusterID)
+	v.scanInstances(report)
+	v.scanVolumes(report)
+	v.scanLoadBalancers(report)
+	v.scanSecurityGroups(report)
+	v.scanNetworkInterfaces(report)
+	v.scanRoute53(report)
+	v.scanInstanceProfiles(report)
+	v.scanBuckets(report)
+	return report, nil
+}
+
+func (v *LeftoverVerifier) infraTagKey() string {
+	return "kubernetes.io/cluster/" + v.InfraID
+}
+
+// ownedByTag tells if the tag attributes a resource to the cluster
+func (v *LeftoverVerifier) ownedByTag(key string, value string) bool {
+	return key == v.infraTagKey() || (v.ClusterID != "" && key == clusterIDTagKey && value == v.ClusterID)
+}
+
+// namedFor tells if the name of a resource starts with the infra ID, case-insensitively
+func (v *LeftoverVerifier) namedFor(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(v.InfraID)+"-")
+}
+
+// ec2Filters returns one filter per way to attribute an EC2 resource to the cluster, they are ORed by listing
+// them separately
+func (v *LeftoverVerifier) ec2Filters() []map[string][]string {
+	filters := []map[string][]string{
+		{"tag-key": {v.infraTagKey()}},
+	}
+	if v.ClusterID != "" {
+		filters = append(filters, map[string][]string{"tag:" + clusterIDTagKey: {v.ClusterID}})
+	}
+	return filters
+}
+
+func (v *LeftoverVerifier) scanInstances(report *LeftoverReport) {
+	for _, filter := range v.ec2Filters() {
+		instances, err := v.AWSClient.ListInstances(nil, filter)
+		if err != nil {
+			report.addError(ResourceTypeInstance, err)
+			return
+		}
+		for _, instance := range instances {
+			if instance.State != nil && instance.State.Name == ec2types.InstanceStateNameTerminated {
+				continue
+			}
+			state := ""
+			if instance.State != nil {
+				state = string(instance.State.Name)
+			}
+			report.add(ResourceTypeInstance, aws.ToString(instance.InstanceId),
+				details("name", aws_client.GetInstanceName(&instance), "state", state))
+		}
+	}
+}
+
+func (v *LeftoverVerifier) scanVolumes(report *LeftoverReport) {
+	for _, filter := range v.ec2Filters() {
+		volumes, err := v.AWSClient.ListVolumes(filter)
+		if err != nil {
+			report.addError(ResourceTypeVolume, err)
+			return
+		}
+		for _, volume := range volumes {
+			report.add(ResourceTypeVolume, aws.ToString(volume.VolumeId),
+				details("name", ec2TagValue(volume.Tags, "Name"), "state", string(volume.State)))
+		}
+	}
+}
+
+func (v *LeftoverVerifier) scanLoadBalancers(report *LeftoverReport) {
+	tags := map[string]string{v.infraTagKey(): ""}
+	if v.ClusterID != "" {
+		tags[clusterIDTagKey] = v.ClusterID
+	}
+	for key, value := range tags {
+		loadBalancers, err := v.AWSClient.ListLoadBalancersByTag(key, value)
+		if err != nil {
+			report.addError(ResourceTypeLoadBalancer, err)
+		}
+		for _, lb := range loadBalancers {
+			report.add(ResourceTypeLoadBalancer, aws.ToString(lb.LoadBalancerArn),
+				details("name", aws.ToString(lb.LoadBalancerName), "type", string(lb.Type)))
+		}
+
+		classicLoadBalancers, err := v.AWSClient.ListClassicLoadBalancersByTag(key, value)
+		if err != nil {
+			report.addError(ResourceTypeClassicLoadBalancer, err)
+		}
+		for _, lb := range classicLoadBalancers {
+			report.add(ResourceTypeClassicLoadBalancer, aws.ToString(lb.LoadBalancerName),
+				details("dns name", aws.ToString(lb.DNSName)))
+		}
+	}
+}
+
+func (v *LeftoverVerifier) scanSecurityGroups(report *LeftoverReport) {
+	for _, filter := range v.ec2Filters() {
+		securityGroups, err := v.AWSClient.ListSecurityGroupsByFilters(filter)
+		if err != nil {
+			report.addError(ResourceTypeSecurityGroup, err)
+			return
+		}
+		for _, sg := range securityGroups {
+			report.add(ResourceTypeSecurityGroup, aws.ToString(sg.GroupId),
+				details("name", aws.ToString(sg.GroupName), "vpc", aws.ToString(sg.VpcId)))
+		}
+	}
+}
+
+func (v *LeftoverVerifier) scanNetworkInterfaces(report *LeftoverReport) {
+	for _, filter := range v.ec2Filters() {
+		networkInterfaces, err := v.AWSClient.ListNetworkInterfaces(filter)
+		if err != nil {
+			report.addError(ResourceTypeNetworkInterface, err)
+			return
+		}
+		for _, eni := range networkInterfaces {
+			report.add(ResourceTypeNetworkInterface, aws.ToString(eni.NetworkInterfaceId),
+				details("status", string(eni.Status), "description", aws.ToString(eni.Description)))
+		}
+	}
+}
+
+// scanRoute53 reports the hosted zones tagged for the cluster and, when the cluster domain is known, the records
+// under the cluster domain in the other zones. The NS and SOA records of a leftover zone are not reported on
+// their own.
+func (v *LeftoverVerifier) scanRoute53(report *LeftoverReport) {
+	hostedZones, err := v.AWSClient.ListHostedZones()
+	if err != nil {
+		report.addError(ResourceTypeHostedZone, err)
+		return
+	}
+	for _, zone := range hostedZones {
+		zoneID := aws.ToString(zone.Id)
+		zoneName := strings.TrimSuffix(aws.ToString(zone.Name), ".")
+		tags, err := v.AWSClient.GetHostedZoneTags(zoneID)
+		if err != nil {
+			report.addError(ResourceTypeHostedZone, err)
+			continue
+		}
+		owned := false
+		for _, tag := range tags {
+			if v.ownedByTag(aws.ToString(tag.Key), aws.ToString(tag.Value)) {
+				owned = true
+				break
+			}
+		}
+		if owned {
+			report.add(ResourceTypeHostedZone, zoneID, details("name", zoneName))
+			continue
+		}
+
+		if v.ClusterDomain == "" || !inDomain(v.ClusterDomain, zoneName) {
+			continue
+		}
+		recordSets, err := v.AWSClient.ListResourceRecordSets(zoneID)
+		if err != nil {
+			report.addError(ResourceTypeRecordSet, err)
+			continue
+		}
+		for _, recordSet := range recordSets {
+			name := strings.TrimSuffix(aws.ToString(recordSet.Name), ".")
+			if !inDomain(name, v.ClusterDomain) {
+				continue
+			}
+			report.add(ResourceTypeRecordSet, fmt.Sprintf("%s %s", name, recordSet.Type),
+				details("zone", zoneName))
+		}
+	}
+}
+
+func (v *LeftoverVerifier) scanInstanceProfiles(report *LeftoverReport) {
+	instanceProfiles, err := v.AWSClient.ListInstanceProfiles("")
+	if err != nil {
+		report.addError(ResourceTypeInstanceProfile, err)
+		return
+	}
+	for _, profile := range instanceProfiles {
+		name := aws.ToString(profile.InstanceProfileName)
+		if !v.namedFor(name) {
+			continue
+		}
+		roles := []string{}
+		for _, role := range profile.Roles {
+			roles = append(roles, aws.ToString(role.RoleName))
+		}
+		report.add(ResourceTypeInstanceProfile, name, details("roles", strings.Join(roles, ",")))
+	}
+}
+
+// scanBuckets reports the buckets named '<infra ID>-...', like the image registry bucket, and the buckets of the
+// region of the client tagged for the cluster. The tags of the buckets of other regions are not checked, they can
+// only be read with a client of the bucket region.
+func (v *LeftoverVerifier) scanBuckets(report *LeftoverReport) {
+	buckets, err := v.AWSClient.ListBuckets()
+	if err != nil {
+		report.addError(ResourceTypeBucket, err)
+		return
+	}
+	for _, bucket := range buckets {
+		name := aws.ToString(bucket.Name)
+		if !v.namedFor(name) {
+			owned, err := v.bucketOwnedByTag(name)
+			if err != nil {
+				report.addError(ResourceTypeBucket, err)
+				continue
+			}
+			if !owned {
+				continue
+			}
+		}
+		created := ""
+		if bucket.CreationDate != nil {
+			created = bucket.CreationDate.Format(time.RFC3339)
+		}
+		report.add(ResourceTypeBucket, name, details("created", created))
+	}
+}
+
+// bucketOwnedByTag tells if the bucket is in the region of the client and tagged for the cluster
+func (v *LeftoverVerifier) bucketOwnedByTag(name string) (bool, error) {
+	region, err := v.AWSClient.GetBucketRegion(name)
+	if err != nil || region != v.AWSClient.Region {
+		return false, err
+	}
+	tags, err := v.AWSClient.GetBucketTags(name)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range tags {
+		if v.ownedByTag(aws.ToString(tag.Key), aws.ToString(tag.Value)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inDomain tells if the name is the domain or one of its subdomains, names are compared without trailing dots
+// and case
+func inDomain(name string, domain string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// details formats key and value pairs, skipping the empty values
+func details(keysAndValues ...string) string {
+	parts := []string{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i+1] == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", keysAndValues[i], keysAndValues[i+1]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func ec2TagValue(tags []ec2types.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
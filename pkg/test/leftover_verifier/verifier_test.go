@@ -0,0 +1,40 @@
+package leftover_verifier
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Leftover verifier", func() {
+	It("refuses to scan without infra ID", func() {
+		verifier := NewLeftoverVerifier(nil, "", "123")
+		_, err := verifier.Scan()
+		Expect(err).To(MatchError("the infra ID of the cluster is required to find its resources"))
+		_, err = verifier.WaitForNoLeftovers(time.Hour)
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("attributes the resources to the cluster by name",
+		func(name string, expected bool) {
+			Expect(NewLeftoverVerifier(nil, "ABC12-xyz", "").namedFor(name)).To(Equal(expected))
+		},
+		Entry("image registry bucket", "abc12-xyz-image-registry-us-east-1-qwerty", true),
+		Entry("instance profile", "ABC12-xyz-worker-profile", true),
+		Entry("infra ID in the middle", "backup-abc12-xyz-data", false),
+		Entry("longer infra ID", "abc12-xyzw-image-registry", false),
+		Entry("infra ID alone", "abc12-xyz", false),
+	)
+
+	DescribeTable("attributes the resources to the cluster by tag",
+		func(clusterID string, key string, value string, expected bool) {
+			Expect(NewLeftoverVerifier(nil, "abc12", clusterID).ownedByTag(key, value)).To(Equal(expected))
+		},
+		Entry("infra tag", "", "kubernetes.io/cluster/abc12", "owned", true),
+		Entry("infra tag of another cluster", "", "kubernetes.io/cluster/def34", "owned", false),
+		Entry("cluster ID tag", "123", "api.openshift.com/id", "123", true),
+		Entry("cluster ID tag of another cluster", "123", "api.openshift.com/id", "456", false),
+		Entry("cluster ID tag without cluster ID", "", "api.openshift.com/id", "", false),
+	)
+})
@@ -0,0 +1,31 @@
+package spec_logs
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// ReportEntryName is the name of the report entry holding the captured logs of a failed spec
+const ReportEntryName = "Helper logs"
+
+// CaptureSpecLogs captures the logs written through pkg/log while the current spec runs and attaches them to the
+// spec report only when the spec fails. Unless Ginkgo runs in verbose mode the logs aren't written to the output,
+// so that the output of parallel specs doesn't interleave.
+//
+// It must be called from a setup node, like BeforeEach, or from the spec itself. The returned context carries
+// the capture, so the logs written with the context variants of the log functions are captured too.
+func CaptureSpecLogs(ctx context.Context) context.Context {
+	_, reporterConfig := GinkgoConfiguration()
+	mute := !reporterConfig.Verbose && !reporterConfig.VeryVerbose
+	capture, stop := log.StartCapture(mute)
+	DeferCleanup(func() {
+		stop()
+		if CurrentSpecReport().Failed() {
+			AddReportEntry(ReportEntryName, capture.String())
+		}
+	})
+	return log.ContextWithCapture(ctx, capture)
+}
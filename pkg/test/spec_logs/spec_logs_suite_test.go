@@ -0,0 +1,13 @@
+package spec_logs
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSpecLogs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Spec Logs Suite")
+}
@@ -0,0 +1,30 @@
+package spec_logs
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+var _ = Describe("CaptureSpecLogs", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = CaptureSpecLogs(context.Background())
+	})
+
+	It("captures the logs of the spec", func() {
+		log.LogInfo("helper message")
+		log.LogWarningContext(ctx, "context message")
+		captured := log.CaptureFromContext(ctx).String()
+		Expect(captured).To(ContainSubstring("helper message"))
+		Expect(captured).To(ContainSubstring("context message"))
+	})
+
+	It("starts with an empty capture for each spec", func() {
+		Expect(log.CaptureFromContext(ctx).String()).To(BeEmpty())
+	})
+})
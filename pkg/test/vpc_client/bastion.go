@@ -1,11 +1,11 @@
 package vpc_client
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/openshift-online/ocm-common/pkg/file"
 	"golang.org/x/crypto/bcrypt"
 	"net/url"
 	"strings"
@@ -19,9 +19,11 @@ import (
 
 // LaunchBastion will launch a bastion instance on the indicated zone.
 // If set imageID to empty, it will find the bastion image using filter with specific name.
+// The instance terminates itself once the TTL of the VPC is reached, see SweepExpiredHelperInstances
+// to clean up its key pair and EIP.
+// When a step fails the security group, key pair, instance and EIP created before are deleted.
 func (vpc *VPC) LaunchBastion(imageID string, zone string, userData string, keypairName string,
 	privateKeyPath string) (*types.Instance, error) {
-	var inst *types.Instance
 	if imageID == "" {
 
 		var err error
@@ -35,67 +37,19 @@ func (vpc *VPC) LaunchBastion(imageID string, zone string, userData string, keyp
 		log.LogError("Userdata can not be empty, pleas provide the correct userdata")
 		return nil, errors.New("userData should not be empty")
 	}
-	pubSubnet, err := vpc.PreparePublicSubnet(zone)
-	if err != nil {
-		log.LogError("Error preparing a subnet in current zone %s with image ID %s: %s", zone, imageID, err)
-		return nil, err
-	}
-	SGID, err := vpc.CreateAndAuthorizeDefaultSecurityGroupForProxy(3128)
-	if err != nil {
-		log.LogError("Prepare SG failed for the bastion preparation %s", err)
-		return inst, err
-	}
 	keyName := fmt.Sprintf("%s-%s", CON.InstanceKeyNamePrefix, keypairName)
-	key, err := vpc.CreateKeyPair(keyName)
-	if err != nil {
-		log.LogError("Create key pair failed %s", err)
-		return inst, err
-	}
-	tags := map[string]string{
-		"Name": CON.BastionName,
-	}
-	_, err = vpc.AWSClient.TagResource(*key.KeyPairId, tags)
-	if err != nil {
-		log.LogError("Add tag for key pair %s failed %s", *key.KeyPairId, err)
-		return inst, err
-	}
-
 	privateKeyName := fmt.Sprintf("%s-%s", keypairName, "keyPair.pem")
-	sshKeyPath, err := file.WriteToFile(*key.KeyMaterial, privateKeyName, privateKeyPath)
-	if err != nil {
-		log.LogError("Write private key to %s failed %s", sshKeyPath, err)
-		return inst, err
-	}
-	instOut, err := vpc.AWSClient.LaunchInstance(pubSubnet.ID, imageID, 1, "t3.medium", *key.KeyName,
-		[]string{SGID}, true, userData)
-
+	state, err := vpc.helperInstanceSaga("bastion-"+keypairName, CON.BastionName, imageID, zone, userData,
+		keyName, privateKeyName, privateKeyPath, 3128).Observe(vpc.sagaObserver(OperationLaunchBastion)).
+		Run(context.TODO())
 	if err != nil {
 		log.LogError("Launch bastion instance failed %s", err)
-		return inst, err
-	} else {
-		log.LogInfo("Launch bastion instance %s succeed", *instOut.Instances[0].InstanceId)
-	}
-	tags = map[string]string{
-		"Name": CON.BastionName,
-	}
-	instID := *instOut.Instances[0].InstanceId
-	_, err = vpc.AWSClient.TagResource(instID, tags)
-	if err != nil {
-		return inst, fmt.Errorf("tag instance %s failed:%s", instID, err)
-	}
-
-	publicIP, err := vpc.AWSClient.AllocateEIPAndAssociateInstance(instID)
-	if err != nil {
-		log.LogError("Prepare EIP failed for the bastion preparation %s", err)
-		return inst, err
+		return nil, err
 	}
-	log.LogInfo("Prepare EIP successfully for the bastion preparation. Launch with IP: %s", publicIP)
 
-	time.Sleep(2 * time.Minute)
+	time.Sleep(helperInstanceBootTime)
 
-	inst = &instOut.Instances[0]
-	inst.PublicIpAddress = &publicIP
-	return inst, nil
+	return vpc.helperInstance(state)
 }
 
 // PrepareBastionProxy will launch a bastion instance with squid proxy on the indicated zone and return the proxy url.
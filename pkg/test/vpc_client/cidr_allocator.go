@@ -0,0 +1,303 @@
+package vpc_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/apparentlymart/go-cidr/cidr"
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	CON "github.com/openshift-online/ocm-common/pkg/aws/consts"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+const (
+	allocatorLockRetryInterval = 100 * time.Millisecond
+	allocatorLockTimeout       = 30 * time.Second
+	// allocatorStaleLockAge is the age after which a lock file is considered left behind by a killed process
+	allocatorStaleLockAge = 2 * time.Minute
+)
+
+// CIDRAllocator hands out VPC CIDRs that don't collide with the ones allocated to other runs. The owner is
+// recorded with the allocation, usually the VPC name. Releasing a CIDR that is not allocated is a no-op.
+type CIDRAllocator interface {
+	Allocate(owner string) (string, error)
+	Release(cidr string) error
+}
+
+// ************************* IPAM allocator *************************
+
+// IpamCIDRAllocator allocates the VPC CIDRs from an AWS VPC IPAM pool, shared by all the runs using the pool
+type IpamCIDRAllocator struct {
+	AWSClient     *aws_client.AWSClient
+	PoolID        string
+	NetmaskLength int32
+}
+
+func NewIpamCIDRAllocator(awsClient *aws_client.AWSClient, poolID string) *IpamCIDRAllocator {
+	return &IpamCIDRAllocator{
+		AWSClient:     awsClient,
+		PoolID:        poolID,
+		NetmaskLength: CON.DefaultVPCNetmaskLength,
+	}
+}
+
+func (a *IpamCIDRAllocator) Netmask(length int32) *IpamCIDRAllocator {
+	a.NetmaskLength = length
+	return a
+}
+
+func (a *IpamCIDRAllocator) Allocate(owner string) (string, error) {
+	allocation, err := a.AWSClient.AllocateIpamPoolCidr(a.PoolID, a.NetmaskLength, owner)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(allocation.Cidr), nil
+}
+
+// Release looks the allocation of the CIDR up in the pool, so that CIDRs allocated by another process can be
+// released as well
+func (a *IpamCIDRAllocator) Release(cidr string) error {
+	allocations, err := a.AWSClient.ListIpamPoolAllocations(a.PoolID)
+	if err != nil {
+		return err
+	}
+	for _, allocation := range allocations {
+		if aws.ToString(allocation.Cidr) == cidr {
+			return a.AWSClient.ReleaseIpamPoolAllocation(a.PoolID, aws.ToString(allocation.IpamPoolAllocationId),
+				cidr)
+		}
+	}
+	log.LogInfo("CIDR %s is not allocated from IPAM pool %s, nothing to release", cidr, a.PoolID)
+	return nil
+}
+
+// ************************* File allocator *************************
+
+// FileCIDRAllocator allocates the VPC CIDRs from a local range, recording the allocations in a JSON file. The
+// file is locked while it is updated, so that the runs sharing the file on a host never get the same CIDR.
+// Allocations older than the lease, when set, are considered leaked and handed out again.
+type FileCIDRAllocator struct {
+	Path          string
+	Supernet      string
+	NetmaskLength int
+	Lease         time.Duration
+}
+
+// FileCIDRAllocation is an entry of the allocations file
+type FileCIDRAllocation struct {
+	Owner       string    `json:"owner"`
+	AllocatedAt time.Time `json:"allocated_at"`
+}
+
+func NewFileCIDRAllocator(path string) *FileCIDRAllocator {
+	return &FileCIDRAllocator{
+		Path:          path,
+		Supernet:      CON.DefaultVPCCIDRSupernet,
+		NetmaskLength: CON.DefaultVPCNetmaskLength,
+	}
+}
+
+func (a *FileCIDRAllocator) Range(supernet string, netmaskLength int) *FileCIDRAllocator {
+	a.Supernet = supernet
+	a.NetmaskLength = netmaskLength
+	return a
+}
+
+func (a *FileCIDRAllocator) LeaseDuration(lease time.Duration) *FileCIDRAllocator {
+	a.Lease = lease
+	return a
+}
+
+func (a *FileCIDRAllocator) Allocate(owner string) (string, error) {
+	_, supernet, err := net.ParseCIDR(a.Supernet)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR range %s: %s", a.Supernet, err)
+	}
+	supernetPrefix, _ := supernet.Mask.Size()
+	if a.NetmaskLength < supernetPrefix {
+		return "", fmt.Errorf("netmask length %d is larger than the range %s", a.NetmaskLength, a.Supernet)
+	}
+
+	var allocated string
+	err = a.update(func(allocations map[string]FileCIDRAllocation) error {
+		count := uint64(1) << uint(a.NetmaskLength-supernetPrefix)
+		for i := uint64(0); i < count; i++ {
+			subnet, err := cidr.Subnet(supernet, a.NetmaskLength-supernetPrefix, int(i))
+			if err != nil {
+				return err
+			}
+			existing, found := allocations[subnet.String()]
+			if found && (a.Lease == 0 || time.Since(existing.AllocatedAt) < a.Lease) {
+				continue
+			}
+			if found {
+				log.LogWarning("Allocation of %s to %s expired, handing it out again", subnet, existing.Owner)
+			}
+			allocated = subnet.String()
+			allocations[allocated] = FileCIDRAllocation{Owner: owner, AllocatedAt: time.Now().UTC()}
+			return nil
+		}
+		return fmt.Errorf("no CIDR /%d left in %s", a.NetmaskLength, a.Supernet)
+	})
+	if err != nil {
+		return "", err
+	}
+	log.LogInfo("Allocated CIDR %s to %s from %s", allocated, owner, a.Path)
+	return allocated, nil
+}
+
+func (a *FileCIDRAllocator) Release(cidr string) error {
+	return a.update(func(allocations map[string]FileCIDRAllocation) error {
+		if _, found := allocations[cidr]; found {
+			delete(allocations, cidr)
+			log.LogInfo("Released CIDR %s from %s", cidr, a.Path)
+		}
+		return nil
+	})
+}
+
+// Allocations returns the current allocations by CIDR
+func (a *FileCIDRAllocator) Allocations() (map[string]FileCIDRAllocation, error) {
+	var allocations map[string]FileCIDRAllocation
+	err := a.update(func(current map[string]FileCIDRAllocation) error {
+		allocations = current
+		return nil
+	})
+	return allocations, err
+}
+
+// update runs the function on the allocations read from the file while holding the lock and writes them back
+func (a *FileCIDRAllocator) update(fn func(allocations map[string]FileCIDRAllocation) error) error {
+	unlock, err := lockFile(a.Path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	allocations := map[string]FileCIDRAllocation{}
+	content, err := os.ReadFile(a.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(content) != 0 {
+		if err = json.Unmarshal(content, &allocations); err != nil {
+			return fmt.Errorf("failed to parse CIDR allocations file %s: %s", a.Path, err)
+		}
+	}
+	if err = fn(allocations); err != nil {
+		return err
+	}
+	content, err = json.MarshalIndent(allocations, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a killed process never leaves a truncated file
+	tmpPath := a.Path + ".tmp"
+	if err = os.WriteFile(tmpPath, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, a.Path)
+}
+
+// lockTokens numbers the locks taken by the process, so that the goroutines of a process don't share tokens
+var lockTokens atomic.Uint64
+
+// lockFile creates the lock file exclusively, waiting for other holders to remove it. Lock files older than
+// allocatorStaleLockAge are removed, see removeStaleLock. The lock file holds a token of its holder, so that the
+// returned unlock function never removes a lock taken over by another process.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	token := fmt.Sprintf("%d-%d", os.Getpid(), lockTokens.Add(1))
+	deadline := time.Now().Add(allocatorLockTimeout)
+	for {
+		lock, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = lock.WriteString(token)
+			if err = errors.Join(err, lock.Close()); err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return func() { unlockFile(path, token) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		removed, err := removeStaleLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if removed {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock file %s", path)
+		}
+		time.Sleep(allocatorLockRetryInterval)
+	}
+}
+
+// unlockFile removes the lock file when it still holds the token
+func unlockFile(path string, token string) {
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != token {
+		log.LogWarning("Lock file %s was removed as stale and taken over, leaving it", path)
+		return
+	}
+	os.Remove(path)
+}
+
+// removeStaleLock removes the lock file when it is older than allocatorStaleLockAge and returns true when the lock
+// can be taken again right away. The lock file is renamed first, so that a single process removes it, and is only
+// removed when its modification time and holder are the ones seen stale: when the lock was released and taken
+// again meanwhile, the fresh lock file is put back.
+func removeStaleLock(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if time.Since(info.ModTime()) <= allocatorStaleLockAge {
+		return false, nil
+	}
+	holder, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	stalePath := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), lockTokens.Add(1))
+	if err = os.Rename(path, stalePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
+		}
+		return false, err
+	}
+	staleInfo, statErr := os.Stat(stalePath)
+	staleHolder, readErr := os.ReadFile(stalePath)
+	if statErr == nil && readErr == nil && staleInfo.ModTime().Equal(info.ModTime()) &&
+		bytes.Equal(staleHolder, holder) {
+		log.LogWarning("Removing stale lock file %s of %s", path, holder)
+		return true, os.Remove(stalePath)
+	}
+	// Linking fails when yet another process took the lock, the holder of the renamed lock then loses it
+	if err = os.Link(stalePath, path); err != nil && !errors.Is(err, os.ErrExist) {
+		os.Remove(stalePath)
+		return false, err
+	}
+	return false, os.Remove(stalePath)
+}
@@ -0,0 +1,135 @@
+package vpc_client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File CIDR allocator", func() {
+	var path string
+	var allocator *FileCIDRAllocator
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "allocations.json")
+		allocator = NewFileCIDRAllocator(path).Range("10.0.0.0/22", 24)
+	})
+
+	It("allocates the CIDRs of the range until it is exhausted", func() {
+		allocated := []string{}
+		for i := 0; i < 4; i++ {
+			cidr, err := allocator.Allocate("vpc")
+			Expect(err).ToNot(HaveOccurred())
+			allocated = append(allocated, cidr)
+		}
+		Expect(allocated).To(Equal([]string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}))
+
+		_, err := allocator.Allocate("vpc")
+		Expect(err).To(MatchError("no CIDR /24 left in 10.0.0.0/22"))
+
+		Expect(allocator.Release("10.0.1.0/24")).To(Succeed())
+		Expect(allocator.Allocate("other-vpc")).To(Equal("10.0.1.0/24"))
+		allocations, err := allocator.Allocations()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allocations).To(HaveLen(4))
+		Expect(allocations["10.0.1.0/24"].Owner).To(Equal("other-vpc"))
+	})
+
+	It("rejects an invalid range", func() {
+		_, err := allocator.Range("10.0.0.0/22", 20).Allocate("vpc")
+		Expect(err).To(MatchError("netmask length 20 is larger than the range 10.0.0.0/22"))
+		_, err = allocator.Range("10.0.0.0", 24).Allocate("vpc")
+		Expect(err).To(MatchError(ContainSubstring("invalid CIDR range 10.0.0.0")))
+	})
+
+	It("hands out the allocations older than the lease again", func() {
+		content, err := json.Marshal(map[string]FileCIDRAllocation{
+			"10.0.0.0/24": {Owner: "leaked-vpc", AllocatedAt: time.Now().Add(-2 * time.Hour)},
+			"10.0.1.0/24": {Owner: "live-vpc", AllocatedAt: time.Now().Add(-time.Minute)},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(path, content, 0600)).To(Succeed())
+
+		Expect(allocator.Allocate("vpc")).To(Equal("10.0.2.0/24"))
+		Expect(allocator.LeaseDuration(time.Hour).Allocate("vpc")).To(Equal("10.0.0.0/24"))
+		allocations, err := allocator.Allocations()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allocations["10.0.0.0/24"].Owner).To(Equal("vpc"))
+		Expect(allocations["10.0.1.0/24"].Owner).To(Equal("live-vpc"))
+	})
+
+	It("ignores the release of a CIDR that is not allocated", func() {
+		Expect(allocator.Allocate("vpc")).To(Equal("10.0.0.0/24"))
+		Expect(allocator.Release("10.0.3.0/24")).To(Succeed())
+		Expect(allocator.Release("10.0.3.0/24")).To(Succeed())
+		allocations, err := allocator.Allocations()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allocations).To(HaveKey("10.0.0.0/24"))
+		Expect(allocations).To(HaveLen(1))
+	})
+
+	It("never hands the same CIDR out to concurrent allocators of the file", func() {
+		allocated := make(chan string, 8)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				cidr, err := NewFileCIDRAllocator(path).Range("10.0.0.0/21", 24).Allocate("vpc")
+				Expect(err).ToNot(HaveOccurred())
+				allocated <- cidr
+			}()
+		}
+		wg.Wait()
+		close(allocated)
+		unique := map[string]bool{}
+		for cidr := range allocated {
+			unique[cidr] = true
+		}
+		Expect(unique).To(HaveLen(8))
+		Expect(path + ".lock").ToNot(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("Lock file", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "allocations.json.lock")
+	})
+
+	It("removes a stale lock file", func() {
+		Expect(os.WriteFile(path, []byte("1-1"), 0600)).To(Succeed())
+		old := time.Now().Add(-2 * allocatorStaleLockAge)
+		Expect(os.Chtimes(path, old, old)).To(Succeed())
+
+		unlock, err := lockFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.ReadFile(path)).ToNot(Equal([]byte("1-1")))
+		unlock()
+		Expect(path).ToNot(BeAnExistingFile())
+		matches, err := filepath.Glob(path + ".stale-*")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(BeEmpty())
+	})
+
+	It("keeps a fresh lock file", func() {
+		Expect(os.WriteFile(path, []byte("1-1"), 0600)).To(Succeed())
+		Expect(removeStaleLock(path)).To(BeFalse())
+		Expect(os.ReadFile(path)).To(Equal([]byte("1-1")))
+	})
+
+	It("leaves a lock file taken over by another holder", func() {
+		unlock, err := lockFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(path, []byte("1-1"), 0600)).To(Succeed())
+		unlock()
+		Expect(os.ReadFile(path)).To(Equal([]byte("1-1")))
+	})
+})
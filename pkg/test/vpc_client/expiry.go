@@ -0,0 +1,274 @@
+package vpc_client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
+	CON "github.com/openshift-online/ocm-common/pkg/aws/consts"
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// helperInstanceTTL returns the time to live of the bastion and proxy instances of the VPC
+func (vpc *VPC) helperInstanceTTL() time.Duration {
+	if vpc.HelperInstanceTTL > 0 {
+		return vpc.HelperInstanceTTL
+	}
+	return CON.DefaultHelperInstanceTTL
+}
+
+// helperTags returns the tags of a helper instance and of its key pair and EIP, including the expiry tag
+func (vpc *VPC) helperTags(name string) map[string]string {
+	return map[string]string{
+		"Name":                 name,
+		CON.HelperExpiryTagKey: time.Now().Add(vpc.helperInstanceTTL()).UTC().Format(time.RFC3339),
+	}
+}
+
+// launchHelperInstance launches a bastion or proxy instance that terminates itself once its time to live is
+// reached: the instance is terminated on shutdown and the shutdown is scheduled by the user data.
+// The user data is base64 encoded, when empty a script only scheduling the shutdown is used, see
+// scheduleShutdownUserData for the supported formats.
+func (vpc *VPC) launchHelperInstance(subnetID string, imageID string, keyName string, securityGroupID string,
+	userData string, tags map[string]string) (*ec2.RunInstancesOutput, error) {
+	userData, err := scheduleShutdownUserData(userData, vpc.helperInstanceTTL())
+	if err != nil {
+		return nil, err
+	}
+	awsTags := []types.Tag{}
+	for key, value := range tags {
+		awsTags = append(awsTags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	input := &ec2.RunInstancesInput{
+		ImageId:                           aws.String(imageID),
+		MinCount:                          aws.Int32(1),
+		MaxCount:                          aws.Int32(1),
+		InstanceType:                      types.InstanceTypeT3Medium,
+		KeyName:                           aws.String(keyName),
+		SecurityGroupIds:                  []string{securityGroupID},
+		SubnetId:                          aws.String(subnetID),
+		UserData:                          aws.String(userData),
+		InstanceInitiatedShutdownBehavior: types.ShutdownBehaviorTerminate,
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeInstance,
+				Tags:         awsTags,
+			},
+		},
+	}
+	return vpc.AWSClient.RunInstances(input, true)
+}
+
+// tagHelperEIP tags the EIP with the public IP with the tags of the helper instance, so that it can be swept
+func (vpc *VPC) tagHelperEIP(publicIP string, tags map[string]string) error {
+	addresses, err := vpc.AWSClient.ListAddresses(map[string][]string{"public-ip": {publicIP}})
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if _, err = vpc.AWSClient.TagResource(*address.AllocationId, tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloudInitContentTypes are the MIME types of the cloud-init user data formats, by the prefix of their first line
+var cloudInitContentTypes = []struct {
+	prefix      string
+	contentType string
+}{
+	{"#!", "text/x-shellscript"},
+	{"#cloud-config", "text/cloud-config"},
+	{"#include", "text/x-include-url"},
+	{"#cloud-boothook", "text/cloud-boothook"},
+	{"#part-handler", "text/part-handler"},
+	{"#upstart-job", "text/upstart-job"},
+}
+
+// userDataPart is a part of a MIME multipart user data
+type userDataPart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// scheduleShutdownUserData adds a scheduled shutdown to the base64 encoded user data. The shutdown is added to a
+// shell script, added as a shell script part to a MIME multipart user data and a cloud-init user data, like a
+// cloud-config, is wrapped in a MIME multipart user data with the shell script part. Other user data, like
+// compressed ones, is kept unchanged: the instance is then only terminated by SweepExpiredHelperInstances.
+func scheduleShutdownUserData(userData string, ttl time.Duration) (string, error) {
+	content := "#!/bin/bash\n"
+	if userData != "" {
+		decoded, err := base64.StdEncoding.DecodeString(userData)
+		if err != nil {
+			return "", fmt.Errorf("user data should be base64 encoded: %s", err)
+		}
+		content = string(decoded)
+	}
+	minutes := int(math.Max(1, math.Ceil(ttl.Minutes())))
+	shutdown := fmt.Sprintf("shutdown -h +%d\n", minutes)
+	shutdownPart := userDataPart{
+		header: textproto.MIMEHeader{"Content-Type": {`text/x-shellscript; charset="us-ascii"`}},
+		body:   []byte("#!/bin/bash\n" + shutdown),
+	}
+
+	if strings.HasPrefix(content, "#!") {
+		shebang, body, _ := strings.Cut(content, "\n")
+		return base64.StdEncoding.EncodeToString([]byte(shebang + "\n" + shutdown + body)), nil
+	}
+	if parts, ok := multipartUserDataParts(content); ok {
+		return base64.StdEncoding.EncodeToString(multipartUserData(append(parts, shutdownPart))), nil
+	}
+	for _, format := range cloudInitContentTypes {
+		if strings.HasPrefix(content, format.prefix) {
+			part := userDataPart{
+				header: textproto.MIMEHeader{"Content-Type": {format.contentType + `; charset="us-ascii"`}},
+				body:   []byte(content),
+			}
+			return base64.StdEncoding.EncodeToString(multipartUserData([]userDataPart{part, shutdownPart})), nil
+		}
+	}
+	log.LogWarning("The format of the user data is unknown, no shutdown is scheduled: the helper instance will " +
+		"be terminated by the sweep of the expired instances")
+	return userData, nil
+}
+
+// multipartUserDataParts returns the parts of a MIME multipart user data, ok is false for other user data
+func multipartUserDataParts(content string) (parts []userDataPart, ok bool) {
+	message, err := mail.ReadMessage(strings.NewReader(content))
+	if err != nil {
+		return nil, false
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, false
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts, true
+		}
+		if err != nil {
+			return nil, false
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, userDataPart{header: part.Header, body: body})
+	}
+}
+
+// multipartUserData returns the MIME multipart user data with the parts
+func multipartUserData(parts []userDataPart) []byte {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, part := range parts {
+		// writing to a bytes.Buffer doesn't fail
+		partWriter, _ := writer.CreatePart(part.header)
+		_, _ = partWriter.Write(part.body)
+	}
+	_ = writer.Close()
+	header := fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\nMIME-Version: 1.0\n\n", writer.Boundary())
+	return append([]byte(header), body.Bytes()...)
+}
+
+// isExpired returns true if the resource has an expiry tag in the past
+func isExpired(tags []types.Tag, now time.Time) bool {
+	for _, tag := range tags {
+		if tag.Key == nil || *tag.Key != CON.HelperExpiryTagKey || tag.Value == nil {
+			continue
+		}
+		expiry, err := time.Parse(time.RFC3339, *tag.Value)
+		if err != nil {
+			log.LogWarning("Ignoring invalid expiry tag value %s", *tag.Value)
+			return false
+		}
+		return now.After(expiry)
+	}
+	return false
+}
+
+// SweepExpiredHelperInstances terminates the bastion and proxy instances of the region of the client whose expiry
+// tag is in the past, then releases the expired EIPs and deletes the expired key pairs, including the ones of
+// instances that already terminated themselves. Resources without expiry tag are left untouched.
+func SweepExpiredHelperInstances(awsClient *aws_client.AWSClient) error {
+	now := time.Now()
+	var errs []error
+	instances, err := awsClient.ListInstances([]string{}, map[string][]string{
+		"tag-key":  {CON.HelperExpiryTagKey},
+		"tag:Name": {CON.BastionName, CON.ProxyName},
+		"instance-state-name": {
+			string(types.InstanceStateNamePending),
+			string(types.InstanceStateNameRunning),
+			string(types.InstanceStateNameStopping),
+			string(types.InstanceStateNameStopped),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	expiredInstances := []string{}
+	for _, instance := range instances {
+		if isExpired(instance.Tags, now) {
+			expiredInstances = append(expiredInstances, *instance.InstanceId)
+		}
+	}
+	if len(expiredInstances) > 0 {
+		log.LogInfo("Terminating expired helper instances %s", strings.Join(expiredInstances, ","))
+		if err = awsClient.TerminateInstances(expiredInstances, true, 20); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	addresses, err := awsClient.ListAddresses(map[string][]string{"tag-key": {CON.HelperExpiryTagKey}})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, address := range addresses {
+		if !isExpired(address.Tags, now) {
+			continue
+		}
+		if address.AssociationId != nil {
+			if _, err = awsClient.DisassociateAddress(*address.AssociationId); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if _, err = awsClient.ReleaseAddress(*address.AllocationId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	keyPairs, err := awsClient.ListKeyPairs(map[string][]string{"tag-key": {CON.HelperExpiryTagKey}})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, keyPair := range keyPairs {
+		if !isExpired(keyPair.Tags, now) {
+			continue
+		}
+		if _, err = awsClient.DeleteKeyPair(*keyPair.KeyName); err != nil {
+			log.LogError("Delete expired key pair %s failed: %s", *keyPair.KeyName, err)
+			errs = append(errs, err)
+		} else {
+			log.LogInfo("Deleted expired key pair %s", *keyPair.KeyName)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package vpc_client
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	CON "github.com/openshift-online/ocm-common/pkg/aws/consts"
+)
+
+var _ = Describe("Helper instance expiry", func() {
+	encode := func(content string) string {
+		return base64.StdEncoding.EncodeToString([]byte(content))
+	}
+
+	// parts returns the content type and body of the parts of a base64 encoded MIME multipart user data
+	parts := func(userData string) []string {
+		decoded, err := base64.StdEncoding.DecodeString(userData)
+		Expect(err).ToNot(HaveOccurred())
+		message, err := mail.ReadMessage(strings.NewReader(string(decoded)))
+		Expect(err).ToNot(HaveOccurred())
+		mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mediaType).To(Equal("multipart/mixed"))
+		result := []string{}
+		reader := multipart.NewReader(message.Body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return result
+			}
+			Expect(err).ToNot(HaveOccurred())
+			body, err := io.ReadAll(part)
+			Expect(err).ToNot(HaveOccurred())
+			result = append(result, part.Header.Get("Content-Type")+"\n"+string(body))
+		}
+	}
+
+	DescribeTable("schedules the shutdown of a script",
+		func(userData string, ttl time.Duration, expected string) {
+			result, err := scheduleShutdownUserData(userData, ttl)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(encode(expected)))
+		},
+		Entry("empty user data", "", time.Hour, "#!/bin/bash\nshutdown -h +60\n"),
+		Entry("script", encode("#!/bin/sh\necho ready\n"), time.Hour, "#!/bin/sh\nshutdown -h +60\necho ready\n"),
+		Entry("script without newline", encode("#!/bin/sh"), time.Hour, "#!/bin/sh\nshutdown -h +60\n"),
+		Entry("partial minute", "", 90*time.Second, "#!/bin/bash\nshutdown -h +2\n"),
+		Entry("no ttl", "", time.Duration(0), "#!/bin/bash\nshutdown -h +1\n"),
+	)
+
+	DescribeTable("adds a shutdown script part to the other cloud-init formats",
+		func(content string, expected []string) {
+			result, err := scheduleShutdownUserData(encode(content), 2*time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parts(result)).To(Equal(expected))
+		},
+		Entry("cloud-config", "#cloud-config\npackages:\n  - squid\n", []string{
+			"text/cloud-config; charset=\"us-ascii\"\n#cloud-config\npackages:\n  - squid\n",
+			"text/x-shellscript; charset=\"us-ascii\"\n#!/bin/bash\nshutdown -h +120\n",
+		}),
+		Entry("include", "#include\nhttps://example.com/user-data\n", []string{
+			"text/x-include-url; charset=\"us-ascii\"\n#include\nhttps://example.com/user-data\n",
+			"text/x-shellscript; charset=\"us-ascii\"\n#!/bin/bash\nshutdown -h +120\n",
+		}),
+		Entry("MIME multipart", "Content-Type: multipart/mixed; boundary=\"frontier\"\nMIME-Version: 1.0\n\n"+
+			"--frontier\nContent-Type: text/cloud-config\n\n#cloud-config\nruncmd: [ls]\n"+
+			"--frontier\nContent-Type: text/x-shellscript\n\n#!/bin/bash\necho ready\n--frontier--\n", []string{
+			"text/cloud-config\n#cloud-config\nruncmd: [ls]",
+			"text/x-shellscript\n#!/bin/bash\necho ready",
+			"text/x-shellscript; charset=\"us-ascii\"\n#!/bin/bash\nshutdown -h +120\n",
+		}),
+	)
+
+	It("keeps the user data of an unknown format", func() {
+		userData := base64.StdEncoding.EncodeToString([]byte{0x1f, 0x8b, 0x08, 0x00})
+		Expect(scheduleShutdownUserData(userData, time.Hour)).To(Equal(userData))
+		userData = encode("not a script")
+		Expect(scheduleShutdownUserData(userData, time.Hour)).To(Equal(userData))
+	})
+
+	It("fails when the user data isn't base64 encoded", func() {
+		_, err := scheduleShutdownUserData("#!/bin/bash", time.Hour)
+		Expect(err).To(MatchError(ContainSubstring("user data should be base64 encoded")))
+	})
+
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	DescribeTable("checks the expiry tag",
+		func(tags []types.Tag, expected bool) {
+			Expect(isExpired(tags, now)).To(Equal(expected))
+		},
+		Entry("past expiry", []types.Tag{
+			{Key: aws.String("Name"), Value: aws.String(CON.BastionName)},
+			{Key: aws.String(CON.HelperExpiryTagKey), Value: aws.String("2024-06-15T11:00:00Z")},
+		}, true),
+		Entry("future expiry", []types.Tag{
+			{Key: aws.String(CON.HelperExpiryTagKey), Value: aws.String("2024-06-15T13:00:00Z")},
+		}, false),
+		Entry("other time zone", []types.Tag{
+			{Key: aws.String(CON.HelperExpiryTagKey), Value: aws.String("2024-06-15T13:30:00+02:00")},
+		}, true),
+		Entry("invalid expiry", []types.Tag{
+			{Key: aws.String(CON.HelperExpiryTagKey), Value: aws.String("tomorrow")},
+		}, false),
+		Entry("empty expiry", []types.Tag{{Key: aws.String(CON.HelperExpiryTagKey)}}, false),
+		Entry("no expiry tag", []types.Tag{{Key: aws.String("Name"), Value: aws.String(CON.ProxyName)}}, false),
+		Entry("no tags", nil, false),
+	)
+})
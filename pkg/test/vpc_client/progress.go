@@ -0,0 +1,189 @@
+package vpc_client
+
+import (
+	"strings"
+	"time"
+
+	"github.com/openshift-online/ocm-common/pkg/utils/saga"
+)
+
+// Operations reporting progress events
+const (
+	OperationPrepareVPC          = "PrepareVPC"
+	OperationCreateVPCChain      = "CreateVPCChain"
+	OperationDeleteVPCChain      = "DeleteVPCChain"
+	OperationLaunchBastion       = "LaunchBastion"
+	OperationLaunchProxyInstance = "LaunchProxyInstance"
+)
+
+// ProgressPhase is the stage of a step reported by a progress event
+type ProgressPhase string
+
+const (
+	ProgressStarted  ProgressPhase = "started"
+	ProgressFinished ProgressPhase = "finished"
+	ProgressFailed   ProgressPhase = "failed"
+	// ProgressSkipped is reported for the steps completed by a previous run of a resumed operation
+	ProgressSkipped ProgressPhase = "skipped"
+	// ProgressRolledBack is reported for the completed steps undone after the failure of a later one
+	ProgressRolledBack ProgressPhase = "rolled-back"
+	// ProgressRollbackFailed is reported for the steps whose changes couldn't be undone, their resources are left
+	// behind
+	ProgressRollbackFailed ProgressPhase = "rollback-failed"
+)
+
+// ProgressEvent reports the progress of a step of a long-running operation
+type ProgressEvent struct {
+	Operation    string
+	Step         string
+	Phase        ProgressPhase
+	ResourceType string
+	// ResourceID is the ID of the resource handled by the step, when known
+	ResourceID string
+	Time       time.Time
+	// Elapsed is the duration of the step, it is set for the finished, failed, rolled back and rollback failed phases
+	Elapsed time.Duration
+	// OperationElapsed is the time since the operation started
+	OperationElapsed time.Duration
+	// Percent is the share of the steps of the operation that are completed
+	Percent int
+	Err     error
+}
+
+// ProgressHandler receives the progress events, it is called synchronously by the operations
+type ProgressHandler func(event ProgressEvent)
+
+// ProgressChannel returns a handler sending the events to the channel. The operations block until the events
+// are received, so the channel must be drained while they run.
+func ProgressChannel(events chan<- ProgressEvent) ProgressHandler {
+	return func(event ProgressEvent) {
+		events <- event
+	}
+}
+
+// stepResource describes the resource handled by a step of the provisioning flows
+type stepResource struct {
+	resourceType string
+	id           func(vpc *VPC, state saga.State) string
+}
+
+func stateValue(key string) func(vpc *VPC, state saga.State) string {
+	return func(vpc *VPC, state saga.State) string {
+		return state.Get(key)
+	}
+}
+
+var stepResources = map[string]stepResource{
+	"allocate-cidr":            {"cidr", stateValue(vpcCIDRKey)},
+	"create-vpc":               {"vpc", stateValue(vpcIDKey)},
+	"enable-dns-hostnames":     {"vpc", stateValue(vpcIDKey)},
+	"prepare-internet-gateway": {"internet-gateway", nil},
+	"create-subnets": {"subnet", func(vpc *VPC, state saga.State) string {
+		ids := []string{}
+		for _, subnet := range vpc.SubnetList {
+			ids = append(ids, subnet.ID)
+		}
+		return strings.Join(ids, ",")
+	}},
+	"prepare-public-subnet": {"subnet", stateValue(subnetIDKey)},
+	"create-security-group": {"security-group", stateValue(securityGroupIDKey)},
+	"create-key-pair":       {"key-pair", stateValue(keyNameKey)},
+	"launch-instance":       {"instance", stateValue(instanceIDKey)},
+	"associate-eip":         {"eip", stateValue(publicIPKey)},
+	"setup-proxy-server":    {"instance", stateValue(instanceIDKey)},
+}
+
+var sagaPhases = map[saga.Phase]ProgressPhase{
+	saga.StepStarted:    ProgressStarted,
+	saga.StepFinished:   ProgressFinished,
+	saga.StepFailed:     ProgressFailed,
+	saga.StepSkipped:    ProgressSkipped,
+	saga.StepUndone:     ProgressRolledBack,
+	saga.StepUndoFailed: ProgressRollbackFailed,
+}
+
+// sagaObserver translates the step events of a provisioning flow into progress events of the operation
+func (vpc *VPC) sagaObserver(operation string) saga.Observer {
+	start := time.Now()
+	return func(event saga.StepEvent) {
+		if vpc.ProgressHandler == nil {
+			return
+		}
+		completed := event.Index
+		if event.Phase == saga.StepFinished || event.Phase == saga.StepSkipped {
+			completed++
+		}
+		progress := ProgressEvent{
+			Operation:        operation,
+			Step:             event.Step,
+			Phase:            sagaPhases[event.Phase],
+			Time:             time.Now(),
+			Elapsed:          event.Elapsed,
+			OperationElapsed: time.Since(start),
+			Percent:          completed * 100 / event.Total,
+			Err:              event.Err,
+		}
+		if resource, ok := stepResources[event.Step]; ok {
+			progress.ResourceType = resource.resourceType
+			if resource.id != nil {
+				progress.ResourceID = resource.id(vpc, event.State)
+			}
+		}
+		vpc.ProgressHandler(progress)
+	}
+}
+
+// progressTracker reports the progress of the operations that are not provisioning flows
+type progressTracker struct {
+	handler   ProgressHandler
+	operation string
+	total     int
+	completed int
+	start     time.Time
+}
+
+func newProgressTracker(handler ProgressHandler, operation string, total int) *progressTracker {
+	return &progressTracker{
+		handler:   handler,
+		operation: operation,
+		total:     total,
+		start:     time.Now(),
+	}
+}
+
+// run runs the step and reports its start and its end
+func (t *progressTracker) run(step string, resourceType string, resourceID string, fn func() error) error {
+	t.emit(step, ProgressStarted, resourceType, resourceID, 0, nil)
+	start := time.Now()
+	err := fn()
+	if err != nil {
+		t.emit(step, ProgressFailed, resourceType, resourceID, time.Since(start), err)
+		return err
+	}
+	t.completed++
+	t.emit(step, ProgressFinished, resourceType, resourceID, time.Since(start), nil)
+	return nil
+}
+
+func (t *progressTracker) emit(step string, phase ProgressPhase, resourceType string, resourceID string,
+	elapsed time.Duration, err error) {
+	if t.handler == nil {
+		return
+	}
+	percent := 100
+	if t.total > 0 {
+		percent = t.completed * 100 / t.total
+	}
+	t.handler(ProgressEvent{
+		Operation:        t.operation,
+		Step:             step,
+		Phase:            phase,
+		ResourceType:     resourceType,
+		ResourceID:       resourceID,
+		Time:             time.Now(),
+		Elapsed:          elapsed,
+		OperationElapsed: time.Since(t.start),
+		Percent:          percent,
+		Err:              err,
+	})
+}
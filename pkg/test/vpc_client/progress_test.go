@@ -0,0 +1,144 @@
+package vpc_client
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-common/pkg/utils/saga"
+)
+
+var _ = Describe("Progress", func() {
+	var events []ProgressEvent
+
+	record := func(event ProgressEvent) {
+		events = append(events, event)
+	}
+
+	// summary keeps the fields of the event that don't depend on the time
+	summary := func(event ProgressEvent) ProgressEvent {
+		return ProgressEvent{
+			Operation:    event.Operation,
+			Step:         event.Step,
+			Phase:        event.Phase,
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			Percent:      event.Percent,
+			Err:          event.Err,
+		}
+	}
+
+	BeforeEach(func() {
+		events = nil
+	})
+
+	Describe("tracker", func() {
+		It("reports the start and the end of the steps", func() {
+			tracker := newProgressTracker(record, OperationDeleteVPCChain, 2)
+			Expect(tracker.run("delete-instances", "instance", "i-0123", func() error {
+				time.Sleep(time.Millisecond)
+				return nil
+			})).To(Succeed())
+			failure := fmt.Errorf("dependency violation")
+			Expect(tracker.run("delete-vpc", "vpc", "vpc-0123", func() error {
+				return failure
+			})).To(MatchError(failure))
+
+			Expect(events).To(HaveLen(4))
+			Expect(summary(events[0])).To(Equal(ProgressEvent{Operation: OperationDeleteVPCChain, Step: "delete-instances",
+				Phase: ProgressStarted, ResourceType: "instance", ResourceID: "i-0123", Percent: 0}))
+			Expect(events[0].Elapsed).To(BeZero())
+			Expect(summary(events[1])).To(Equal(ProgressEvent{Operation: OperationDeleteVPCChain, Step: "delete-instances",
+				Phase: ProgressFinished, ResourceType: "instance", ResourceID: "i-0123", Percent: 50}))
+			Expect(events[1].Elapsed).To(BeNumerically(">=", time.Millisecond))
+			Expect(summary(events[2])).To(Equal(ProgressEvent{Operation: OperationDeleteVPCChain, Step: "delete-vpc",
+				Phase: ProgressStarted, ResourceType: "vpc", ResourceID: "vpc-0123", Percent: 50}))
+			Expect(summary(events[3])).To(Equal(ProgressEvent{Operation: OperationDeleteVPCChain, Step: "delete-vpc",
+				Phase: ProgressFailed, ResourceType: "vpc", ResourceID: "vpc-0123", Percent: 50, Err: failure}))
+		})
+
+		It("reports a complete operation without steps", func() {
+			tracker := newProgressTracker(record, OperationLaunchBastion, 0)
+			Expect(tracker.run("launch-instance", "instance", "", func() error { return nil })).To(Succeed())
+			Expect(events[1].Percent).To(Equal(100))
+		})
+
+		It("runs the steps without handler", func() {
+			ran := false
+			tracker := newProgressTracker(nil, OperationLaunchBastion, 1)
+			Expect(tracker.run("launch-instance", "instance", "", func() error {
+				ran = true
+				return nil
+			})).To(Succeed())
+			Expect(ran).To(BeTrue())
+		})
+	})
+
+	Describe("saga observer", func() {
+		var vpc *VPC
+
+		BeforeEach(func() {
+			vpc = &VPC{
+				ProgressHandler: record,
+				SubnetList:      []*Subnet{{ID: "subnet-a"}, {ID: "subnet-b"}},
+			}
+		})
+
+		DescribeTable("translates the step events",
+			func(event saga.StepEvent, expected ProgressEvent) {
+				event.Total = 4
+				vpc.sagaObserver(OperationPrepareVPC)(event)
+				Expect(events).To(HaveLen(1))
+				expected.Operation = OperationPrepareVPC
+				Expect(summary(events[0])).To(Equal(expected))
+			},
+			Entry("started step",
+				saga.StepEvent{Step: "create-vpc", Index: 1, Phase: saga.StepStarted,
+					State: saga.State{vpcIDKey: "vpc-0123"}},
+				ProgressEvent{Step: "create-vpc", Phase: ProgressStarted, ResourceType: "vpc",
+					ResourceID: "vpc-0123", Percent: 25}),
+			Entry("finished step",
+				saga.StepEvent{Step: "create-subnets", Index: 1, Phase: saga.StepFinished, State: saga.State{}},
+				ProgressEvent{Step: "create-subnets", Phase: ProgressFinished, ResourceType: "subnet",
+					ResourceID: "subnet-a,subnet-b", Percent: 50}),
+			Entry("skipped step",
+				saga.StepEvent{Step: "allocate-cidr", Index: 0, Phase: saga.StepSkipped,
+					State: saga.State{vpcCIDRKey: "10.0.0.0/16"}},
+				ProgressEvent{Step: "allocate-cidr", Phase: ProgressSkipped, ResourceType: "cidr",
+					ResourceID: "10.0.0.0/16", Percent: 25}),
+			Entry("failed step",
+				saga.StepEvent{Step: "launch-instance", Index: 3, Phase: saga.StepFailed, State: saga.State{}},
+				ProgressEvent{Step: "launch-instance", Phase: ProgressFailed, ResourceType: "instance", Percent: 75}),
+			Entry("undone step",
+				saga.StepEvent{Step: "create-security-group", Index: 2, Phase: saga.StepUndone,
+					State: saga.State{securityGroupIDKey: "sg-0123"}},
+				ProgressEvent{Step: "create-security-group", Phase: ProgressRolledBack,
+					ResourceType: "security-group", ResourceID: "sg-0123", Percent: 50}),
+			Entry("step failing to be undone",
+				saga.StepEvent{Step: "prepare-internet-gateway", Index: 2, Phase: saga.StepUndoFailed,
+					State: saga.State{}},
+				ProgressEvent{Step: "prepare-internet-gateway", Phase: ProgressRollbackFailed,
+					ResourceType: "internet-gateway", Percent: 50}),
+			Entry("unknown step",
+				saga.StepEvent{Step: "custom", Index: 3, Phase: saga.StepFinished, State: saga.State{}},
+				ProgressEvent{Step: "custom", Phase: ProgressFinished, Percent: 100}),
+		)
+
+		It("reports the error of the step", func() {
+			failure := fmt.Errorf("unauthorized")
+			vpc.sagaObserver(OperationPrepareVPC)(saga.StepEvent{Step: "create-key-pair", Total: 1,
+				Phase: saga.StepUndoFailed, Err: failure, Elapsed: time.Second, State: saga.State{}})
+			Expect(events[0].Err).To(MatchError(failure))
+			Expect(events[0].Elapsed).To(Equal(time.Second))
+		})
+
+		It("reports nothing without handler", func() {
+			vpc.ProgressHandler = nil
+			vpc.sagaObserver(OperationPrepareVPC)(saga.StepEvent{Step: "create-vpc", Total: 1,
+				Phase: saga.StepStarted})
+			Expect(events).To(BeEmpty())
+		})
+	})
+})
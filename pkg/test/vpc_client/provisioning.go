@@ -0,0 +1,521 @@
+package vpc_client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	CON "github.com/openshift-online/ocm-common/pkg/aws/consts"
+	awsErrors "github.com/openshift-online/ocm-common/pkg/aws/errors"
+	"github.com/openshift-online/ocm-common/pkg/file"
+	"github.com/openshift-online/ocm-common/pkg/log"
+	"github.com/openshift-online/ocm-common/pkg/utils/saga"
+)
+
+// Keys of the state shared by the steps of the provisioning flows
+const (
+	vpcCIDRKey            = "vpc-cidr"
+	vpcIDKey              = "vpc-id"
+	internetGatewayKey    = "internet-gateway-id"
+	subnetIDKey           = "subnet-id"
+	subnetSnapshotKey     = "existing-subnet-ids"
+	routeTableSnapshotKey = "existing-route-table-ids"
+	securityGroupIDKey    = "security-group-id"
+	keyNameKey            = "key-name"
+	sshKeyPathKey         = "ssh-key-path"
+	instanceIDKey         = "instance-id"
+	publicIPKey           = "public-ip"
+	proxyCAKey            = "proxy-ca"
+)
+
+// helperInstanceBootTime is the time given to a helper instance to boot before connecting to it with SSH
+const helperInstanceBootTime = 2 * time.Minute
+
+// checkpointFile returns the file saving the progress of the flow, or an empty string when no checkpoint
+// directory is set
+func (vpc *VPC) checkpointFile(flow string) string {
+	if vpc.CheckpointDir == "" {
+		return ""
+	}
+	return filepath.Join(vpc.CheckpointDir, flow+".json")
+}
+
+// restoreCIDR sets the CIDR recorded by the flow, with a new CIDR pool, when the VPC doesn't have it yet
+func (vpc *VPC) restoreCIDR(state saga.State) {
+	if cidr := state.Get(vpcCIDRKey); cidr != "" && cidr != vpc.CIDRValue {
+		vpc.CIDR(cidr).NewCIDRPool()
+	}
+}
+
+// vpcChainSaga returns the flow creating the VPC, its internet gateway and the subnets of the zones.
+// Every step deletes what it created when it or a later step fails. A step run again, when the flow resumes after
+// its failure or the interruption of the process, adopts what a previous attempt created.
+func (vpc *VPC) vpcChainSaga(zones ...string) *saga.Saga {
+	flow := "vpc-chain-" + vpc.VPCName
+	return saga.New(flow).Checkpoint(vpc.checkpointFile(flow)).
+		Step("allocate-cidr",
+			func(ctx context.Context, state saga.State) error {
+				if vpc.CIDRAllocator == nil {
+					state.Set(vpcCIDRKey, vpc.CIDRValue)
+					return nil
+				}
+				cidr, err := vpc.CIDRAllocator.Allocate(vpc.VPCName)
+				if err != nil {
+					return err
+				}
+				state.Set(vpcCIDRKey, cidr)
+				vpc.CIDR(cidr).NewCIDRPool()
+				return nil
+			},
+			func(ctx context.Context, state saga.State) error {
+				if vpc.CIDRAllocator == nil {
+					return nil
+				}
+				return vpc.CIDRAllocator.Release(state.Get(vpcCIDRKey))
+			}).
+		Step("create-vpc",
+			func(ctx context.Context, state saga.State) error {
+				vpc.restoreCIDR(state)
+				if vpcID := state.Get(vpcIDKey); vpcID != "" {
+					log.LogInfo("VPC %s was created by a previous attempt, reusing it", vpcID)
+					vpc.ID(vpcID)
+					return nil
+				}
+				// The VPC of an interrupted attempt is found by its name
+				respVpc, created, err := vpc.AWSClient.EnsureVpc(vpc.CIDRValue, vpc.VPCName)
+				// The VPC is returned when the wait or the tagging fails, it is recorded to be deleted by the undo
+				if respVpc != nil && respVpc.VpcId != nil {
+					state.Set(vpcIDKey, *respVpc.VpcId)
+					vpc.ID(*respVpc.VpcId)
+				}
+				if err != nil {
+					return err
+				}
+				if created {
+					log.LogInfo("VPC created on AWS with id: %s", *respVpc.VpcId)
+				}
+				return nil
+			},
+			func(ctx context.Context, state saga.State) error {
+				if state.Get(vpcIDKey) == "" {
+					return nil
+				}
+				_, err := vpc.AWSClient.DeleteVpc(state.Get(vpcIDKey))
+				if err == nil {
+					vpc.ID("")
+				}
+				return err
+			}).
+		Step("enable-dns-hostnames",
+			func(ctx context.Context, state saga.State) error {
+				vpc.ID(state.Get(vpcIDKey))
+				_, err := vpc.AWSClient.ModifyVpcDnsAttribute(vpc.VpcID, CON.VpcDnsHostnamesAttribute, true)
+				return err
+			}, nil).
+		Step("prepare-internet-gateway",
+			func(ctx context.Context, state saga.State) error {
+				vpc.ID(state.Get(vpcIDKey))
+				igws, err := vpc.AWSClient.ListInternetGateWay(vpc.VpcID)
+				if err != nil || len(igws) != 0 {
+					return err
+				}
+				igw, err := vpc.AWSClient.CreateInternetGateway()
+				if err != nil {
+					return err
+				}
+				state.Set(internetGatewayKey, *igw.InternetGateway.InternetGatewayId)
+				_, err = vpc.AWSClient.AttachInternetGateway(*igw.InternetGateway.InternetGatewayId, vpc.VpcID)
+				return err
+			},
+			func(ctx context.Context, state saga.State) error {
+				vpc.ID(state.Get(vpcIDKey))
+				if err := vpc.DeleteVPCInternetGateWays(); err != nil {
+					return err
+				}
+				// The gateway isn't attached to the VPC when the attachment failed
+				if igwID := state.Get(internetGatewayKey); igwID != "" {
+					_, err := vpc.AWSClient.DeleteInternetGateway(igwID)
+					if err != nil && !awsErrors.IsErrorCode(err, awsErrors.InvalidInternetGatewayID) {
+						return err
+					}
+				}
+				return nil
+			}).
+		Step("create-subnets",
+			func(ctx context.Context, state saga.State) error {
+				vpc.ID(state.Get(vpcIDKey))
+				vpc.restoreCIDR(state)
+				// A previous attempt interrupted before its completion may have left subnets behind
+				if err := vpc.deleteSubnetResources(); err != nil {
+					return err
+				}
+				return vpc.CreateMultiZoneSubnet(zones...)
+			},
+			func(ctx context.Context, state saga.State) error {
+				vpc.ID(state.Get(vpcIDKey))
+				return vpc.deleteSubnetResources()
+			})
+}
+
+// deleteSubnetResources deletes the NAT gateways with their EIPs, the route tables and the subnets of the VPC
+func (vpc *VPC) deleteSubnetResources() error {
+	natGateways, err := vpc.AWSClient.ListNatGateWays(vpc.VpcID)
+	if err != nil {
+		return err
+	}
+	allocationIDs := []string{}
+	for _, natGateway := range natGateways {
+		for _, address := range natGateway.NatGatewayAddresses {
+			if address.AllocationId != nil {
+				allocationIDs = append(allocationIDs, *address.AllocationId)
+			}
+		}
+	}
+	if err = vpc.DeleteVPCNatGateways(vpc.VpcID); err != nil {
+		return err
+	}
+	for _, allocationID := range allocationIDs {
+		if _, err = vpc.AWSClient.ReleaseAddress(allocationID); err != nil {
+			return err
+		}
+	}
+	if err = vpc.DeleteVPCRouteTables(vpc.VpcID); err != nil {
+		return err
+	}
+	if err = vpc.DeleteVPCSubnets(); err != nil {
+		return err
+	}
+	vpc.SubnetList = nil
+	if vpc.CIDRValue != "" {
+		vpc.NewCIDRPool()
+	}
+	return nil
+}
+
+// helperInstanceSaga returns the flow launching a bastion or proxy instance with its security group, key pair
+// and EIP in a public subnet of the zone. The private key is written to privateKeyPath. Every step deletes what
+// it created when it or a later step fails, callers can append steps configuring the instance. As for
+// vpcChainSaga, a step run again adopts what a previous attempt created.
+func (vpc *VPC) helperInstanceSaga(flow string, name string, imageID string, zone string, userData string,
+	keyName string, privateKeyName string, privateKeyPath string, ports ...int32) *saga.Saga {
+	tags := vpc.helperTags(name)
+	return saga.New(flow).Checkpoint(vpc.checkpointFile(flow)).
+		// The subnets and route tables of the VPC are recorded by a step of their own, so that the snapshot is
+		// saved before the public subnet is prepared and a new attempt doesn't take the snapshot again
+		Step("snapshot-subnets",
+			func(ctx context.Context, state saga.State) error {
+				subnetIDs, routeTableIDs, err := vpc.subnetResourceIDs()
+				if err != nil {
+					return err
+				}
+				state.Set(subnetSnapshotKey, strings.Join(subnetIDs, ","))
+				state.Set(routeTableSnapshotKey, strings.Join(routeTableIDs, ","))
+				return nil
+			}, nil).
+		Step("prepare-public-subnet",
+			func(ctx context.Context, state saga.State) error {
+				// The undo only deletes the subnets and route tables that aren't in the snapshot, the ones
+				// PreparePublicSubnet creates. A public subnet of the zone created by a previous attempt is reused.
+				pubSubnet, err := vpc.PreparePublicSubnet(zone)
+				if err != nil {
+					return fmt.Errorf("preparing a subnet in zone %s failed: %s", zone, err)
+				}
+				state.Set(subnetIDKey, pubSubnet.ID)
+				return nil
+			},
+			func(ctx context.Context, state saga.State) error {
+				return vpc.deleteNewSubnetResources(zone, state.Get(subnetSnapshotKey), state.Get(routeTableSnapshotKey))
+			}).
+		Step("create-security-group",
+			func(ctx context.Context, state saga.State) error {
+				// The group of a previous attempt may miss some of its rules, it is created again
+				if err := vpc.deleteHelperSecurityGroups(ports...); err != nil {
+					return err
+				}
+				// The group is returned when its authorization fails, it is recorded to be deleted by the undo
+				sgID, err := vpc.CreateAndAuthorizeDefaultSecurityGroupForProxy(ports...)
+				if sgID != "" {
+					state.Set(securityGroupIDKey, sgID)
+				}
+				return err
+			},
+			func(ctx context.Context, state saga.State) error {
+				if state.Get(securityGroupIDKey) == "" {
+					return nil
+				}
+				_, err := vpc.AWSClient.DeleteSecurityGroup(state.Get(securityGroupIDKey))
+				if err != nil && !awsErrors.IsErrorCode(err, awsErrors.InvalidGroup) {
+					return err
+				}
+				return nil
+			}).
+		Step("create-key-pair",
+			func(ctx context.Context, state saga.State) error {
+				key, created, err := vpc.AWSClient.EnsureKeyPair(keyName)
+				if err != nil {
+					return err
+				}
+				state.Set(keyNameKey, *key.KeyName)
+				// The private key of a key pair created by a previous attempt is only known if it was written
+				sshKeyPath := privateKeyFile(privateKeyName, privateKeyPath)
+				if !created && !file.IfFileExists(sshKeyPath) {
+					log.LogInfo("Private key of key pair %s wasn't written, creating the key pair again", keyName)
+					if err = vpc.DeleteKeyPair([]string{keyName}); err != nil {
+						return err
+					}
+					if key, err = vpc.CreateKeyPair(keyName); err != nil {
+						return err
+					}
+					created = true
+				}
+				if _, err = vpc.AWSClient.TagResource(*key.KeyPairId, tags); err != nil {
+					return fmt.Errorf("add tag for key pair %s failed: %s", *key.KeyPairId, err)
+				}
+				if created {
+					sshKeyPath, err = file.WriteToFile(*key.KeyMaterial, privateKeyName, privateKeyPath)
+					if err != nil {
+						return fmt.Errorf("write private key to %s failed: %s", privateKeyPath, err)
+					}
+				}
+				state.Set(sshKeyPathKey, sshKeyPath)
+				return nil
+			},
+			func(ctx context.Context, state saga.State) error {
+				if sshKeyPath := state.Get(sshKeyPathKey); sshKeyPath != "" {
+					if err := os.Remove(sshKeyPath); err != nil && !os.IsNotExist(err) {
+						return err
+					}
+				}
+				if state.Get(keyNameKey) == "" {
+					return nil
+				}
+				return vpc.DeleteKeyPair([]string{state.Get(keyNameKey)})
+			}).
+		Step("launch-instance",
+			func(ctx context.Context, state saga.State) error {
+				instanceID, err := vpc.findHelperInstance(state.Get(instanceIDKey), name, state.Get(subnetIDKey),
+					state.Get(keyNameKey))
+				if err != nil {
+					return err
+				}
+				if instanceID != "" {
+					log.LogInfo("%s instance %s was launched by a previous attempt, reusing it", name, instanceID)
+					state.Set(instanceIDKey, instanceID)
+					_, err = vpc.AWSClient.WaitForInstancesRunning([]string{instanceID}, 10)
+					return err
+				}
+				instOut, err := vpc.launchHelperInstance(state.Get(subnetIDKey), imageID, state.Get(keyNameKey),
+					state.Get(securityGroupIDKey), userData, tags)
+				// The instance is returned when it doesn't get running, it is recorded to be terminated by the undo
+				if instOut != nil && len(instOut.Instances) != 0 {
+					state.Set(instanceIDKey, *instOut.Instances[0].InstanceId)
+				}
+				if err != nil {
+					return err
+				}
+				log.LogInfo("Launch %s instance %s succeed", name, state.Get(instanceIDKey))
+				return nil
+			},
+			func(ctx context.Context, state saga.State) error {
+				if state.Get(instanceIDKey) == "" {
+					return nil
+				}
+				return vpc.AWSClient.TerminateInstances([]string{state.Get(instanceIDKey)}, true, 20)
+			}).
+		Step("associate-eip",
+			func(ctx context.Context, state saga.State) error {
+				// The EIP associated by a previous attempt is reused
+				addresses, err := vpc.AWSClient.ListAddresses(map[string][]string{
+					"instance-id": {state.Get(instanceIDKey)},
+				})
+				if err != nil {
+					return err
+				}
+				var publicIP string
+				if len(addresses) != 0 {
+					publicIP = aws.ToString(addresses[0].PublicIp)
+				} else if publicIP, err = vpc.AWSClient.AllocateEIPAndAssociateInstance(
+					state.Get(instanceIDKey)); err != nil {
+					return err
+				}
+				state.Set(publicIPKey, publicIP)
+				if err = vpc.tagHelperEIP(publicIP, tags); err != nil {
+					return fmt.Errorf("add tag for EIP %s failed: %s", publicIP, err)
+				}
+				log.LogInfo("Prepare EIP successfully for the %s. Launch with IP: %s", name, publicIP)
+				return nil
+			},
+			func(ctx context.Context, state saga.State) error {
+				if state.Get(publicIPKey) == "" {
+					return nil
+				}
+				return vpc.releaseHelperEIP(state.Get(publicIPKey))
+			})
+}
+
+// privateKeyFile returns the path of the private key file written by file.WriteToFile
+func privateKeyFile(privateKeyName string, privateKeyPath string) string {
+	return fmt.Sprintf("%s/%s", privateKeyPath, privateKeyName)
+}
+
+// deleteHelperSecurityGroups deletes the security group of the bastion, opening the ports, or of the proxy in the VPC
+func (vpc *VPC) deleteHelperSecurityGroups(ports ...int32) error {
+	namePrefix, _ := proxySecurityGroup(ports...)
+	securityGroups, err := vpc.AWSClient.ListSecurityGroupsByFilters(map[string][]string{
+		"vpc-id":     {vpc.VpcID},
+		"group-name": {namePrefix + "-0"},
+	})
+	if err != nil {
+		return err
+	}
+	for _, securityGroup := range securityGroups {
+		log.LogInfo("Deleting security group %s left by a previous attempt", aws.ToString(securityGroup.GroupId))
+		if _, err = vpc.AWSClient.DeleteSecurityGroup(aws.ToString(securityGroup.GroupId)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findHelperInstance returns the ID of the instance launched by a previous attempt: the recorded one or, when the
+// attempt was interrupted before recording it, the instance with the name and key pair in the subnet. An empty ID
+// is returned when there is none.
+func (vpc *VPC) findHelperInstance(instanceID string, name string, subnetID string, keyName string) (string, error) {
+	filters := map[string][]string{
+		"instance-state-name": {
+			string(types.InstanceStateNamePending),
+			string(types.InstanceStateNameRunning),
+		},
+	}
+	instanceIDs := []string{}
+	if instanceID != "" {
+		instanceIDs = append(instanceIDs, instanceID)
+	} else {
+		filters["tag:Name"] = []string{name}
+		filters["subnet-id"] = []string{subnetID}
+		filters["key-name"] = []string{keyName}
+	}
+	instances, err := vpc.AWSClient.ListInstances(instanceIDs, filters)
+	if err != nil {
+		// the recorded instance may be gone for long
+		if instanceID != "" && awsErrors.IsErrorCode(err, awsErrors.InvalidInstanceID) {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(instances) == 0 {
+		return "", nil
+	}
+	return aws.ToString(instances[0].InstanceId), nil
+}
+
+// subnetResourceIDs returns the IDs of the subnets and of the route tables of the VPC
+func (vpc *VPC) subnetResourceIDs() ([]string, []string, error) {
+	subnets, err := vpc.AWSClient.ListSubnetByVpcID(vpc.VpcID)
+	if err != nil {
+		return nil, nil, err
+	}
+	routeTables, err := vpc.AWSClient.ListCustomerRouteTables(vpc.VpcID)
+	if err != nil {
+		return nil, nil, err
+	}
+	subnetIDs := []string{}
+	for _, subnet := range subnets {
+		subnetIDs = append(subnetIDs, *subnet.SubnetId)
+	}
+	routeTableIDs := []string{}
+	for _, routeTable := range routeTables {
+		routeTableIDs = append(routeTableIDs, *routeTable.RouteTableId)
+	}
+	return subnetIDs, routeTableIDs, nil
+}
+
+// deleteNewSubnetResources deletes the subnets of the zone and the route tables that aren't in the recorded
+// comma-separated IDs, like the ones left by a public subnet creation failing half way. The route tables associated
+// with other subnets are kept.
+func (vpc *VPC) deleteNewSubnetResources(zone string, existingSubnetIDs string, existingRouteTableIDs string) error {
+	existing := map[string]bool{}
+	for _, id := range strings.Split(existingSubnetIDs+","+existingRouteTableIDs, ",") {
+		existing[id] = true
+	}
+	subnets, err := vpc.AWSClient.ListSubnetByVpcID(vpc.VpcID)
+	if err != nil {
+		return err
+	}
+	newSubnets := map[string]bool{}
+	for _, subnet := range subnets {
+		if !existing[*subnet.SubnetId] && aws.ToString(subnet.AvailabilityZone) == zone {
+			newSubnets[*subnet.SubnetId] = true
+		}
+	}
+	routeTables, err := vpc.AWSClient.ListCustomerRouteTables(vpc.VpcID)
+	if err != nil {
+		return err
+	}
+	for _, routeTable := range routeTables {
+		if existing[*routeTable.RouteTableId] {
+			continue
+		}
+		ours := true
+		for _, association := range routeTable.Associations {
+			if association.SubnetId != nil && !newSubnets[*association.SubnetId] {
+				ours = false
+			}
+		}
+		if !ours {
+			continue
+		}
+		if err = vpc.AWSClient.DeleteRouteTableChain(*routeTable.RouteTableId); err != nil {
+			return err
+		}
+	}
+	for subnetID := range newSubnets {
+		if _, err = vpc.AWSClient.DeleteSubnet(subnetID); err != nil {
+			return err
+		}
+	}
+	if len(newSubnets) != 0 {
+		vpc.SubnetList = nil
+	}
+	return nil
+}
+
+// releaseHelperEIP disassociates and releases the EIP with the public IP
+func (vpc *VPC) releaseHelperEIP(publicIP string) error {
+	addresses, err := vpc.AWSClient.ListAddresses(map[string][]string{"public-ip": {publicIP}})
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if address.AssociationId != nil {
+			if _, err = vpc.AWSClient.DisassociateAddress(*address.AssociationId); err != nil {
+				return err
+			}
+		}
+		if _, err = vpc.AWSClient.ReleaseAddress(*address.AllocationId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helperInstance returns the instance launched by a helper instance flow, with its public IP
+func (vpc *VPC) helperInstance(state saga.State) (*types.Instance, error) {
+	instances, err := vpc.AWSClient.ListInstances([]string{state.Get(instanceIDKey)})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("instance %s not found", state.Get(instanceIDKey))
+	}
+	inst := instances[0]
+	publicIP := state.Get(publicIPKey)
+	inst.PublicIpAddress = &publicIP
+	return &inst, nil
+}
@@ -1,6 +1,7 @@
 package vpc_client
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"time"
@@ -8,8 +9,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	CON "github.com/openshift-online/ocm-common/pkg/aws/consts"
-	"github.com/openshift-online/ocm-common/pkg/file"
 	"github.com/openshift-online/ocm-common/pkg/log"
+	"github.com/openshift-online/ocm-common/pkg/utils/saga"
 )
 
 // FindProxyLaunchImage will try to find a proper image based on the filters to launch the proxy instance
@@ -68,80 +69,50 @@ func (vpc *VPC) FindProxyLaunchImage() (string, error) {
 // LaunchProxyInstance will launch a proxy instance on the indicated zone.
 // If set imageID to empty, it will find the proxy image in the ProxyImageMap map
 // LaunchProxyInstance will return proxyInstance detail, privateIPAddress,CAcontent and error
+// The instance terminates itself once the TTL of the VPC is reached, see SweepExpiredHelperInstances
+// When a step fails the security group, key pair, instance and EIP created before are deleted.
 func (vpc *VPC) LaunchProxyInstance(zone string, keypairName string, privateKeyPath string) (inst types.Instance, privateIP string, proxyServerCA string, err error) {
 	imageID, err := vpc.FindProxyLaunchImage()
 	if err != nil {
 		return inst, "", "", err
 	}
-	pubSubnet, err := vpc.PreparePublicSubnet(zone)
-	if err != nil {
-		log.LogInfo("Error preparing a subnet in current zone %s with image ID %s: %s", zone, imageID, err)
-		return inst, "", "", err
-	}
-	SGID, err := vpc.CreateAndAuthorizeDefaultSecurityGroupForProxy()
-	if err != nil {
-		log.LogError("Prepare SG failed for the proxy preparation %s", err)
-		return inst, "", "", err
-	}
 
 	keyName := fmt.Sprintf("%s-%s", CON.InstanceKeyNamePrefix, keypairName)
-	key, err := vpc.CreateKeyPair(keyName)
-	if err != nil {
-		log.LogError("Create key pair %s failed %s", keyName, err)
-		return inst, "", "", err
-	}
-	tags := map[string]string{
-		"Name": CON.ProxyName,
-	}
-	_, err = vpc.AWSClient.TagResource(*key.KeyPairId, tags)
-	if err != nil {
-		log.LogError("Add tag for key pair %s failed %s", *key.KeyPairId, err)
-		return inst, "", "", err
-	}
 	privateKeyName := fmt.Sprintf("%s-%s", keypairName, "keyPair.pem")
-	sshKey, err := file.WriteToFile(*key.KeyMaterial, privateKeyName, privateKeyPath)
-	if err != nil {
-		log.LogError("Write private key to file failed %s", err)
-		return inst, "", "", err
-	}
+	state, err := vpc.helperInstanceSaga("proxy-"+keypairName, CON.ProxyName, imageID, zone, "", keyName,
+		privateKeyName, privateKeyPath).
+		Step("setup-proxy-server",
+			func(ctx context.Context, state saga.State) error {
+				time.Sleep(helperInstanceBootTime)
+				sshKey := state.Get(sshKeyPathKey)
+				hostname := fmt.Sprintf("%s:22", state.Get(publicIPKey))
+				err := setupMITMProxyServer(sshKey, hostname)
+				if err != nil {
+					log.LogError("Setup MITM proxy server failed  %s", err)
+					return err
+				}
 
-	instOut, err := vpc.AWSClient.LaunchInstance(pubSubnet.ID, imageID, 1, "t3.medium", keyName, []string{SGID}, true)
+				cmd := "cat mitm-ca.pem"
+				caContent, err := Exec_CMD(CON.AWSInstanceUser, sshKey, hostname, cmd)
+				if err != nil {
+					log.LogError("login instance to run cmd %s:%s", cmd, err)
+					return err
+				}
+				state.Set(proxyCAKey, caContent)
+				return nil
+			}, nil).
+		Observe(vpc.sagaObserver(OperationLaunchProxyInstance)).
+		Run(context.TODO())
 	if err != nil {
 		log.LogError("Launch proxy instance failed %s", err)
 		return inst, "", "", err
-	} else {
-		log.LogInfo("Launch proxy instance %s succeed", *instOut.Instances[0].InstanceId)
-	}
-
-	instID := *instOut.Instances[0].InstanceId
-	_, err = vpc.AWSClient.TagResource(instID, tags)
-	if err != nil {
-		log.LogError("Add tag for instance  %s failed %s", instID, err)
-		return inst, "", "", err
-	}
-
-	publicIP, err := vpc.AWSClient.AllocateEIPAndAssociateInstance(instID)
-	if err != nil {
-		log.LogError("Prepare EIP failed for the proxy preparation %s", err)
-		return inst, "", "", err
-	}
-	log.LogInfo("Prepare EIP successfully for the proxy preparation. Launch with IP: %s", publicIP)
-
-	time.Sleep(2 * time.Minute)
-	hostname := fmt.Sprintf("%s:22", publicIP)
-	err = setupMITMProxyServer(sshKey, hostname)
-	if err != nil {
-		log.LogError("Setup MITM proxy server failed  %s", err)
-		return inst, "", "", err
 	}
 
-	cmd := "cat mitm-ca.pem"
-	caContent, err := Exec_CMD(CON.AWSInstanceUser, sshKey, hostname, cmd)
+	instance, err := vpc.helperInstance(state)
 	if err != nil {
-		log.LogError("login instance to run cmd %s:%s", cmd, err)
 		return inst, "", "", err
 	}
-	return instOut.Instances[0], *instOut.Instances[0].PrivateIpAddress, caContent, err
+	return *instance, *instance.PrivateIpAddress, state.Get(proxyCAKey), nil
 }
 
 func setupMITMProxyServer(sshKey string, hostname string) (err error) {
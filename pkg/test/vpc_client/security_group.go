@@ -35,34 +35,39 @@ func (vpc *VPC) DeleteVPCSecurityGroups(customizedOnly bool) error {
 	return nil
 }
 
-// CreateAndAuthorizeDefaultSecurityGroupForProxy can prepare a security group for the proxy launch
+// CreateAndAuthorizeDefaultSecurityGroupForProxy can prepare a security group for the proxy launch.
+// When the authorization fails the ID of the created group is returned with the error so that it can be deleted.
 func (vpc *VPC) CreateAndAuthorizeDefaultSecurityGroupForProxy(ports ...int32) (string, error) {
 	var groupID string
 	var err error
 	var sgIDs []string
-	var securityGroupName string
-	var securityGroupDescription string
-	if len(ports) > 0 {
-		securityGroupName = con.BastionSecurityGroupName
-		securityGroupDescription = con.BastionSecurityGroupDescription
-	} else {
-		securityGroupName = con.ProxySecurityGroupName
-		securityGroupDescription = con.ProxySecurityGroupDescription
-	}
+	securityGroupName, securityGroupDescription := proxySecurityGroup(ports...)
 	sgIDs, err = vpc.CreateAdditionalSecurityGroups(1, securityGroupName, securityGroupDescription, ports...)
 
+	if len(sgIDs) > 0 {
+		groupID = sgIDs[0]
+	}
 	if err != nil {
 		log.LogError("Security group prepare for proxy failed")
 	} else {
-		groupID = sgIDs[0]
 		log.LogInfo("Authorize SG %s prepared successfully for proxy.", groupID)
 	}
 	return groupID, err
 }
 
+// proxySecurityGroup returns the name prefix and the description of the security group of a bastion, opening
+// the ports, or of a proxy
+func proxySecurityGroup(ports ...int32) (string, string) {
+	if len(ports) > 0 {
+		return con.BastionSecurityGroupName, con.BastionSecurityGroupDescription
+	}
+	return con.ProxySecurityGroupName, con.ProxySecurityGroupDescription
+}
+
 // CreateAdditionalSecurityGroups  can prepare <count> additional security groups
 // description can be empty which will be set to default value
 // namePrefix is required, otherwise if there is same security group existing the creation will fail
+// when the authorization of a group fails, the groups created so far are returned with the error
 func (vpc *VPC) CreateAdditionalSecurityGroups(count int, namePrefix string, description string, ports ...int32) ([]string, error) {
 	preparedSGs := []string{}
 	createdsgNum := 0
@@ -87,7 +92,7 @@ func (vpc *VPC) CreateAdditionalSecurityGroups(count int, namePrefix string, des
 			for _, v := range port {
 				_, err = vpc.AWSClient.AuthorizeSecurityGroupIngress(groupID, cidr, con.TCPProtocol, v, v)
 				if err != nil {
-					return preparedSGs, err
+					return append(preparedSGs, groupID), err
 				}
 			}
 
@@ -2,6 +2,7 @@ package vpc_client
 
 import (
 	"net"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/openshift-online/ocm-common/pkg/aws/aws_client"
@@ -79,6 +80,17 @@ type VPC struct {
 	CIDRPool   *VPCCIDRPool
 	SubnetList []*Subnet
 	Region     string
+	// HelperInstanceTTL is the time to live of the bastion and proxy instances, defaults to
+	// consts.DefaultHelperInstanceTTL
+	HelperInstanceTTL time.Duration
+	// CheckpointDir is the directory where the provisioning flows save their progress, so that they can be resumed
+	// by another process. The progress is only kept in memory when it is empty.
+	CheckpointDir string
+	// CIDRAllocator allocates the VPC CIDR when the VPC chain is created and releases it when it is deleted.
+	// CIDRValue is used as is when it is not set.
+	CIDRAllocator CIDRAllocator
+	// ProgressHandler, when set, receives the progress events of the long-running operations
+	ProgressHandler ProgressHandler
 }
 
 func NewVPC() *VPC {
@@ -122,3 +134,23 @@ func (vpc *VPC) SetRegion(region string) *VPC {
 	vpc.Region = region
 	return vpc
 }
+
+func (vpc *VPC) InstanceTTL(ttl time.Duration) *VPC {
+	vpc.HelperInstanceTTL = ttl
+	return vpc
+}
+
+func (vpc *VPC) CheckpointDirectory(dir string) *VPC {
+	vpc.CheckpointDir = dir
+	return vpc
+}
+
+func (vpc *VPC) Allocator(allocator CIDRAllocator) *VPC {
+	vpc.CIDRAllocator = allocator
+	return vpc
+}
+
+func (vpc *VPC) OnProgress(handler ProgressHandler) *VPC {
+	vpc.ProgressHandler = handler
+	return vpc
+}
@@ -1,6 +1,7 @@
 package vpc_client
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -31,124 +32,92 @@ func (vpc *VPC) GenerateVPCBySubnet(subnetID string) (*VPC, error) {
 //	region is a string of the AWS region. If this value is empty, the default region is "us-east-2".
 //	zone is a slice. If only one subnet should be created, the first zone should be selected. If this value is empty, the default zone is "a".
 //	If success, a VPC struct containing the ids of the created resources and nil.
-//	Otherwise, nil and an error from the call.
+//	Otherwise, the VPC and an error from the call. The resources created before the failure are deleted.
+//
+// When a checkpoint directory is set, an interrupted creation resumes from the last completed step.
+// When a CIDR allocator is set, the VPC CIDR is allocated from it and released by DeleteVPCChain.
+// When a progress handler is set, it receives an event when each step starts and ends.
 func (vpc *VPC) CreateVPCChain(zones ...string) (*VPC, error) {
 	log.LogInfo("Going to create vpc and the follow resources on zones: %s", strings.Join(zones, ","))
-	respVpc, err := vpc.AWSClient.CreateVpc(vpc.CIDRValue, vpc.VPCName)
-	if err != nil {
-		log.LogError("Create vpc meets error: %s ", err.Error())
-		return nil, err
-	}
-	log.LogInfo("VPC created on AWS with id: %s", *respVpc.Vpc.VpcId)
-	_, err = vpc.AWSClient.ModifyVpcDnsAttribute(*respVpc.Vpc.VpcId, CON.VpcDnsHostnamesAttribute, true)
-	if err != nil {
-		log.LogError("Modify Vpc failed: %s ", err.Error())
-		return nil, err
-	}
-	log.LogInfo("VPC DNS Updated on AWS with id: %s", *respVpc.Vpc.VpcId)
-	vpc = vpc.ID(*respVpc.Vpc.VpcId)
-	_, err = vpc.PrepareInternetGateway()
+	_, err := vpc.vpcChainSaga(zones...).Observe(vpc.sagaObserver(OperationCreateVPCChain)).Run(context.TODO())
 	if err != nil {
-		log.LogError("Prepare Vpc internet gateway failed: %s ", err.Error())
+		log.LogError("Create vpc chain meets error: %s", err.Error())
 		return vpc, err
 	}
-	log.LogInfo("Prepare vpc internetgateway for vpc %s", *respVpc.Vpc.VpcId)
-	err = vpc.CreateMultiZoneSubnet(zones...)
-	if err != nil {
-		log.LogError("Create subnets meets error: %s", err.Error())
-	} else {
-		log.LogInfo("Create subnets successfully")
-	}
-	return vpc, err
+	log.LogInfo("Create subnets successfully")
+	return vpc, nil
+}
+
+// deleteStage is a step of DeleteVPCChain
+type deleteStage struct {
+	name         string
+	resourceType string
+	run          func() error
+	errMessage   string
 }
 
+// DeleteVPCChain deletes the VPC with the instances, security groups, gateways, subnets and other resources in it.
+// totalClean also deletes the leaked instances, load balancers and security groups not created by the helpers.
 func (vpc *VPC) DeleteVPCChain(totalClean ...bool) error {
 	vpcID := vpc.VpcID
 	if vpcID == "" {
 		return fmt.Errorf("got empty vpc ID to clean. Make sure you loaded it from AWS")
 	}
 	log.LogInfo("Going to delete the vpc and follow resources by ID: %s", vpcID)
-	log.LogInfo("Going to terminate proxy instances if exists")
-	err := vpc.TerminateVPCInstances(true)
-	if err != nil {
-		log.LogError("Delete vpc instances meets error: %s", err.Error())
-		return err
-	}
-	log.LogInfo("Delete vpc instances successfully")
-
-	log.LogInfo("Going to delete proxy security group")
-	err = vpc.DeleteVPCSecurityGroups(true)
-	if err != nil {
-		log.LogError("Delete vpc proxy security group meets error: %s", err.Error())
-		return err
-	}
-	log.LogInfo("Delete vpc proxy security group successfully")
-
-	err = vpc.DeleteVPCRouteTables(vpcID)
-	if err != nil {
-		log.LogError("Delete vpc route tables meets error: %s", err.Error())
-		return err
-	}
-	log.LogInfo("Delete vpc route tables successfully")
-
-	err = vpc.DeleteVPCNatGateways(vpcID)
-	if err != nil {
-		log.LogError("Delete vpc nat gatways meets error: %s", err.Error())
-		return err
-	}
-
-	log.LogInfo("Delete vpc nat gateways successfully")
-	err = vpc.AWSClient.DeleteVPCEndpoints(vpc.VpcID)
-	if err != nil {
-		log.LogError("Delete vpc endpoints meets error: %s", err.Error())
-		return err
+	stages := []deleteStage{
+		{"terminate-proxy-instances", "instance", func() error { return vpc.TerminateVPCInstances(true) },
+			"Delete vpc instances meets error"},
+		{"delete-proxy-security-groups", "security-group", func() error { return vpc.DeleteVPCSecurityGroups(true) },
+			"Delete vpc proxy security group meets error"},
+		{"delete-route-tables", "route-table", func() error { return vpc.DeleteVPCRouteTables(vpcID) },
+			"Delete vpc route tables meets error"},
+		{"delete-nat-gateways", "nat-gateway", func() error { return vpc.DeleteVPCNatGateways(vpcID) },
+			"Delete vpc nat gatways meets error"},
+		{"delete-endpoints", "vpc-endpoint", func() error { return vpc.AWSClient.DeleteVPCEndpoints(vpcID) },
+			"Delete vpc endpoints meets error"},
 	}
 	if len(totalClean) == 1 && totalClean[0] {
 		log.LogInfo("Got total clean set, going to delete other possible resource leak")
-		// Delete leak instances
-		log.LogInfo("Going to terminate the leak instances if exist")
-		err = vpc.TerminateVPCInstances(false)
-		if err != nil {
-			log.LogError("Terminate vpc instances meets error: %s", err.Error())
+		stages = append(stages,
+			deleteStage{"terminate-leaked-instances", "instance",
+				func() error { return vpc.TerminateVPCInstances(false) }, "Terminate vpc instances meets error"},
+			deleteStage{"delete-load-balancers", "load-balancer", vpc.DeleteVPCELBs,
+				"Delete vpc load balancers meets error"},
+			deleteStage{"delete-security-groups", "security-group",
+				func() error { return vpc.DeleteVPCSecurityGroups(false) }, "Delete vpc security groups meets error"})
+	}
+	stages = append(stages,
+		deleteStage{"delete-network-interfaces", "network-interface", vpc.DeleteVPCNetworkInterfaces,
+			"Delete vpc network interfaces meets error"},
+		deleteStage{"delete-internet-gateways", "internet-gateway", vpc.DeleteVPCInternetGateWays,
+			"Delete vpc internet gatways meets error"},
+		deleteStage{"delete-subnets", "subnet", vpc.DeleteVPCSubnets, "Delete vpc subnets meets error"},
+		deleteStage{"delete-vpc", "vpc", func() error {
+			_, err := vpc.AWSClient.DeleteVpc(vpcID)
 			return err
-		}
+		}, fmt.Sprintf("Delete vpc %s meets error", vpcID)})
+	if vpc.CIDRAllocator != nil && vpc.CIDRValue != "" {
+		stages = append(stages, deleteStage{"release-cidr", "cidr", func() error {
+			return vpc.CIDRAllocator.Release(vpc.CIDRValue)
+		}, fmt.Sprintf("Release vpc cidr %s meets error", vpc.CIDRValue)})
+	}
 
-		// Delete leak ELBs
-		err = vpc.DeleteVPCELBs()
-		if err != nil {
-			log.LogError("Delete vpc load balancers meets error: %s", err.Error())
-			return err
+	tracker := newProgressTracker(vpc.ProgressHandler, OperationDeleteVPCChain, len(stages))
+	for _, stage := range stages {
+		// The other stages delete all the resources of their type in the VPC
+		resourceID := ""
+		switch stage.resourceType {
+		case "vpc":
+			resourceID = vpcID
+		case "cidr":
+			resourceID = vpc.CIDRValue
 		}
-
-		// Delete leak security groups
-		err = vpc.DeleteVPCSecurityGroups(false)
+		err := tracker.run(stage.name, stage.resourceType, resourceID, stage.run)
 		if err != nil {
-			log.LogError("Delete vpc security groups meets error: %s", err.Error())
+			log.LogError("%s: %s", stage.errMessage, err.Error())
 			return err
 		}
-	}
-	err = vpc.DeleteVPCNetworkInterfaces()
-	if err != nil {
-		log.LogError("Delete vpc network interfaces meets error: %s", err.Error())
-		return err
-	}
-
-	err = vpc.DeleteVPCInternetGateWays()
-	if err != nil {
-		log.LogError("Delete vpc internet gatways meets error: %s", err.Error())
-		return err
-	}
-
-	err = vpc.DeleteVPCSubnets()
-	if err != nil {
-		log.LogError("Delete vpc subnets meets error: %s", err.Error())
-		return err
-	}
-
-	_, err = vpc.AWSClient.DeleteVpc(vpc.VpcID)
-	if err != nil {
-		log.LogError("Delete vpc %s meets error: %s", vpc.VpcID, err.Error())
-		return err
+		log.LogInfo("Step %s of the vpc deletion done", stage.name)
 	}
 	return nil
 }
@@ -161,6 +130,13 @@ func (vpc *VPC) DeleteVPCChain(totalClean ...bool) error {
 // Try vpc.PreparePairSubnets by zone for further implementation to get a pair of
 // Zones will be customized if you want. Otherwise, it will use the default zone "a"
 func PrepareVPC(vpcName string, region string, vpcCIDR string, checkExisting bool, awsSharedCredentialFile string, zones ...string) (*VPC, error) {
+	return PrepareVPCWithProgress(nil, vpcName, region, vpcCIDR, checkExisting, awsSharedCredentialFile, zones...)
+}
+
+// PrepareVPCWithProgress is PrepareVPC reporting its progress to the handler. The handler is kept by the returned
+// VPC so that its later operations report their progress too.
+func PrepareVPCWithProgress(handler ProgressHandler, vpcName string, region string, vpcCIDR string,
+	checkExisting bool, awsSharedCredentialFile string, zones ...string) (*VPC, error) {
 	var awsclient *aws_client.AWSClient
 	var err error
 
@@ -195,14 +171,23 @@ func PrepareVPC(vpcName string, region string, vpcCIDR string, checkExisting boo
 			vpcID := *vpcs[0].VpcId
 			log.LogInfo("Got a vpc %s with name %s on region %s. Just load it for usage",
 				vpcID, vpcName, region)
-			vpc, err := GenerateVPCByID(vpcID, region)
+			tracker := newProgressTracker(handler, OperationPrepareVPC, len(zones)+1)
+			var vpc *VPC
+			err = tracker.run("load-vpc", "vpc", vpcID, func() (err error) {
+				vpc, err = GenerateVPCByID(vpcID, region)
+				return err
+			})
 			if err != nil {
 				log.LogError("Load vpc %s details meets error %s",
 					vpcID, err.Error())
 				return nil, err
 			}
+			vpc.OnProgress(handler)
 			for _, zone := range zones {
-				_, err = vpc.PreparePairSubnetByZone(zone)
+				err = tracker.run("prepare-pair-subnet-"+zone, "subnet", "", func() error {
+					_, err := vpc.PreparePairSubnetByZone(zone)
+					return err
+				})
 				if err != nil {
 					log.LogError("Prepare subnets for vpc %s on zone %s meets error %s",
 						vpcID, zone, err.Error())
@@ -220,7 +205,8 @@ func PrepareVPC(vpcName string, region string, vpcCIDR string, checkExisting boo
 		AWSclient(awsclient).
 		SetRegion(region).
 		CIDR(vpcCIDR).
-		NewCIDRPool()
+		NewCIDRPool().
+		OnProgress(handler)
 	vpc, err = vpc.CreateVPCChain(zones...)
 	if err != nil {
 		log.LogError("Create vpc chain meets error: %s", err.Error())
@@ -0,0 +1,13 @@
+package vpc_client
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVPCClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "VPC Client Suite")
+}
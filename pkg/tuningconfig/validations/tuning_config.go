@@ -0,0 +1,128 @@
+package validations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// tunedSpec is the subset of the Node Tuning Operator 'Tuned' spec that is validated
+type tunedSpec struct {
+	Profile   []tunedProfile   `json:"profile"`
+	Recommend []tunedRecommend `json:"recommend"`
+}
+
+type tunedProfile struct {
+	Name *string `json:"name"`
+	Data *string `json:"data"`
+}
+
+type tunedRecommend struct {
+	Priority *int64  `json:"priority"`
+	Profile  *string `json:"profile"`
+}
+
+// ParseTuningConfigSpec parses a tuning config spec provided as YAML or JSON
+func ParseTuningConfigSpec(data []byte) (map[string]interface{}, error) {
+	spec := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("Invalid tuning config spec: %v", err)
+	}
+	return spec, nil
+}
+
+// ValidateTuningConfigName checks that the name of the tuning config is a valid DNS label
+func ValidateTuningConfigName(name string) error {
+	if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+		return fmt.Errorf("Invalid tuning config name '%s': %s", name, msgs[0])
+	}
+	return nil
+}
+
+// ValidateTuningConfigSpec checks the structure of a Node Tuning Operator spec:
+//
+// * At least one profile must be defined, each one with a unique name and its data.
+// * At least one recommendation must be defined, each one with a non-negative priority and
+// referencing one of the profiles.
+//
+// All the issues are returned at once.
+func ValidateTuningConfigSpec(spec interface{}) error {
+	if spec == nil {
+		return fmt.Errorf("Tuning config spec is required")
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("Invalid tuning config spec: %v", err)
+	}
+	parsed := tunedSpec{}
+	if err = json.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("Invalid tuning config spec: %v", err)
+	}
+
+	var errs []error
+	if len(parsed.Profile) == 0 {
+		errs = append(errs, fmt.Errorf("Tuning config spec must define at least one profile"))
+	}
+	profiles := map[string]bool{}
+	for i, profile := range parsed.Profile {
+		if profile.Name == nil || *profile.Name == "" {
+			errs = append(errs, fmt.Errorf("Tuning config profile %d has no name", i))
+			continue
+		}
+		if profiles[*profile.Name] {
+			errs = append(errs, fmt.Errorf("Tuning config profile '%s' is duplicated", *profile.Name))
+		}
+		profiles[*profile.Name] = true
+		if profile.Data == nil || *profile.Data == "" {
+			errs = append(errs, fmt.Errorf("Tuning config profile '%s' has no data", *profile.Name))
+		}
+	}
+
+	if len(parsed.Recommend) == 0 {
+		errs = append(errs, fmt.Errorf("Tuning config spec must define at least one recommendation"))
+	}
+	for i, recommend := range parsed.Recommend {
+		if recommend.Priority == nil {
+			errs = append(errs, fmt.Errorf("Tuning config recommendation %d has no priority", i))
+		} else if *recommend.Priority < 0 {
+			errs = append(errs, fmt.Errorf("Tuning config recommendation %d has a negative priority", i))
+		}
+		if recommend.Profile == nil || *recommend.Profile == "" {
+			errs = append(errs, fmt.Errorf("Tuning config recommendation %d has no profile", i))
+		} else if !profiles[*recommend.Profile] {
+			errs = append(errs, fmt.Errorf("Tuning config recommendation %d references the undefined profile '%s'",
+				i, *recommend.Profile))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateTuningConfig validates the name and the spec of a tuning config
+func ValidateTuningConfig(tuningConfig *cmv1.TuningConfig) error {
+	return errors.Join(ValidateTuningConfigName(tuningConfig.Name()), ValidateTuningConfigSpec(tuningConfig.Spec()))
+}
+
+// ValidateNodePoolTuningConfigs checks that the tuning configs referenced by a node pool exist in the cluster
+// and are referenced only once
+func ValidateNodePoolTuningConfigs(references []string, existing []*cmv1.TuningConfig) error {
+	var errs []error
+	names := map[string]bool{}
+	for _, tuningConfig := range existing {
+		names[tuningConfig.Name()] = true
+	}
+	seen := map[string]bool{}
+	for _, reference := range references {
+		if !names[reference] {
+			errs = append(errs, fmt.Errorf("Tuning config '%s' does not exist in the cluster", reference))
+		}
+		if seen[reference] {
+			errs = append(errs, fmt.Errorf("Tuning config '%s' is referenced more than once", reference))
+		}
+		seen[reference] = true
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package validations
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+const validSpec = `
+profile:
+- name: tuned-hugepages
+  data: |
+    [main]
+    summary=Boot time configuration for hugepages
+    include=openshift-node
+    [bootloader]
+    cmdline_openshift_node_hugepages=hugepagesz=2M hugepages=50
+recommend:
+- priority: 20
+  profile: tuned-hugepages
+`
+
+var _ = Describe("Tuning config validations", func() {
+	It("accepts a valid spec", func() {
+		spec, err := ParseTuningConfigSpec([]byte(validSpec))
+		Expect(err).ToNot(HaveOccurred())
+		tuningConfig, err := cmv1.NewTuningConfig().Name("hugepages").Spec(spec).Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ValidateTuningConfig(tuningConfig)).To(Succeed())
+	})
+
+	It("reports every structural issue of the spec", func() {
+		spec, err := ParseTuningConfigSpec([]byte(`
+profile:
+- name: a
+- name: a
+  data: x
+recommend:
+- priority: -1
+  profile: b
+- profile: a
+`))
+		Expect(err).ToNot(HaveOccurred())
+		err = ValidateTuningConfigSpec(spec)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Tuning config profile 'a' has no data"))
+		Expect(err.Error()).To(ContainSubstring("Tuning config profile 'a' is duplicated"))
+		Expect(err.Error()).To(ContainSubstring("recommendation 0 has a negative priority"))
+		Expect(err.Error()).To(ContainSubstring("recommendation 0 references the undefined profile 'b'"))
+		Expect(err.Error()).To(ContainSubstring("recommendation 1 has no priority"))
+	})
+
+	It("requires profiles and recommendations", func() {
+		Expect(ValidateTuningConfigSpec(nil)).To(MatchError("Tuning config spec is required"))
+		err := ValidateTuningConfigSpec(map[string]interface{}{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("at least one profile"))
+		Expect(err.Error()).To(ContainSubstring("at least one recommendation"))
+	})
+
+	It("rejects a spec with the wrong structure", func() {
+		Expect(ValidateTuningConfigSpec(map[string]interface{}{"profile": "tuned"})).ToNot(Succeed())
+	})
+
+	It("rejects invalid names", func() {
+		Expect(ValidateTuningConfigName("Hugepages_1")).ToNot(Succeed())
+	})
+
+	It("validates the references of a node pool", func() {
+		tuningConfig, err := cmv1.NewTuningConfig().Name("hugepages").Build()
+		Expect(err).ToNot(HaveOccurred())
+		existing := []*cmv1.TuningConfig{tuningConfig}
+		Expect(ValidateNodePoolTuningConfigs([]string{"hugepages"}, existing)).To(Succeed())
+		err = ValidateNodePoolTuningConfigs([]string{"hugepages", "hugepages", "missing"}, existing)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Tuning config 'missing' does not exist in the cluster"))
+		Expect(err.Error()).To(ContainSubstring("Tuning config 'hugepages' is referenced more than once"))
+	})
+})
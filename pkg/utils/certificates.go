@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// ParsePEMCertificates parses a bundle of PEM encoded certificates. The bundle must contain at least one
+// certificate and nothing else.
+func ParsePEMCertificates(data string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type '%s'", block.Type)
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 || strings.TrimSpace(string(rest)) != "" {
+		return nil, fmt.Errorf("must contain only PEM encoded certificates")
+	}
+	return certificates, nil
+}
@@ -0,0 +1,51 @@
+package cron_parser
+
+import (
+	. "github.com/openshift-online/ocm-common/pkg/utils/parser/state_machine"
+	. "github.com/openshift-online/ocm-common/pkg/utils/parser/string_parser"
+)
+
+const (
+	// Define the names of the tokens to be parsed
+
+	wildcard   = "WILDCARD"    // The `*` wildcard
+	value      = "VALUE"       // A number or a name, alone or starting a range
+	rangeDash  = "RANGE_DASH"  // The `-` separating the bounds of a range
+	rangeEnd   = "RANGE_END"   // The upper bound of a range
+	stepSlash  = "STEP_SLASH"  // The `/` introducing a step
+	step       = "STEP"        // The increment of a step
+	listComma  = "LIST_COMMA"  // The `,` separating the items of a list
+	fieldSpace = "FIELD_SPACE" // The blank separating two fields
+
+	valuePattern = `(?i)([0-9]+|[A-Z]{3})`
+)
+
+// CronGrammar is the grammar of a single cron field, repeated for each field. The number of fields and the values
+// allowed in each of them are checked by the parser.
+func CronGrammar() Grammar {
+	grammar := Grammar{
+		Tokens: []TokenDefinition{
+			{Name: wildcard, Acceptor: StringAcceptor(`*`)},
+			{Name: value, Acceptor: RegexpAcceptor(valuePattern)},
+			{Name: rangeDash, Acceptor: StringAcceptor(`-`)},
+			{Name: rangeEnd, Acceptor: RegexpAcceptor(valuePattern)},
+			{Name: stepSlash, Acceptor: StringAcceptor(`/`)},
+			{Name: step, Acceptor: RegexpAcceptor(`[0-9]+`)},
+			{Name: listComma, Acceptor: StringAcceptor(`,`)},
+			{Name: fieldSpace, Acceptor: StringAcceptor(` `)},
+		},
+		Transitions: []TokenTransitions{
+			{TokenName: StartState, ValidTransitions: []string{wildcard, value}},
+			{TokenName: wildcard, ValidTransitions: []string{stepSlash, listComma, fieldSpace, EndState}},
+			{TokenName: value, ValidTransitions: []string{rangeDash, stepSlash, listComma, fieldSpace, EndState}},
+			{TokenName: rangeDash, ValidTransitions: []string{rangeEnd}},
+			{TokenName: rangeEnd, ValidTransitions: []string{stepSlash, listComma, fieldSpace, EndState}},
+			{TokenName: stepSlash, ValidTransitions: []string{step}},
+			{TokenName: step, ValidTransitions: []string{listComma, fieldSpace, EndState}},
+			{TokenName: listComma, ValidTransitions: []string{wildcard, value}},
+			{TokenName: fieldSpace, ValidTransitions: []string{wildcard, value}},
+		},
+	}
+
+	return grammar
+}
@@ -0,0 +1,187 @@
+package cron_parser
+
+import (
+	"fmt"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/state_machine"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/string_parser"
+	"strconv"
+	"strings"
+)
+
+// CronParser - This object is to be used to parse and validate the cron expressions of the upgrade policies.
+// The expressions have the five standard fields: minute, hour, day of month, month and day of week. Each field is a
+// list of `*`, values or ranges, optionally followed by a step, like `*/15` or `1-5`. Months and days of the week
+// can be given by name (`JAN`, `MON`).
+type CronParser interface {
+	// Parse - parses the received cron expression and returns its schedule or an error
+	Parse(expression string) (*Schedule, error)
+}
+
+// fieldDefinition describes the values allowed in a field
+type fieldDefinition struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var fieldDefinitions = []fieldDefinition{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}},
+	{name: "day of week", min: 0, max: 6, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}},
+}
+
+// term is an item of the list of a field: `*`, a value or a range, with its step
+type term struct {
+	wildcard bool
+	start    string
+	end      string
+	step     string
+}
+
+type cronParser struct {
+	parser *string_parser.StringParser
+
+	// current parsing state
+	fields      [][]term
+	currentTerm *term
+}
+
+var _ CronParser = &cronParser{}
+
+func (p *cronParser) Parse(expression string) (*Schedule, error) {
+	p.reset()
+
+	if err := p.parser.Parse(expression); err != nil {
+		return nil, err
+	}
+	p.closeTerm()
+	if len(p.fields) != len(fieldDefinitions) {
+		return nil, fmt.Errorf("expected %d fields in cron expression '%s', found %d",
+			len(fieldDefinitions), expression, len(p.fields))
+	}
+
+	schedule := &Schedule{Expression: strings.TrimSpace(expression)}
+	bits := []*bitSet{&schedule.minute, &schedule.hour, &schedule.dayOfMonth, &schedule.month, &schedule.dayOfWeek}
+	for i, field := range p.fields {
+		for _, t := range field {
+			values, err := fieldDefinitions[i].expand(t)
+			if err != nil {
+				return nil, err
+			}
+			*bits[i] |= values
+		}
+	}
+	schedule.anyDayOfMonth = isWildcard(p.fields[2])
+	schedule.anyDayOfWeek = isWildcard(p.fields[4])
+
+	if schedule.Next(scheduleCheckStart).IsZero() {
+		return nil, fmt.Errorf("cron expression '%s' never runs", schedule.Expression)
+	}
+	return schedule, nil
+}
+
+func (p *cronParser) reset() {
+	p.fields = [][]term{{}}
+	p.currentTerm = nil
+}
+
+func (p *cronParser) closeTerm() {
+	if p.currentTerm != nil {
+		last := len(p.fields) - 1
+		p.fields[last] = append(p.fields[last], *p.currentTerm)
+		p.currentTerm = nil
+	}
+}
+
+func (p *cronParser) transitionInterceptor(_, to *state_machine.State[string, string], tokenValue string) error {
+	switch to.Name() {
+	case wildcard:
+		p.currentTerm = &term{wildcard: true}
+	case value:
+		p.currentTerm = &term{start: tokenValue}
+	case rangeEnd:
+		p.currentTerm.end = tokenValue
+	case step:
+		p.currentTerm.step = tokenValue
+	case listComma:
+		p.closeTerm()
+	case fieldSpace:
+		p.closeTerm()
+		if len(p.fields) == len(fieldDefinitions) {
+			return fmt.Errorf("too many fields, expected %d", len(fieldDefinitions))
+		}
+		p.fields = append(p.fields, []term{})
+	}
+	return nil
+}
+
+// expand returns the values of the field matched by the term
+func (d fieldDefinition) expand(t term) (bitSet, error) {
+	start, end := d.min, d.max
+	var err error
+	if !t.wildcard {
+		if start, err = d.parseValue(t.start); err != nil {
+			return 0, err
+		}
+		end = start
+		if t.end != "" {
+			if end, err = d.parseValue(t.end); err != nil {
+				return 0, err
+			}
+		} else if t.step != "" {
+			// `5/15` means every 15 starting at 5
+			end = d.max
+		}
+	}
+	if start > end {
+		return 0, fmt.Errorf("invalid range %d-%d in %s field", start, end, d.name)
+	}
+	increment := 1
+	if t.step != "" {
+		increment, err = strconv.Atoi(t.step)
+		if err != nil || increment < 1 || increment > d.max-d.min+1 {
+			return 0, fmt.Errorf("invalid step '%s' in %s field", t.step, d.name)
+		}
+	}
+	var values bitSet
+	for i := start; i <= end; i += increment {
+		values.set(i)
+	}
+	return values, nil
+}
+
+func (d fieldDefinition) parseValue(token string) (int, error) {
+	if v, ok := d.names[strings.ToUpper(token)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s' in %s field", token, d.name)
+	}
+	if v < d.min || v > d.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d] in %s field", v, d.min, d.max, d.name)
+	}
+	return v, nil
+}
+
+func isWildcard(field []term) bool {
+	return len(field) == 1 && field[0].wildcard && field[0].step == ""
+}
+
+func NewCronParser() CronParser {
+	parser := &cronParser{}
+	parser.parser = string_parser.NewStringParserBuilder().
+		WithGrammar(CronGrammar()).
+		WithTransitionInterceptor(parser.transitionInterceptor).
+		WithScanner(NewCronScanner()).
+		Build()
+	return parser
+}
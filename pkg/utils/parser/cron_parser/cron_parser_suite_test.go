@@ -0,0 +1,13 @@
+package cron_parser_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCronParser(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CronParser Suite")
+}
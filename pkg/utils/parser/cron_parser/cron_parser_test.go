@@ -0,0 +1,119 @@
+package cron_parser
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CronParser", func() {
+	// a Saturday
+	start := time.Date(2024, time.June, 1, 10, 30, 0, 0, time.UTC)
+
+	DescribeTable("Valid expressions", func(expression string, expected []string) {
+		schedule, err := NewCronParser().Parse(expression)
+		Expect(err).ToNot(HaveOccurred())
+		runs := []string{}
+		for _, run := range schedule.NextN(start, len(expected)) {
+			runs = append(runs, run.Format(time.RFC3339))
+		}
+		Expect(runs).To(Equal(expected))
+	},
+		Entry("every minute", "* * * * *", []string{"2024-06-01T10:31:00Z", "2024-06-01T10:32:00Z"}),
+		Entry("steps", "*/20 */12 * * *", []string{"2024-06-01T12:00:00Z", "2024-06-01T12:20:00Z",
+			"2024-06-01T12:40:00Z", "2024-06-02T00:00:00Z"}),
+		Entry("value with step", "5/30 10 * * *", []string{"2024-06-01T10:35:00Z", "2024-06-02T10:05:00Z"}),
+		Entry("ranges and lists", "0 8-9,22 * * *", []string{"2024-06-01T22:00:00Z", "2024-06-02T08:00:00Z",
+			"2024-06-02T09:00:00Z"}),
+		Entry("day of week by name", "0 2 * * mon-tue", []string{"2024-06-03T02:00:00Z", "2024-06-04T02:00:00Z",
+			"2024-06-10T02:00:00Z"}),
+		Entry("month by name", "0 0 1 JAN,jul *", []string{"2024-07-01T00:00:00Z", "2025-01-01T00:00:00Z"}),
+		Entry("day of month or day of week", "0 0 15 * SUN", []string{"2024-06-02T00:00:00Z",
+			"2024-06-09T00:00:00Z", "2024-06-15T00:00:00Z", "2024-06-16T00:00:00Z"}),
+		Entry("leap day", "0 0 29 2 *", []string{"2028-02-29T00:00:00Z"}),
+		Entry("extra blanks", "  0   3 * *\t* ", []string{"2024-06-02T03:00:00Z"}),
+	)
+
+	DescribeTable("Invalid expressions", func(expression string, errMessage string) {
+		_, err := NewCronParser().Parse(expression)
+		Expect(err).To(MatchError(errMessage))
+	},
+		Entry("empty", "", "EOF encountered while parsing string"),
+		Entry("too few fields", "* * * *", "expected 5 fields in cron expression '* * * *', found 4"),
+		Entry("too many fields", "* * * * * *", "[10] error parsing the filter: too many fields, expected 5"),
+		Entry("dangling range", "1- * * * *", "[3] error parsing the filter: unexpected token ` `"),
+		Entry("unexpected symbol", "? * * * *", "[1] error parsing the filter: unexpected token `?`"),
+		Entry("out of range", "60 * * * *", "value 60 out of range [0, 59] in minute field"),
+		Entry("unknown name", "* * * FOO *", "invalid value 'FOO' in month field"),
+		Entry("name in the wrong field", "* * * MON *", "invalid value 'MON' in month field"),
+		Entry("inverted range", "* 10-2 * * *", "invalid range 10-2 in hour field"),
+		Entry("zero step", "*/0 * * * *", "invalid step '0' in minute field"),
+		Entry("never runs", "0 0 31 4 *", "cron expression '0 0 31 4 *' never runs"),
+	)
+})
+
+var _ = Describe("MaintenanceWindow", func() {
+	now := time.Date(2024, time.June, 1, 10, 30, 0, 0, time.UTC)
+	parse := func(expression string) *Schedule {
+		schedule, err := NewCronParser().Parse(expression)
+		Expect(err).ToNot(HaveOccurred())
+		return schedule
+	}
+
+	It("accepts a schedule respecting the window", func() {
+		window := &MaintenanceWindow{
+			MinimumLeadTime: 2 * time.Hour,
+			MinimumInterval: 24 * time.Hour,
+			Days:            []time.Weekday{time.Saturday, time.Sunday},
+			StartHour:       22,
+			EndHour:         4,
+		}
+		Expect(window.Validate(parse("0 23 * * SAT,SUN"), now)).To(Succeed())
+	})
+
+	It("reports all the violations", func() {
+		window := &MaintenanceWindow{
+			MinimumLeadTime: 2 * time.Hour,
+			MinimumInterval: time.Hour,
+			Days:            []time.Weekday{time.Sunday},
+			StartHour:       1,
+			EndHour:         5,
+		}
+		err := window.Validate(parse("*/30 11 * * *"), now)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("the minimum lead time is 2h0m0s"))
+		Expect(err.Error()).To(ContainSubstring("closer than the minimum interval of 1h0m0s"))
+		Expect(err.Error()).To(ContainSubstring("runs on Monday, allowed days are [Sunday]"))
+		Expect(err.Error()).To(ContainSubstring("runs at 11:00, outside of the maintenance hours 01:00-05:00 UTC"))
+	})
+
+	DescribeTable("checks every run, not only the next ones",
+		func(expression string, window *MaintenanceWindow, violation string) {
+			err := window.Validate(parse(expression), now)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(violation))
+		},
+		Entry("day of week", "*/5 * * * *", &MaintenanceWindow{Days: []time.Weekday{time.Saturday}},
+			"runs on Sunday, allowed days are [Saturday]"),
+		Entry("hour", "*/2 * * * *", &MaintenanceWindow{StartHour: 10, EndHour: 14},
+			"runs at 00:00, outside of the maintenance hours 10:00-14:00 UTC"),
+		Entry("day of month", "0 11 1 * *", &MaintenanceWindow{Days: []time.Weekday{time.Saturday}},
+			"runs on Sunday, allowed days are [Saturday]"),
+		Entry("day of month or day of week", "0 11 1 * SAT", &MaintenanceWindow{Days: []time.Weekday{time.Saturday}},
+			"runs on Sunday, allowed days are [Saturday]"),
+		Entry("interval of a leap day", "0 0 1,29 2,3 *", &MaintenanceWindow{MinimumInterval: 48 * time.Hour},
+			"closer than the minimum interval of 48h0m0s"),
+	)
+
+	It("accepts the days of week restricting the schedule", func() {
+		window := &MaintenanceWindow{Days: []time.Weekday{time.Saturday, time.Sunday}, StartHour: 10, EndHour: 14}
+		Expect(window.Validate(parse("*/2 10-13 * * SAT,SUN"), now)).To(Succeed())
+	})
+
+	It("returns the first run after the lead time", func() {
+		window := &MaintenanceWindow{MinimumLeadTime: 90 * time.Minute}
+		run := window.FirstAllowedRun(parse("0 * * * *"), now)
+		Expect(run).To(Equal(time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)))
+	})
+})
@@ -0,0 +1,105 @@
+package cron_parser
+
+import (
+	"fmt"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/string_scanner"
+)
+
+const (
+	NUMBER = iota
+	NAME
+	SYMBOL
+	SEPARATOR
+)
+
+// scanner - This scanner is to be used to parse cron expressions. It splits the provided string into numbers, names
+// (like `MON` or `JAN`), single symbols (`*`, `-`, `/`, `,`) and field separators. Consecutive blanks are returned
+// as a single separator token whose value is a space.
+type scanner struct {
+	tokens []string_scanner.Token
+	pos    int
+}
+
+var _ string_scanner.Scanner = &scanner{}
+
+// Init feeds the scanner with the text to be scanned
+func (s *scanner) Init(txt string) {
+	s.pos = -1
+	s.tokens = nil
+
+	var current *string_scanner.Token
+	sendCurrentToken := func() {
+		if current != nil {
+			s.tokens = append(s.tokens, *current)
+			current = nil
+		}
+	}
+	appendToCurrentToken := func(tokenType int, value rune, position int) {
+		if current != nil && current.TokenType != tokenType {
+			sendCurrentToken()
+		}
+		if current == nil {
+			current = &string_scanner.Token{TokenType: tokenType, Position: position}
+		}
+		current.Value += string(value)
+	}
+
+	for i, currentChar := range txt {
+		switch {
+		case currentChar == ' ' || currentChar == '\t':
+			if current == nil || current.TokenType != SEPARATOR {
+				sendCurrentToken()
+				current = &string_scanner.Token{TokenType: SEPARATOR, Value: " ", Position: i}
+			}
+		case currentChar >= '0' && currentChar <= '9':
+			appendToCurrentToken(NUMBER, currentChar, i)
+		case currentChar >= 'a' && currentChar <= 'z' || currentChar >= 'A' && currentChar <= 'Z':
+			appendToCurrentToken(NAME, currentChar, i)
+		default:
+			sendCurrentToken()
+			s.tokens = append(s.tokens, string_scanner.Token{TokenType: SYMBOL, Value: string(currentChar), Position: i})
+		}
+	}
+	sendCurrentToken()
+
+	// leading and trailing blanks are not field separators
+	if len(s.tokens) > 0 && s.tokens[0].TokenType == SEPARATOR {
+		s.tokens = s.tokens[1:]
+	}
+	if len(s.tokens) > 0 && s.tokens[len(s.tokens)-1].TokenType == SEPARATOR {
+		s.tokens = s.tokens[:len(s.tokens)-1]
+	}
+}
+
+// Next moves to the next token and return `true` if another token is present. Otherwise returns `false`
+func (s *scanner) Next() bool {
+	if s.pos < (len(s.tokens) - 1) {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// Peek looks if another token is present after the current position without moving the cursor
+func (s *scanner) Peek() (bool, *string_scanner.Token) {
+	if s.pos < (len(s.tokens) - 1) {
+		ret := s.tokens[s.pos+1]
+		return true, &ret
+	}
+	return false, nil
+}
+
+// Token returns the current token
+func (s *scanner) Token() *string_scanner.Token {
+	if s.pos < 0 || s.pos >= len(s.tokens) {
+		panic(fmt.Errorf("invalid scanner position %d", s.pos))
+	}
+	ret := s.tokens[s.pos]
+	return &ret
+}
+
+func NewCronScanner() string_scanner.Scanner {
+	return &scanner{
+		pos: -1,
+	}
+}
@@ -0,0 +1,94 @@
+package cron_parser
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maintenanceWindowIntervalPeriod is the period of the upcoming runs checked against the minimum interval, four
+// years include a leap day
+const maintenanceWindowIntervalPeriod = 4 * 366 * 24 * time.Hour
+
+// MaintenanceWindow holds the constraints the runs of an upgrade schedule must respect. The zero value of each
+// constraint disables it.
+type MaintenanceWindow struct {
+	// MinimumLeadTime is the minimum time between now and the next run, to leave time to notify the users
+	MinimumLeadTime time.Duration
+	// MinimumInterval is the minimum time between two consecutive runs
+	MinimumInterval time.Duration
+	// Days are the days of the week the runs may happen on
+	Days []time.Weekday
+	// StartHour and EndHour bound the hours of the day, in UTC, the runs may start in. The window wraps around
+	// midnight when EndHour is lower than StartHour, it is the whole day when they are equal.
+	StartHour int
+	EndHour   int
+}
+
+// Validate checks the schedule against the window, now being the time the schedule is submitted. The days and
+// hours are checked on the fields of the schedule, so that no run is missed, and the minimum interval on the runs
+// of the next maintenanceWindowIntervalPeriod. The returned error lists all the violations found.
+func (w *MaintenanceWindow) Validate(schedule *Schedule, now time.Time) error {
+	first := schedule.Next(now)
+	if first.IsZero() {
+		return fmt.Errorf("schedule '%s' has no upcoming run", schedule.Expression)
+	}
+	var errs []error
+	if leadTime := first.Sub(now.UTC()); leadTime < w.MinimumLeadTime {
+		errs = append(errs, fmt.Errorf("next run at %s is in %s, the minimum lead time is %s",
+			first.Format(time.RFC3339), leadTime.Round(time.Second), w.MinimumLeadTime))
+	}
+	if w.MinimumInterval > 0 {
+		limit := first.Add(maintenanceWindowIntervalPeriod)
+		for previous, run := first, schedule.Next(first); !run.IsZero() && run.Before(limit); previous, run =
+			run, schedule.Next(run) {
+			if run.Sub(previous) < w.MinimumInterval {
+				errs = append(errs, fmt.Errorf("runs at %s and %s are closer than the minimum interval of %s",
+					previous.Format(time.RFC3339), run.Format(time.RFC3339), w.MinimumInterval))
+				break
+			}
+		}
+	}
+	for _, day := range schedule.weekdays() {
+		if !w.allowsDay(day) {
+			errs = append(errs, fmt.Errorf("schedule runs on %s, allowed days are %v", day, w.Days))
+			break
+		}
+	}
+	for _, hour := range schedule.hours() {
+		if !w.allowsHour(hour) {
+			errs = append(errs, fmt.Errorf("schedule runs at %02d:00, outside of the maintenance hours "+
+				"%02d:00-%02d:00 UTC", hour, w.StartHour, w.EndHour))
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FirstAllowedRun returns the first run of the schedule respecting the minimum lead time
+func (w *MaintenanceWindow) FirstAllowedRun(schedule *Schedule, now time.Time) time.Time {
+	return schedule.Next(now.Add(w.MinimumLeadTime).Add(-time.Nanosecond))
+}
+
+func (w *MaintenanceWindow) allowsDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, allowed := range w.Days {
+		if allowed == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *MaintenanceWindow) allowsHour(hour int) bool {
+	switch {
+	case w.StartHour == w.EndHour:
+		return true
+	case w.StartHour < w.EndHour:
+		return hour >= w.StartHour && hour < w.EndHour
+	default:
+		return hour >= w.StartHour || hour < w.EndHour
+	}
+}
@@ -0,0 +1,111 @@
+package cron_parser
+
+import (
+	"time"
+)
+
+// scheduleSearchLimit bounds the search of the next run, an expression without run in that period never runs
+const scheduleSearchLimit = 5 * 366 * 24 * time.Hour
+
+// scheduleCheckStart is the time from which the parser checks that an expression runs at least once
+var scheduleCheckStart = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// bitSet holds the values matched by a field
+type bitSet uint64
+
+func (b *bitSet) set(value int) {
+	*b |= 1 << uint(value)
+}
+
+func (b bitSet) has(value int) bool {
+	return b&(1<<uint(value)) != 0
+}
+
+// Schedule is a parsed cron expression. The times are computed in UTC, as the upgrade policies are scheduled.
+type Schedule struct {
+	Expression string
+
+	minute     bitSet
+	hour       bitSet
+	dayOfMonth bitSet
+	month      bitSet
+	dayOfWeek  bitSet
+	// when only one of the day fields is restricted, only that one applies. Otherwise a day matching either of
+	// them matches, as in the standard cron.
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// Next returns the first run strictly after the given time, or the zero time if the schedule never runs
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(scheduleSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case !s.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !s.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// NextN returns the next n runs after the given time. Fewer runs are returned if the schedule stops running.
+func (s *Schedule) NextN(after time.Time, n int) []time.Time {
+	runs := []time.Time{}
+	for len(runs) < n {
+		after = s.Next(after)
+		if after.IsZero() {
+			break
+		}
+		runs = append(runs, after)
+	}
+	return runs
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth.has(t.Day())
+	dayOfWeek := s.dayOfWeek.has(int(t.Weekday()))
+	switch {
+	case s.anyDayOfMonth:
+		return dayOfWeek
+	case s.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
+
+// hours returns the hours of the day the schedule runs at
+func (s *Schedule) hours() []int {
+	hours := []int{}
+	for hour := 0; hour < 24; hour++ {
+		if s.hour.has(hour) {
+			hours = append(hours, hour)
+		}
+	}
+	return hours
+}
+
+// weekdays returns the days of the week the schedule runs on. A restricted day of month falls on every day of the
+// week over the years, so only a schedule restricted by the day of week alone runs on some days of the week.
+func (s *Schedule) weekdays() []time.Weekday {
+	weekdays := []time.Weekday{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if !s.anyDayOfMonth || s.dayOfWeek.has(int(day)) {
+			weekdays = append(weekdays, day)
+		}
+	}
+	return weekdays
+}
+
+func (s *Schedule) String() string {
+	return s.Expression
+}
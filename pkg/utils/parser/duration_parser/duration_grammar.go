@@ -0,0 +1,34 @@
+package duration_parser
+
+import (
+	. "github.com/openshift-online/ocm-common/pkg/utils/parser/state_machine"
+	. "github.com/openshift-online/ocm-common/pkg/utils/parser/string_parser"
+)
+
+const (
+	// Define the names of the tokens to be parsed
+
+	sign   = "SIGN"   // The optional leading `-` or `+`
+	number = "NUMBER" // An integer or decimal number
+	unit   = "UNIT"   // The unit of the preceding number
+)
+
+// DurationGrammar is the grammar of the Go durations, a sequence of numbers each followed by its unit, like
+// `1h30m` or `1.5d`
+func DurationGrammar() Grammar {
+	grammar := Grammar{
+		Tokens: []TokenDefinition{
+			{Name: sign, Acceptor: RegexpAcceptor(`[-+]`)},
+			{Name: number, Acceptor: RegexpAcceptor(`([0-9]+(\.[0-9]*)?|\.[0-9]+)`)},
+			{Name: unit, Acceptor: RegexpAcceptor(`(ns|us|µs|ms|s|m|h|d)`)},
+		},
+		Transitions: []TokenTransitions{
+			{TokenName: StartState, ValidTransitions: []string{sign, number}},
+			{TokenName: sign, ValidTransitions: []string{number}},
+			{TokenName: number, ValidTransitions: []string{unit}},
+			{TokenName: unit, ValidTransitions: []string{number, EndState}},
+		},
+	}
+
+	return grammar
+}
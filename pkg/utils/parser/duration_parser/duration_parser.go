@@ -0,0 +1,169 @@
+package duration_parser
+
+import (
+	"fmt"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/state_machine"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/string_parser"
+	"math"
+	"strings"
+	"time"
+)
+
+// Day is the duration of the `d` unit. Days are always 24 hours long, the durations ignore the calendar.
+const Day = 24 * time.Hour
+
+var unitDurations = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  Day,
+}
+
+// DurationParser - This object is to be used to parse and validate the durations of the cluster settings, like the
+// autoscaler delays. The syntax is the one of the Go durations (`1h30m`, `1.5s`), with the `d` unit for days.
+type DurationParser interface {
+	// Parse - parses the received duration and checks it is in the configured range
+	Parse(value string) (time.Duration, error)
+}
+
+type durationParser struct {
+	// configuration
+	minimum     *time.Duration
+	maximum     *time.Duration
+	withoutDays bool
+	parser      *string_parser.StringParser
+
+	// current parsing state
+	negative      bool
+	currentNumber string
+	total         time.Duration
+	overflow      bool
+}
+
+var _ DurationParser = &durationParser{}
+
+func (p *durationParser) Parse(value string) (time.Duration, error) {
+	p.reset()
+
+	// as for the Go durations, a zero doesn't need a unit
+	if value != "0" && value != "+0" && value != "-0" {
+		if err := p.parser.Parse(value); err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %v", value, err)
+		}
+	}
+	if p.overflow {
+		return 0, fmt.Errorf("invalid duration '%s': value out of range", value)
+	}
+
+	duration := p.total
+	if p.negative {
+		duration = -duration
+	}
+	if p.minimum != nil && duration < *p.minimum {
+		return 0, fmt.Errorf("duration '%s' must be at least %s", value, p.format(*p.minimum))
+	}
+	if p.maximum != nil && duration > *p.maximum {
+		return 0, fmt.Errorf("duration '%s' must be at most %s", value, p.format(*p.maximum))
+	}
+	return duration, nil
+}
+
+// format formats the duration in the syntax accepted by the parser
+func (p *durationParser) format(duration time.Duration) string {
+	if p.withoutDays {
+		return duration.String()
+	}
+	return FormatDuration(duration)
+}
+
+func (p *durationParser) reset() {
+	p.negative = false
+	p.currentNumber = ""
+	p.total = 0
+	p.overflow = false
+}
+
+func (p *durationParser) transitionInterceptor(_, to *state_machine.State[string, string], tokenValue string) error {
+	switch to.Name() {
+	case sign:
+		p.negative = tokenValue == "-"
+	case number:
+		p.currentNumber = tokenValue
+	case unit:
+		if p.withoutDays && tokenValue == "d" {
+			return fmt.Errorf("unknown unit `d`")
+		}
+		p.add(p.currentNumber, unitDurations[tokenValue])
+	}
+	return nil
+}
+
+// add adds the number of units to the total. As for the Go durations, the integer part is computed with integers to
+// keep the precision and the fraction is rounded down to the nanosecond.
+func (p *durationParser) add(number string, unitDuration time.Duration) {
+	integer, fraction, _ := strings.Cut(number, ".")
+	var value uint64
+	for _, digit := range integer {
+		if value > math.MaxInt64/10 {
+			p.overflow = true
+			return
+		}
+		value = value*10 + uint64(digit-'0')
+	}
+	if value > math.MaxInt64/uint64(unitDuration) {
+		p.overflow = true
+		return
+	}
+	value *= uint64(unitDuration)
+
+	var fractionValue uint64
+	scale := 1.0
+	for _, digit := range fraction {
+		// digits beyond the precision of the fraction are ignored
+		if fractionValue > math.MaxInt64/10 {
+			break
+		}
+		fractionValue = fractionValue*10 + uint64(digit-'0')
+		scale *= 10
+	}
+	value += uint64(float64(fractionValue) * (float64(unitDuration) / scale))
+
+	if value > math.MaxInt64-uint64(p.total) {
+		p.overflow = true
+		return
+	}
+	p.total += time.Duration(value)
+}
+
+// FormatDuration returns the canonical form of the duration: days first, then the Go duration of the rest without
+// its zero minutes and seconds, like `1d2h` or `1h30m`
+func FormatDuration(duration time.Duration) string {
+	if duration == 0 {
+		return "0s"
+	}
+	if duration < 0 {
+		if duration == math.MinInt64 {
+			return duration.String()
+		}
+		return "-" + FormatDuration(-duration)
+	}
+	result := ""
+	if days := duration / Day; days > 0 {
+		result = fmt.Sprintf("%dd", days)
+	}
+	if rest := duration % Day; rest > 0 {
+		formatted := rest.String()
+		if strings.HasSuffix(formatted, "m0s") {
+			formatted = strings.TrimSuffix(formatted, "0s")
+		}
+		if strings.HasSuffix(formatted, "h0m") {
+			formatted = strings.TrimSuffix(formatted, "0m")
+		}
+		result += formatted
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package duration_parser
+
+import (
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/string_parser"
+	"time"
+)
+
+type DurationParserOption func(parser *durationParser)
+
+// WithMinimum rejects the durations lower than minimum
+func WithMinimum(minimum time.Duration) DurationParserOption {
+	return func(parser *durationParser) {
+		parser.minimum = &minimum
+	}
+}
+
+// WithMaximum rejects the durations greater than maximum
+func WithMaximum(maximum time.Duration) DurationParserOption {
+	return func(parser *durationParser) {
+		parser.maximum = &maximum
+	}
+}
+
+// WithNonNegative rejects the negative durations
+func WithNonNegative() DurationParserOption {
+	return WithMinimum(0)
+}
+
+// WithoutDays rejects the `d` unit, for the settings that are Go durations, like the cluster autoscaler delays
+func WithoutDays() DurationParserOption {
+	return func(parser *durationParser) {
+		parser.withoutDays = true
+	}
+}
+
+func NewDurationParser(options ...DurationParserOption) DurationParser {
+	parser := &durationParser{}
+
+	for _, option := range options {
+		option(parser)
+	}
+
+	parser.parser = string_parser.NewStringParserBuilder().
+		WithGrammar(DurationGrammar()).
+		WithTransitionInterceptor(parser.transitionInterceptor).
+		WithScanner(NewDurationScanner()).
+		Build()
+	return parser
+}
+
+// ParseDuration parses the duration without range checks
+func ParseDuration(value string) (time.Duration, error) {
+	return NewDurationParser().Parse(value)
+}
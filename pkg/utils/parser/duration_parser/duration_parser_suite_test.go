@@ -0,0 +1,13 @@
+package duration_parser_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDurationParser(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DurationParser Suite")
+}
@@ -0,0 +1,69 @@
+package duration_parser
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DurationParser", func() {
+	DescribeTable("Valid durations", func(value string, expected time.Duration, canonical string) {
+		duration, err := ParseDuration(value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(duration).To(Equal(expected))
+		Expect(FormatDuration(duration)).To(Equal(canonical))
+	},
+		Entry("minutes", "10m", 10*time.Minute, "10m"),
+		Entry("hours and minutes", "1h30m", 90*time.Minute, "1h30m"),
+		Entry("minutes over an hour", "90m", 90*time.Minute, "1h30m"),
+		Entry("days", "2d", 48*time.Hour, "2d"),
+		Entry("fractional days", "1.5d", 36*time.Hour, "1d12h"),
+		Entry("hours over a day", "25h", 25*time.Hour, "1d1h"),
+		Entry("every unit", "1d1h1m1s1ms1us1ns", Day+time.Hour+time.Minute+time.Second+time.Millisecond+
+			time.Microsecond+time.Nanosecond, "1d1h1m1.001001001s"),
+		Entry("fraction without integer", ".5s", 500*time.Millisecond, "500ms"),
+		Entry("negative", "-2h", -2*time.Hour, "-2h"),
+		Entry("explicit sign", "+5s", 5*time.Second, "5s"),
+		Entry("zero without unit", "0", time.Duration(0), "0s"),
+	)
+
+	DescribeTable("Invalid durations", func(value string, errMessage string) {
+		_, err := ParseDuration(value)
+		Expect(err).To(MatchError(errMessage))
+	},
+		Entry("empty", "", "invalid duration '': EOF encountered while parsing string"),
+		Entry("number without unit", "10",
+			"invalid duration '10': EOF encountered while parsing string"),
+		Entry("unknown unit", "1w", "invalid duration '1w': [2] error parsing the filter: unexpected token `w`"),
+		Entry("blank", "1h 30m", "invalid duration '1h 30m': [3] error parsing the filter: unexpected token ` `"),
+		Entry("two decimal points", "1.2.3s",
+			"invalid duration '1.2.3s': [1] error parsing the filter: unexpected token `1.2.3`"),
+		Entry("overflow", "100000000000000000000ns", "invalid duration '100000000000000000000ns': value out of range"),
+		Entry("sum overflow", "106751d106751d", "invalid duration '106751d106751d': value out of range"),
+	)
+
+	It("checks the range", func() {
+		parser := NewDurationParser(WithNonNegative(), WithMaximum(7*Day))
+		_, err := parser.Parse("-1s")
+		Expect(err).To(MatchError("duration '-1s' must be at least 0s"))
+		_, err = parser.Parse("169h")
+		Expect(err).To(MatchError("duration '169h' must be at most 7d"))
+		duration, err := parser.Parse("168h")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(duration).To(Equal(7 * Day))
+	})
+
+	It("rejects the days when they are disabled", func() {
+		parser := NewDurationParser(WithoutDays(), WithMaximum(48*time.Hour))
+		_, err := parser.Parse("1d")
+		Expect(err).To(MatchError("invalid duration '1d': [2] error parsing the filter: unknown unit `d`"))
+		_, err = parser.Parse("1h1d")
+		Expect(err).To(MatchError("invalid duration '1h1d': [4] error parsing the filter: unknown unit `d`"))
+		_, err = parser.Parse("49h")
+		Expect(err).To(MatchError("duration '49h' must be at most 48h0m0s"))
+		duration, err := parser.Parse("36h")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(duration).To(Equal(36 * time.Hour))
+	})
+})
@@ -0,0 +1,87 @@
+package duration_parser
+
+import (
+	"fmt"
+	"github.com/openshift-online/ocm-common/pkg/utils/parser/string_scanner"
+	"unicode"
+)
+
+const (
+	NUMBER = iota
+	UNIT
+	SYMBOL
+)
+
+// scanner - This scanner is to be used to parse durations. It splits the provided string into numbers (with their
+// decimal point), units and single symbols, like the sign. Blanks are not allowed and are returned as symbols.
+type scanner struct {
+	tokens []string_scanner.Token
+	pos    int
+}
+
+var _ string_scanner.Scanner = &scanner{}
+
+// Init feeds the scanner with the text to be scanned
+func (s *scanner) Init(txt string) {
+	s.pos = -1
+	s.tokens = nil
+
+	var current *string_scanner.Token
+	sendCurrentToken := func() {
+		if current != nil {
+			s.tokens = append(s.tokens, *current)
+			current = nil
+		}
+	}
+
+	for i, currentChar := range txt {
+		tokenType := SYMBOL
+		switch {
+		case currentChar >= '0' && currentChar <= '9' || currentChar == '.':
+			tokenType = NUMBER
+		case unicode.IsLetter(currentChar):
+			tokenType = UNIT
+		}
+		if current != nil && (current.TokenType != tokenType || tokenType == SYMBOL) {
+			sendCurrentToken()
+		}
+		if current == nil {
+			current = &string_scanner.Token{TokenType: tokenType, Position: i}
+		}
+		current.Value += string(currentChar)
+	}
+	sendCurrentToken()
+}
+
+// Next moves to the next token and return `true` if another token is present. Otherwise returns `false`
+func (s *scanner) Next() bool {
+	if s.pos < (len(s.tokens) - 1) {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// Peek looks if another token is present after the current position without moving the cursor
+func (s *scanner) Peek() (bool, *string_scanner.Token) {
+	if s.pos < (len(s.tokens) - 1) {
+		ret := s.tokens[s.pos+1]
+		return true, &ret
+	}
+	return false, nil
+}
+
+// Token returns the current token
+func (s *scanner) Token() *string_scanner.Token {
+	if s.pos < 0 || s.pos >= len(s.tokens) {
+		panic(fmt.Errorf("invalid scanner position %d", s.pos))
+	}
+	ret := s.tokens[s.pos]
+	return &ret
+}
+
+func NewDurationScanner() string_scanner.Scanner {
+	return &scanner{
+		pos: -1,
+	}
+}
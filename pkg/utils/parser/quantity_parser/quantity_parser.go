@@ -0,0 +1,89 @@
+package quantity_parser
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// QuantityParser - This object is to be used to parse and validate the Kubernetes resource quantities of the
+// cluster settings, like memory (`512Mi`, `1G`) or CPU (`2.5`, `500m`) limits. The syntax is the Kubernetes one:
+// a decimal number followed by a binary (`Ki`, `Mi`, ...), decimal (`m`, `k`, `M`, ...) or exponent (`e3`) suffix.
+type QuantityParser interface {
+	// Parse - parses the received quantity and checks it is in the configured range
+	Parse(value string) (resource.Quantity, error)
+}
+
+type quantityParser struct {
+	minimum      *resource.Quantity
+	maximum      *resource.Quantity
+	allowDecimal bool
+}
+
+var _ QuantityParser = &quantityParser{}
+
+func (p *quantityParser) Parse(value string) (resource.Quantity, error) {
+	quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid quantity '%s': %v", value, err)
+	}
+	if !p.allowDecimal && quantity.MilliValue()%1000 != 0 {
+		return resource.Quantity{}, fmt.Errorf("quantity '%s' must be a whole number", value)
+	}
+	if p.minimum != nil && quantity.Cmp(*p.minimum) < 0 {
+		return resource.Quantity{}, fmt.Errorf("quantity '%s' must be at least %s", value, p.minimum.String())
+	}
+	if p.maximum != nil && quantity.Cmp(*p.maximum) > 0 {
+		return resource.Quantity{}, fmt.Errorf("quantity '%s' must be at most %s", value, p.maximum.String())
+	}
+	return quantity, nil
+}
+
+type QuantityParserOption func(parser *quantityParser)
+
+// WithMinimum rejects the quantities lower than minimum, which must be a valid quantity
+func WithMinimum(minimum string) QuantityParserOption {
+	return func(parser *quantityParser) {
+		quantity := resource.MustParse(minimum)
+		parser.minimum = &quantity
+	}
+}
+
+// WithMaximum rejects the quantities greater than maximum, which must be a valid quantity
+func WithMaximum(maximum string) QuantityParserOption {
+	return func(parser *quantityParser) {
+		quantity := resource.MustParse(maximum)
+		parser.maximum = &quantity
+	}
+}
+
+// WithWholeNumbers rejects the quantities with a fractional part, like `1.5` or `500m`. `1.5Ki` is accepted as
+// it is 1536.
+func WithWholeNumbers() QuantityParserOption {
+	return func(parser *quantityParser) {
+		parser.allowDecimal = false
+	}
+}
+
+func NewQuantityParser(options ...QuantityParserOption) QuantityParser {
+	parser := &quantityParser{
+		allowDecimal: true,
+	}
+
+	for _, option := range options {
+		option(parser)
+	}
+	return parser
+}
+
+// ParseQuantity parses the quantity without range checks
+func ParseQuantity(value string) (resource.Quantity, error) {
+	return NewQuantityParser().Parse(value)
+}
+
+// FormatQuantity returns the canonical form of the quantity, the one Kubernetes stores: `1536Mi` for 1.5Gi, `2500m`
+// for 2.5
+func FormatQuantity(quantity resource.Quantity) string {
+	return quantity.String()
+}
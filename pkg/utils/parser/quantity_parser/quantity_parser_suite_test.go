@@ -0,0 +1,13 @@
+package quantity_parser_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuantityParser(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "QuantityParser Suite")
+}
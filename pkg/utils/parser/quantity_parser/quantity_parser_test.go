@@ -0,0 +1,51 @@
+package quantity_parser
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QuantityParser", func() {
+	DescribeTable("Valid quantities", func(value string, canonical string) {
+		quantity, err := ParseQuantity(value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(FormatQuantity(quantity)).To(Equal(canonical))
+	},
+		Entry("binary suffix", "512Mi", "512Mi"),
+		Entry("fractional binary suffix", "1.5Gi", "1536Mi"),
+		Entry("decimal suffix", "1G", "1G"),
+		Entry("decimal CPU", "2.5", "2500m"),
+		Entry("millicores", "500m", "500m"),
+		Entry("exponent", "1e3", "1e3"),
+		Entry("surrounding blanks", " 2 ", "2"),
+	)
+
+	DescribeTable("Invalid quantities", func(value string, errMessage string) {
+		_, err := ParseQuantity(value)
+		Expect(err).To(MatchError(ContainSubstring(errMessage)))
+	},
+		Entry("empty", "", "invalid quantity ''"),
+		Entry("unknown suffix", "1Gb", "invalid quantity '1Gb'"),
+		Entry("blank inside", "1 Gi", "invalid quantity '1 Gi'"),
+	)
+
+	It("checks the range", func() {
+		parser := NewQuantityParser(WithMinimum("256Mi"), WithMaximum("2Gi"))
+		_, err := parser.Parse("128Mi")
+		Expect(err).To(MatchError("quantity '128Mi' must be at least 256Mi"))
+		_, err = parser.Parse("3G")
+		Expect(err).To(MatchError("quantity '3G' must be at most 2Gi"))
+		quantity, err := parser.Parse("1.5Gi")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(FormatQuantity(quantity)).To(Equal("1536Mi"))
+	})
+
+	It("rejects fractions of whole numbers", func() {
+		parser := NewQuantityParser(WithWholeNumbers())
+		_, err := parser.Parse("2.5")
+		Expect(err).To(MatchError("quantity '2.5' must be a whole number"))
+		quantity, err := parser.Parse("1.5Ki")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(quantity.Value()).To(Equal(int64(1536)))
+	})
+})
@@ -0,0 +1,120 @@
+package sql_parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// IndexedPredicateCost is the cost of a predicate that can be resolved using the index of its column
+	IndexedPredicateCost = 1
+	// DefaultScanCost is the cost of a predicate that requires scanning its column, when the column metadata
+	// doesn't declare one
+	DefaultScanCost = 10
+)
+
+// ColumnMetadata describes how expensive it is to filter on a column
+type ColumnMetadata struct {
+	// Indexed tells if the column is indexed: equality, range, IN and prefix LIKE predicates on it cost
+	// IndexedPredicateCost
+	Indexed bool
+	// ScanCost is the cost of the predicates that can't use an index, like ILIKE, LIKE '%...' or <>.
+	// Defaults to DefaultScanCost
+	ScanCost int
+}
+
+// PredicateCost is the cost of a single predicate of a filter
+type PredicateCost struct {
+	// Predicate is the predicate as written by the user, ie: `name ILIKE '%test%'`
+	Predicate string
+	Cost      int
+}
+
+// FilterCost is the cost score of a parsed filter
+type FilterCost struct {
+	// Total is the sum of the costs of all the predicates
+	Total      int
+	Predicates []PredicateCost
+}
+
+// predicate accumulates the tokens of the predicate being parsed
+type predicate struct {
+	column string
+	jsonb  bool
+	// the JSONB path following the column, ie: ->'data'->>'name'
+	path     []string
+	operator []string
+	values   []string
+	// unquoted values, used to inspect LIKE patterns
+	rawValues []string
+}
+
+func (p *predicate) String() string {
+	value := strings.Join(p.values, ", ")
+	if len(p.values) > 1 || strings.HasSuffix(p.operatorName(), "IN") {
+		value = "(" + value + ")"
+	}
+	return fmt.Sprintf("%s%s %s %s", p.column, strings.Join(p.path, ""), strings.Join(p.operator, " "), value)
+}
+
+func (p *predicate) operatorName() string {
+	return strings.ToUpper(strings.Join(p.operator, " "))
+}
+
+// predicateCost computes the cost of the predicate according to the metadata of its column. Columns without
+// metadata are considered not indexed.
+func (p *sqlParser) predicateCost(pred *predicate) int {
+	metadata, found := p.columnsMetadata[pred.column]
+	scanCost := DefaultScanCost
+	if found && metadata.ScanCost > 0 {
+		scanCost = metadata.ScanCost
+	}
+	if !found || !metadata.Indexed || pred.jsonb {
+		return scanCost
+	}
+	switch pred.operatorName() {
+	case "=", "<", ">", "<=", ">=", "IN":
+		return IndexedPredicateCost
+	case "LIKE":
+		if len(pred.rawValues) > 0 && !strings.HasPrefix(pred.rawValues[0], "%") &&
+			!strings.HasPrefix(pred.rawValues[0], "_") {
+			return IndexedPredicateCost
+		}
+	}
+	return scanCost
+}
+
+// closePredicate adds the cost of the predicate being parsed, if any, to the cost of the filter
+func (p *sqlParser) closePredicate() {
+	if p.currentPredicate == nil {
+		return
+	}
+	cost := p.predicateCost(p.currentPredicate)
+	p.cost.Total += cost
+	p.cost.Predicates = append(p.cost.Predicates, PredicateCost{Predicate: p.currentPredicate.String(), Cost: cost})
+	p.currentPredicate = nil
+}
+
+// checkCost returns an error listing the predicates that can't use an index when the cost of the filter
+// exceeds the maximum cost
+func (p *sqlParser) checkCost() error {
+	if p.maximumCost <= 0 || p.cost.Total <= p.maximumCost {
+		return nil
+	}
+	expensive := []PredicateCost{}
+	for _, predicateCost := range p.cost.Predicates {
+		if predicateCost.Cost > IndexedPredicateCost {
+			expensive = append(expensive, predicateCost)
+		}
+	}
+	sort.SliceStable(expensive, func(i, j int) bool {
+		return expensive[i].Cost > expensive[j].Cost
+	})
+	descriptions := []string{}
+	for _, predicateCost := range expensive {
+		descriptions = append(descriptions, fmt.Sprintf("`%s` (%d)", predicateCost.Predicate, predicateCost.Cost))
+	}
+	return fmt.Errorf("maximum permitted filter cost (%d) exceeded: the filter costs %d, expensive predicates are: %s",
+		p.maximumCost, p.cost.Total, strings.Join(descriptions, ", "))
+}
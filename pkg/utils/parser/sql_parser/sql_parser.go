@@ -17,11 +17,16 @@ type SQLParser interface {
 	// - interface{}: All the values to pass to the database (to replace the '?' placeholders)
 	// - error: non nil in case of any error
 	Parse(sql string) (string, interface{}, error)
+
+	// Cost - returns the cost score of the last parsed SQL string, with the cost of each of its predicates
+	Cost() FilterCost
 }
 
 type sqlParser struct {
 	// configuration
 	maximumComplexity int
+	maximumCost       int
+	columnsMetadata   map[string]ColumnMetadata
 	parser            *string_parser.StringParser
 
 	// current parsing state
@@ -31,6 +36,9 @@ type sqlParser struct {
 	openBraces   int
 	validColumns []string
 	columnPrefix string
+	// the predicate being parsed and the cost of the completed ones
+	currentPredicate *predicate
+	cost             FilterCost
 
 	// current parsing result
 	resultQry    string
@@ -50,13 +58,24 @@ func (p *sqlParser) Parse(sql string) (string, interface{}, error) {
 		return "", nil, fmt.Errorf("EOF while searching for closing brace ')'")
 	}
 
+	p.closePredicate()
+	if err := p.checkCost(); err != nil {
+		return "", nil, err
+	}
+
 	p.resultQry = strings.Trim(p.resultQry, " ")
 	return p.resultQry, p.resultValues, nil
 }
 
+func (p *sqlParser) Cost() FilterCost {
+	return p.cost
+}
+
 func (p *sqlParser) reset() {
 	p.complexity = 0
 	p.openBraces = 0
+	p.currentPredicate = nil
+	p.cost = FilterCost{}
 	p.resultQry = ""
 	p.resultValues = nil
 }
@@ -86,6 +105,7 @@ func (p *sqlParser) transitionInterceptor(_, to *state_machine.State[string, str
 	case valueTokenFamily:
 		p.resultQry += " ?"
 		p.resultValues = append(p.resultValues, tokenValue)
+		p.addPredicateValue(tokenValue, tokenValue)
 		return nil
 	case quotedValueTokenFamily:
 		p.resultQry += " ?"
@@ -96,8 +116,13 @@ func (p *sqlParser) transitionInterceptor(_, to *state_machine.State[string, str
 			tmp = string([]rune(tmp)[1 : len(tmp)-1])
 		}
 		p.resultValues = append(p.resultValues, tmp)
+		p.addPredicateValue(tokenValue, tmp)
 		return nil
 	case logicalOpTokenFamily:
+		if p.currentPredicate != nil && len(p.currentPredicate.values) == 0 {
+			// NOT IN
+			p.currentPredicate.operator = append(p.currentPredicate.operator, tokenValue)
+		}
 		p.complexity++
 		if p.complexity > p.maximumComplexity {
 			return fmt.Errorf("maximum number of permitted joins (%d) exceeded", p.maximumComplexity)
@@ -113,14 +138,40 @@ func (p *sqlParser) transitionInterceptor(_, to *state_machine.State[string, str
 		if p.columnPrefix != "" && !strings.HasPrefix(columnName, p.columnPrefix+".") {
 			columnName = p.columnPrefix + "." + columnName
 		}
+		p.closePredicate()
+		p.currentPredicate = &predicate{column: strings.ToLower(tokenValue)}
 		p.resultQry += columnName
 		return nil
+	case opTokenFamily:
+		if p.currentPredicate != nil {
+			p.currentPredicate.operator = append(p.currentPredicate.operator, tokenValue)
+		}
+		p.resultQry += " " + tokenValue
+		return nil
+	case jsonbFamily:
+		if p.currentPredicate != nil {
+			p.currentPredicate.jsonb = true
+			if tokenValue == jsonbContains {
+				p.currentPredicate.operator = append(p.currentPredicate.operator, tokenValue)
+			} else {
+				p.currentPredicate.path = append(p.currentPredicate.path, tokenValue)
+			}
+		}
+		p.resultQry += " " + tokenValue
+		return nil
 	default:
 		p.resultQry += " " + tokenValue
 		return nil
 	}
 }
 
+func (p *sqlParser) addPredicateValue(tokenValue string, rawValue string) {
+	if p.currentPredicate != nil {
+		p.currentPredicate.values = append(p.currentPredicate.values, tokenValue)
+		p.currentPredicate.rawValues = append(p.currentPredicate.rawValues, rawValue)
+	}
+}
+
 func contains(ary []string, value string) bool {
 	for _, v := range ary {
 		if v == value {
@@ -25,6 +25,24 @@ func WithMaximumComplexity(maximumComplexity int) SQLParserOption {
 	}
 }
 
+// WithColumnsMetadata declares how expensive it is to filter on each column, see ColumnMetadata.
+// Columns without metadata are considered not indexed.
+func WithColumnsMetadata(columnsMetadata map[string]ColumnMetadata) SQLParserOption {
+	return func(parser *sqlParser) {
+		parser.columnsMetadata = map[string]ColumnMetadata{}
+		for column, metadata := range columnsMetadata {
+			parser.columnsMetadata[strings.ToLower(column)] = metadata
+		}
+	}
+}
+
+// WithMaximumCost rejects the filters whose cost score exceeds maximumCost. A value lower than 1 disables the check.
+func WithMaximumCost(maximumCost int) SQLParserOption {
+	return func(parser *sqlParser) {
+		parser.maximumCost = maximumCost
+	}
+}
+
 func NewSQLParser(options ...SQLParserOption) SQLParser {
 	parser := &sqlParser{
 		maximumComplexity: defaultMaximumComplexity,
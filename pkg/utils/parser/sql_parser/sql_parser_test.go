@@ -278,4 +278,61 @@ var _ = Describe("SQLParser", func() {
 			wantErr:   false,
 		}, NewSQLParser(WithColumnPrefix("main"))),
 	)
+
+	Context("COST", func() {
+		metadata := map[string]ColumnMetadata{
+			"name":        {Indexed: true},
+			"Region":      {Indexed: true, ScanCost: 5},
+			"description": {ScanCost: 20},
+		}
+
+		It("Computes the cost of each predicate", func() {
+			parser := NewSQLParser(WithColumnsMetadata(metadata))
+			_, _, err := parser.Parse("name = 'test' and region LIKE 'eu-%' and region <> 'us-east-1' and " +
+				"description ILIKE '%test%' and owner in ('a', 'b') and name->'data'->>'field' = 'x' and region not in (x)")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parser.Cost()).To(Equal(FilterCost{
+				Total: 1 + 1 + 5 + 20 + DefaultScanCost + DefaultScanCost + 5,
+				Predicates: []PredicateCost{
+					{Predicate: "name = 'test'", Cost: 1},
+					{Predicate: "region LIKE 'eu-%'", Cost: 1},
+					{Predicate: "region <> 'us-east-1'", Cost: 5},
+					{Predicate: "description ILIKE '%test%'", Cost: 20},
+					{Predicate: "owner in ('a', 'b')", Cost: DefaultScanCost},
+					{Predicate: "name->'data'->>'field' = 'x'", Cost: DefaultScanCost},
+					{Predicate: "region not in (x)", Cost: 5},
+				},
+			}))
+		})
+
+		It("Treats leading wildcards as scans", func() {
+			parser := NewSQLParser(WithColumnsMetadata(metadata))
+			_, _, err := parser.Parse("name LIKE '%test'")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parser.Cost().Total).To(Equal(DefaultScanCost))
+		})
+
+		It("Resets the cost between parses", func() {
+			parser := NewSQLParser(WithColumnsMetadata(metadata))
+			_, _, err := parser.Parse("description ILIKE '%test%'")
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = parser.Parse("name = test")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parser.Cost().Total).To(Equal(IndexedPredicateCost))
+		})
+
+		It("Accepts filters within the budget", func() {
+			parser := NewSQLParser(WithColumnsMetadata(metadata), WithMaximumCost(10))
+			qry, _, err := parser.Parse("name = a or name = b or (region = c and region LIKE 'us%')")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qry).To(Equal("name = ? or name = ? or (region = ? and region LIKE ?)"))
+		})
+
+		It("Rejects filters over the budget reporting the expensive predicates", func() {
+			parser := NewSQLParser(WithColumnsMetadata(metadata), WithMaximumCost(10))
+			_, _, err := parser.Parse("name = a and region <> b and description ILIKE '%x%'")
+			Expect(err).To(MatchError("maximum permitted filter cost (10) exceeded: the filter costs 26, " +
+				"expensive predicates are: `description ILIKE '%x%'` (20), `region <> b` (5)"))
+		})
+	})
 })
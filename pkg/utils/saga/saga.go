@@ -0,0 +1,330 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openshift-online/ocm-common/pkg/log"
+)
+
+// State holds the values the steps share, like the IDs of the created resources. It is saved with the
+// checkpoint so that the steps of a resumed run and the undo actions can find the values of the previous runs.
+type State map[string]string
+
+// Get returns the value of the key, or an empty string
+func (s State) Get(key string) string {
+	return s[key]
+}
+
+// Set records the value of the key
+func (s State) Set(key string, value string) {
+	s[key] = value
+}
+
+// Action is the function run by a step or by its undo action
+type Action func(ctx context.Context, state State) error
+
+// Step is a unit of work with the action compensating it
+type Step struct {
+	Name string
+	// Do runs again when a failed or interrupted saga resumes, it must adopt what a previous attempt created, as
+	// recorded in the state or found by name, instead of creating it twice
+	Do Action
+	// Undo reverts Do, it is optional for the steps that create nothing. It is also called when Do fails, so it
+	// must tolerate the partial state left by Do, like the resources created before the failure.
+	Undo Action
+}
+
+// Checkpoint is the progress of a saga: the names of the completed steps, in order, the name of the step that
+// failed, if any, and the state
+type Checkpoint struct {
+	Completed []string `json:"completed"`
+	Failed    string   `json:"failed,omitempty"`
+	State     State    `json:"state"`
+}
+
+// Error is returned when a step fails, with the errors of the undo actions that failed during the rollback
+type Error struct {
+	Saga         string
+	Step         string
+	Err          error
+	RollbackErrs []error
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("saga %s failed at step %s: %v", e.Saga, e.Step, e.Err)
+	if len(e.RollbackErrs) > 0 {
+		message = fmt.Sprintf("%s; rollback failed: %v", message, errors.Join(e.RollbackErrs...))
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Phase is the stage of a step reported to the observer
+type Phase string
+
+const (
+	StepStarted    Phase = "started"
+	StepFinished   Phase = "finished"
+	StepFailed     Phase = "failed"
+	StepSkipped    Phase = "skipped"
+	StepUndone     Phase = "undone"
+	StepUndoFailed Phase = "undo-failed"
+)
+
+// StepEvent reports the progress of a step. Index is the position of the step in the saga, Elapsed is the
+// duration of the action for the finished, failed, undone and undo-failed phases.
+type StepEvent struct {
+	Saga    string
+	Step    string
+	Index   int
+	Total   int
+	Phase   Phase
+	Elapsed time.Duration
+	Err     error
+	State   State
+}
+
+// Observer receives the events of the steps, it is called synchronously by the saga
+type Observer func(event StepEvent)
+
+// Saga runs steps in order. When a step fails its partial changes are undone, then the completed steps in reverse
+// order, unless the rollback is disabled, in which case a later run resumes from the failed step.
+// The progress can be saved to a checkpoint file to resume or roll back from another process.
+type Saga struct {
+	Name           string
+	Steps          []Step
+	CheckpointFile string
+	// KeepOnFailure disables the rollback on failure, the completed steps are kept to resume later
+	KeepOnFailure bool
+	// Observer, when set, is notified of the progress of the steps
+	Observer Observer
+
+	checkpoint *Checkpoint
+}
+
+// New creates an empty saga
+func New(name string) *Saga {
+	return &Saga{Name: name}
+}
+
+// Step appends a step to the saga, undo can be nil
+func (s *Saga) Step(name string, do Action, undo Action) *Saga {
+	s.Steps = append(s.Steps, Step{Name: name, Do: do, Undo: undo})
+	return s
+}
+
+// Checkpoint sets the file the progress is saved to and loaded from
+func (s *Saga) Checkpoint(path string) *Saga {
+	s.CheckpointFile = path
+	return s
+}
+
+// KeepCompletedOnFailure disables the rollback on failure so that a later run resumes from the failed step
+func (s *Saga) KeepCompletedOnFailure(keep bool) *Saga {
+	s.KeepOnFailure = keep
+	return s
+}
+
+// Observe sets the function notified of the progress of the steps
+func (s *Saga) Observe(observer Observer) *Saga {
+	s.Observer = observer
+	return s
+}
+
+// State returns the state of the saga, loading the checkpoint file if needed
+func (s *Saga) State() (State, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s.checkpoint.State, nil
+}
+
+// Run runs the steps not completed yet. On success the checkpoint file is removed and the state returned.
+// On failure an *Error is returned with the state; after a complete rollback the checkpoint file is removed too.
+func (s *Saga) Run(ctx context.Context) (State, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	completed := map[string]bool{}
+	for _, name := range s.checkpoint.Completed {
+		completed[name] = true
+	}
+	for index, step := range s.Steps {
+		if completed[step.Name] {
+			log.LogDebug("Saga %s: step %s already completed, skipping", s.Name, step.Name)
+			s.notify(index, StepSkipped, 0, nil)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			s.notify(index, StepFailed, 0, err)
+			return s.fail(ctx, step.Name, err)
+		}
+		log.LogInfo("Saga %s: running step %s", s.Name, step.Name)
+		s.notify(index, StepStarted, 0, nil)
+		start := time.Now()
+		if err := step.Do(ctx, s.checkpoint.State); err != nil {
+			log.LogError("Saga %s: step %s failed: %s", s.Name, step.Name, err)
+			s.notify(index, StepFailed, time.Since(start), err)
+			s.checkpoint.Failed = step.Name
+			return s.fail(ctx, step.Name, err)
+		}
+		s.notify(index, StepFinished, time.Since(start), nil)
+		s.checkpoint.Completed = append(s.checkpoint.Completed, step.Name)
+		s.checkpoint.Failed = ""
+		if err := s.save(); err != nil {
+			return s.fail(ctx, step.Name, err)
+		}
+	}
+	// The progress is kept in memory so that Rollback can still undo a complete run
+	return s.checkpoint.State, s.removeCheckpointFile()
+}
+
+// Rollback undoes the partial changes of the failed step, then the completed steps in reverse order. The steps
+// whose undo fails stay in the checkpoint so that the rollback can be retried.
+func (s *Saga) Rollback(ctx context.Context) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	steps := map[string]Step{}
+	indexes := map[string]int{}
+	for index, step := range s.Steps {
+		steps[step.Name] = step
+		indexes[step.Name] = index
+	}
+	var errs []error
+	undo := func(name string) bool {
+		step, ok := steps[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("step %s of the checkpoint is unknown", name))
+			return false
+		}
+		if step.Undo == nil {
+			return true
+		}
+		log.LogInfo("Saga %s: undoing step %s", s.Name, name)
+		// The undo actions must run even if the context of the failed run is cancelled
+		start := time.Now()
+		if err := step.Undo(context.WithoutCancel(ctx), s.checkpoint.State); err != nil {
+			log.LogError("Saga %s: undo of step %s failed: %s", s.Name, name, err)
+			s.notify(indexes[name], StepUndoFailed, time.Since(start), err)
+			errs = append(errs, fmt.Errorf("undo of step %s failed: %v", name, err))
+			return false
+		}
+		s.notify(indexes[name], StepUndone, time.Since(start), nil)
+		return true
+	}
+	if s.checkpoint.Failed != "" && undo(s.checkpoint.Failed) {
+		s.checkpoint.Failed = ""
+	}
+	remaining := []string{}
+	for i := len(s.checkpoint.Completed) - 1; i >= 0; i-- {
+		name := s.checkpoint.Completed[i]
+		if !undo(name) {
+			remaining = append([]string{name}, remaining...)
+		}
+	}
+	s.checkpoint.Completed = remaining
+	if len(errs) > 0 {
+		if err := s.save(); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+	return s.clear()
+}
+
+func (s *Saga) notify(index int, phase Phase, elapsed time.Duration, err error) {
+	if s.Observer == nil {
+		return
+	}
+	s.Observer(StepEvent{
+		Saga:    s.Name,
+		Step:    s.Steps[index].Name,
+		Index:   index,
+		Total:   len(s.Steps),
+		Phase:   phase,
+		Elapsed: elapsed,
+		Err:     err,
+		State:   s.checkpoint.State,
+	})
+}
+
+func (s *Saga) fail(ctx context.Context, stepName string, err error) (State, error) {
+	sagaErr := &Error{Saga: s.Name, Step: stepName, Err: err}
+	state := s.checkpoint.State
+	if s.KeepOnFailure {
+		// The failed step is saved so that a later rollback undoes its partial changes too
+		if saveErr := s.save(); saveErr != nil {
+			sagaErr.Err = errors.Join(err, saveErr)
+		}
+		return state, sagaErr
+	}
+	if rollbackErr := s.Rollback(ctx); rollbackErr != nil {
+		sagaErr.RollbackErrs = []error{rollbackErr}
+	}
+	return state, sagaErr
+}
+
+func (s *Saga) load() error {
+	if s.checkpoint != nil {
+		return nil
+	}
+	s.checkpoint = &Checkpoint{Completed: []string{}, State: State{}}
+	if s.CheckpointFile == "" {
+		return nil
+	}
+	content, err := os.ReadFile(s.CheckpointFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint of saga %s: %v", s.Name, err)
+	}
+	if err = json.Unmarshal(content, s.checkpoint); err != nil {
+		return fmt.Errorf("failed to parse checkpoint %s of saga %s: %v", s.CheckpointFile, s.Name, err)
+	}
+	if s.checkpoint.State == nil {
+		s.checkpoint.State = State{}
+	}
+	log.LogInfo("Saga %s: resuming from checkpoint %s with %d completed steps", s.Name, s.CheckpointFile,
+		len(s.checkpoint.Completed))
+	return nil
+}
+
+func (s *Saga) save() error {
+	if s.CheckpointFile == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(s.checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(s.CheckpointFile, content, 0600); err != nil {
+		return fmt.Errorf("failed to save checkpoint of saga %s: %v", s.Name, err)
+	}
+	return nil
+}
+
+// clear forgets the progress once the saga is fully rolled back
+func (s *Saga) clear() error {
+	s.checkpoint = nil
+	return s.removeCheckpointFile()
+}
+
+func (s *Saga) removeCheckpointFile() error {
+	if s.CheckpointFile == "" {
+		return nil
+	}
+	if err := os.Remove(s.CheckpointFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint of saga %s: %v", s.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package saga_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSaga(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Saga Suite")
+}
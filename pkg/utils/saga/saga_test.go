@@ -0,0 +1,246 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-common/pkg/utils/saga"
+)
+
+var _ = Describe("Saga", func() {
+	var (
+		ctx     context.Context
+		journal []string
+	)
+
+	// record returns an action appending the message to the journal and failing when err is set
+	record := func(message string, err error) saga.Action {
+		return func(ctx context.Context, state saga.State) error {
+			journal = append(journal, message)
+			if err == nil {
+				state.Set(message, "done")
+			}
+			return err
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		journal = []string{}
+	})
+
+	It("runs all the steps in order", func() {
+		state, err := saga.New("test").
+			Step("one", record("do one", nil), record("undo one", nil)).
+			Step("two", record("do two", nil), nil).
+			Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(journal).To(Equal([]string{"do one", "do two"}))
+		Expect(state.Get("do two")).To(Equal("done"))
+	})
+
+	It("undoes the failed step and the completed steps in reverse order on failure", func() {
+		failure := errors.New("boom")
+		_, err := saga.New("test").
+			Step("one", record("do one", nil), record("undo one", nil)).
+			Step("two", record("do two", nil), nil).
+			Step("three", record("do three", nil), record("undo three", nil)).
+			Step("four", record("do four", failure), record("undo four", nil)).
+			Run(ctx)
+		Expect(err).To(MatchError(failure))
+		sagaErr := &saga.Error{}
+		Expect(errors.As(err, &sagaErr)).To(BeTrue())
+		Expect(sagaErr.Step).To(Equal("four"))
+		Expect(sagaErr.RollbackErrs).To(BeEmpty())
+		Expect(journal).To(Equal([]string{"do one", "do two", "do three", "do four", "undo four", "undo three",
+			"undo one"}))
+	})
+
+	It("cleans up the partial state of the failed step", func() {
+		// createAll creates the resources one by one and fails after the first one, like a create followed by a tag
+		failure := errors.New("tagging failed")
+		createAll := func(ctx context.Context, state saga.State) error {
+			state.Set("first", "created")
+			return failure
+		}
+		deleteCreated := func(ctx context.Context, state saga.State) error {
+			for _, name := range []string{"second", "first"} {
+				if state.Get(name) != "" {
+					journal = append(journal, "delete "+name)
+				}
+			}
+			return nil
+		}
+		_, err := saga.New("test").
+			Step("one", record("do one", nil), record("undo one", nil)).
+			Step("create", createAll, deleteCreated).
+			Run(ctx)
+		Expect(err).To(MatchError(failure))
+		Expect(journal).To(Equal([]string{"do one", "delete first", "undo one"}))
+	})
+
+	It("reports the failures of the undo actions", func() {
+		_, err := saga.New("test").
+			Step("one", record("do one", nil), record("undo one", errors.New("undo failed"))).
+			Step("two", record("do two", errors.New("boom")), nil).
+			Run(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed at step two: boom"))
+		Expect(err.Error()).To(ContainSubstring("undo of step one failed: undo failed"))
+	})
+
+	It("rolls back a complete run", func() {
+		s := saga.New("test").
+			Step("one", record("do one", nil), record("undo one", nil)).
+			Step("two", record("do two", nil), record("undo two", nil))
+		_, err := s.Run(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Rollback(ctx)).To(Succeed())
+		Expect(journal).To(Equal([]string{"do one", "do two", "undo two", "undo one"}))
+	})
+
+	It("notifies the observer of the progress of the steps", func() {
+		events := []string{}
+		_, err := saga.New("test").
+			Step("one", record("do one", nil), record("undo one", nil)).
+			Step("two", record("do two", errors.New("boom")), nil).
+			Observe(func(event saga.StepEvent) {
+				Expect(event.Total).To(Equal(2))
+				events = append(events, fmt.Sprintf("%d %s %s", event.Index, event.Step, event.Phase))
+			}).
+			Run(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(events).To(Equal([]string{
+			"0 one started",
+			"0 one finished",
+			"1 two started",
+			"1 two failed",
+			"0 one undone",
+		}))
+	})
+
+	Context("with a checkpoint file", func() {
+		var checkpoint string
+
+		BeforeEach(func() {
+			checkpoint = filepath.Join(GinkgoT().TempDir(), "checkpoint.json")
+		})
+
+		It("resumes from the failed step when the completed steps are kept", func() {
+			_, err := saga.New("test").Checkpoint(checkpoint).KeepCompletedOnFailure(true).
+				Step("one", record("do one", nil), record("undo one", nil)).
+				Step("two", record("do two", errors.New("boom")), nil).
+				Run(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(checkpoint).To(BeAnExistingFile())
+
+			state, err := saga.New("test").Checkpoint(checkpoint).
+				Step("one", record("do one again", nil), record("undo one", nil)).
+				Step("two", record("do two", nil), nil).
+				Run(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state.Get("do one")).To(Equal("done"))
+			Expect(journal).To(Equal([]string{"do one", "do two", "do two"}))
+			_, err = os.Stat(checkpoint)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("rolls back the steps of a previous run", func() {
+			_, err := saga.New("test").Checkpoint(checkpoint).KeepCompletedOnFailure(true).
+				Step("one", record("do one", nil), record("undo one", nil)).
+				Step("two", record("do two", errors.New("boom")), nil).
+				Run(ctx)
+			Expect(err).To(HaveOccurred())
+
+			undoOne := func(ctx context.Context, state saga.State) error {
+				journal = append(journal, "undo one with "+state.Get("do one"))
+				return nil
+			}
+			err = saga.New("test").Checkpoint(checkpoint).
+				Step("one", record("do one", nil), undoOne).
+				Step("two", record("do two", nil), nil).
+				Rollback(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(journal).To(Equal([]string{"do one", "do two", "undo one with done"}))
+			_, err = os.Stat(checkpoint)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("undoes the failed step of a previous run", func() {
+			_, err := saga.New("test").Checkpoint(checkpoint).KeepCompletedOnFailure(true).
+				Step("one", record("do one", nil), record("undo one", nil)).
+				Step("two", record("do two", errors.New("boom")), record("undo two", nil)).
+				Run(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(journal).To(Equal([]string{"do one", "do two"}))
+
+			err = saga.New("test").Checkpoint(checkpoint).
+				Step("one", record("do one", nil), record("undo one", nil)).
+				Step("two", record("do two", nil), record("undo two", nil)).
+				Rollback(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(journal).To(Equal([]string{"do one", "do two", "undo two", "undo one"}))
+		})
+
+		It("lets a step run again adopt what its failed attempt created", func() {
+			created := []string{}
+			failure := errors.New("tagging failed")
+			// create creates a resource unless the state records one, then fails once it is created when err is set
+			create := func(err error) saga.Action {
+				return func(ctx context.Context, state saga.State) error {
+					if state.Get("resource") == "" {
+						id := fmt.Sprintf("resource-%d", len(created))
+						created = append(created, id)
+						state.Set("resource", id)
+					}
+					return err
+				}
+			}
+			deleteResource := func(ctx context.Context, state saga.State) error {
+				journal = append(journal, "delete "+state.Get("resource"))
+				return nil
+			}
+
+			_, err := saga.New("test").Checkpoint(checkpoint).KeepCompletedOnFailure(true).
+				Step("create", create(failure), deleteResource).
+				Run(ctx)
+			Expect(err).To(MatchError(failure))
+
+			state, err := saga.New("test").Checkpoint(checkpoint).
+				Step("create", create(nil), deleteResource).
+				Run(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(Equal([]string{"resource-0"}))
+			Expect(state.Get("resource")).To(Equal("resource-0"))
+
+			By("undoing the resource of a new failed attempt")
+			_, err = saga.New("test").Checkpoint(checkpoint).KeepCompletedOnFailure(true).
+				Step("create", create(failure), deleteResource).
+				Run(ctx)
+			Expect(err).To(MatchError(failure))
+			err = saga.New("test").Checkpoint(checkpoint).
+				Step("create", create(nil), deleteResource).
+				Rollback(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(journal).To(Equal([]string{"delete resource-1"}))
+		})
+
+		It("keeps the steps whose undo failed", func() {
+			s := saga.New("test").Checkpoint(checkpoint).
+				Step("one", record("do one", nil), record("undo one", errors.New("undo failed"))).
+				Step("two", record("do two", errors.New("boom")), nil)
+			_, err := s.Run(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(checkpoint).To(BeAnExistingFile())
+			content, err := os.ReadFile(checkpoint)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`"one"`))
+		})
+	})
+})